@@ -0,0 +1,241 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package base
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-ini/ini"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	configEnvVariableRegexp = regexp.MustCompile(`[$][{]([^}]*)[}]`)
+
+	// configFileExcludedFlags are flags which are meaningless in a config file
+	configFileExcludedFlags = map[string]bool{
+		"conf":         true,
+		"print-config": true,
+		"check-flag":   true,
+		"help":         true,
+		"version":      true,
+	}
+)
+
+// ConfigFileSetting is a single value read from a config file. Name is the command line flag
+// the setting maps onto; Key is the key as it appears in the config file, including its section.
+type ConfigFileSetting struct {
+	Key   string
+	Name  string
+	Value string
+}
+
+// configFileFlagName maps a config file key onto a flag name: sections only group keys,
+// and underscores are interchangeable with dashes, e.g. `[osc] max_lag_millis` maps onto --max-lag-millis
+func configFileFlagName(key string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "_", "-")
+}
+
+// expandConfigFileValue substitutes ${SOME_ENV_VARIABLE} references in a config file value
+func expandConfigFileValue(key, value string) (expanded string, err error) {
+	expanded = configEnvVariableRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		variable := configEnvVariableRegexp.FindStringSubmatch(reference)[1]
+		envValue, ok := os.LookupEnv(variable)
+		if !ok && err == nil {
+			err = fmt.Errorf("Config file key %q references unset environment variable %q", key, variable)
+		}
+		return envValue
+	})
+	return expanded, err
+}
+
+// ReadConfigFileSettings reads a config file where keys are command line flag names. YAML is used for
+// .yaml/.yml files; any other file is read as ini, which also covers TOML-style `key = "value"` tables.
+// ${SOME_ENV_VARIABLE} references in values are substituted from the environment.
+func ReadConfigFileSettings(configFile string) (settings []ConfigFileSetting, err error) {
+	switch strings.ToLower(filepath.Ext(configFile)) {
+	case ".yaml", ".yml":
+		settings, err = readYAMLConfigFileSettings(configFile)
+	default:
+		settings, err = readIniConfigFileSettings(configFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keysByName := make(map[string]string)
+	for i, setting := range settings {
+		if setting.Name == "" {
+			return nil, fmt.Errorf("Empty key in config file %s", configFile)
+		}
+		if previousKey, ok := keysByName[setting.Name]; ok {
+			return nil, fmt.Errorf("Config file keys %q and %q both set --%s", previousKey, setting.Key, setting.Name)
+		}
+		keysByName[setting.Name] = setting.Key
+		if settings[i].Value, err = expandConfigFileValue(setting.Key, setting.Value); err != nil {
+			return nil, err
+		}
+	}
+	return settings, nil
+}
+
+func readIniConfigFileSettings(configFile string) (settings []ConfigFileSetting, err error) {
+	cfg, err := ini.Load(configFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range cfg.Sections() {
+		for _, key := range section.Keys() {
+			fullKey := key.Name()
+			if section.Name() != ini.DefaultSection {
+				fullKey = fmt.Sprintf("%s.%s", section.Name(), key.Name())
+			}
+			settings = append(settings, ConfigFileSetting{
+				Key:   fullKey,
+				Name:  configFileFlagName(key.Name()),
+				Value: key.String(),
+			})
+		}
+	}
+	return settings, nil
+}
+
+func readYAMLConfigFileSettings(configFile string) (settings []ConfigFileSetting, err error) {
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("Unable to parse config file %s: %w", configFile, err)
+	}
+	if len(document.Content) == 0 {
+		return settings, nil
+	}
+	return yamlMappingSettings(document.Content[0], "")
+}
+
+// yamlMappingSettings reads settings off a YAML mapping. Nested mappings are sections, and
+// sequences are comma delimited, e.g. a list of throttle control replicas.
+func yamlMappingSettings(node *yaml.Node, section string) (settings []ConfigFileSetting, err error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("Expected a mapping of keys to values at line %d of config file", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		fullKey := keyNode.Value
+		if section != "" {
+			fullKey = fmt.Sprintf("%s.%s", section, keyNode.Value)
+		}
+		switch valueNode.Kind {
+		case yaml.MappingNode:
+			sectionSettings, err := yamlMappingSettings(valueNode, fullKey)
+			if err != nil {
+				return nil, err
+			}
+			settings = append(settings, sectionSettings...)
+			continue
+		case yaml.SequenceNode:
+			values := []string{}
+			for _, itemNode := range valueNode.Content {
+				if itemNode.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("Config file key %q: expected a list of values", fullKey)
+				}
+				values = append(values, itemNode.Value)
+			}
+			settings = append(settings, ConfigFileSetting{Key: fullKey, Name: configFileFlagName(keyNode.Value), Value: strings.Join(values, ",")})
+		case yaml.ScalarNode:
+			settings = append(settings, ConfigFileSetting{Key: fullKey, Name: configFileFlagName(keyNode.Value), Value: valueNode.Value})
+		default:
+			return nil, fmt.Errorf("Config file key %q: unsupported value", fullKey)
+		}
+	}
+	return settings, nil
+}
+
+// ApplyConfigFileSettings sets flags from config file settings. Flags given on the command line
+// take precedence over the config file, which takes precedence over flag defaults. Keys which map onto
+// no flag, as found in a my.cnf shared with other MySQL clients, are ignored. With --credentials-file or
+// --credentials-command, the credentials provider takes precedence over a password in the config file.
+// Returned warnings describe the ignored settings.
+func ApplyConfigFileSettings(flagSet *flag.FlagSet, settings []ConfigFileSetting) (warnings []string, err error) {
+	explicitFlags := make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		explicitFlags[f.Name] = true
+	})
+	credentialsProviderFlag := configFileCredentialsProviderFlag(flagSet, settings)
+	for _, setting := range settings {
+		if configFileExcludedFlags[setting.Name] || flagSet.Lookup(setting.Name) == nil {
+			warnings = append(warnings, fmt.Sprintf("Ignoring unknown config file key %q: there is no --%s flag", setting.Key, setting.Name))
+			continue
+		}
+		if explicitFlags[setting.Name] {
+			continue
+		}
+		if setting.Name == "password" && credentialsProviderFlag != "" {
+			warnings = append(warnings, fmt.Sprintf("Ignoring config file key %q: credentials are read via --%s", setting.Key, credentialsProviderFlag))
+			continue
+		}
+		if err := flagSet.Set(setting.Name, setting.Value); err != nil {
+			return warnings, fmt.Errorf("Invalid value %q for config file key %q: %w", setting.Value, setting.Key, err)
+		}
+	}
+	return warnings, nil
+}
+
+// configFileCredentialsProviderFlag returns the credentials provider flag in effect, be it given on the
+// command line or in the config file, or an empty string when credentials are static
+func configFileCredentialsProviderFlag(flagSet *flag.FlagSet, settings []ConfigFileSetting) string {
+	for _, name := range []string{"credentials-file", "credentials-command"} {
+		if f := flagSet.Lookup(name); f != nil && f.Value.String() != "" {
+			return name
+		}
+		for _, setting := range settings {
+			if setting.Name == name && setting.Value != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// EffectiveConfig returns the value of every flag that can be set in a config file, in YAML.
+// Passwords are masked.
+func EffectiveConfig(flagSet *flag.FlagSet) (string, error) {
+	names := []string{}
+	values := make(map[string]string)
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if configFileExcludedFlags[f.Name] {
+			return
+		}
+		value := f.Value.String()
+		if strings.Contains(f.Name, "password") && value != "" {
+			value = "********"
+		}
+		names = append(names, f.Name)
+		values[f.Name] = value
+	})
+	sort.Strings(names)
+
+	document := &yaml.Node{Kind: yaml.MappingNode}
+	for _, name := range names {
+		document.Content = append(document.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: name},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: values[name]},
+		)
+	}
+	out, err := yaml.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
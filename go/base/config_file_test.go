@@ -0,0 +1,186 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package base
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	configFile := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(configFile, []byte(content), 0o600))
+	return configFile
+}
+
+func newConfigFileTestFlagSet() *flag.FlagSet {
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.String("user", "", "")
+	flagSet.String("password", "", "")
+	flagSet.Int64("chunk-size", 1000, "")
+	flagSet.Int64("max-lag-millis", 1500, "")
+	flagSet.String("throttle-control-replicas", "", "")
+	flagSet.Bool("execute", false, "")
+	flagSet.String("conf", "", "")
+	return flagSet
+}
+
+func TestReadConfigFileSettingsIni(t *testing.T) {
+	t.Setenv("GH_OST_TEST_PASSWORD", "s3cr3t")
+	configFile := writeConfigFile(t, "gh-ost.cnf", "execute=true\n[client]\nuser=gromit\npassword=${GH_OST_TEST_PASSWORD}\n[osc]\nchunk_size=500\n")
+
+	settings, err := ReadConfigFileSettings(configFile)
+	require.NoError(t, err)
+	require.Equal(t, []ConfigFileSetting{
+		{Key: "execute", Name: "execute", Value: "true"},
+		{Key: "client.user", Name: "user", Value: "gromit"},
+		{Key: "client.password", Name: "password", Value: "s3cr3t"},
+		{Key: "osc.chunk_size", Name: "chunk-size", Value: "500"},
+	}, settings)
+}
+
+func TestReadConfigFileSettingsYAML(t *testing.T) {
+	configFile := writeConfigFile(t, "gh-ost.yaml", `
+client:
+  user: gromit
+throttle:
+  max-lag-millis: 1000
+  throttle-control-replicas:
+    - replica-01:3306
+    - replica-02
+execute: true
+`)
+
+	settings, err := ReadConfigFileSettings(configFile)
+	require.NoError(t, err)
+	require.Equal(t, []ConfigFileSetting{
+		{Key: "client.user", Name: "user", Value: "gromit"},
+		{Key: "throttle.max-lag-millis", Name: "max-lag-millis", Value: "1000"},
+		{Key: "throttle.throttle-control-replicas", Name: "throttle-control-replicas", Value: "replica-01:3306,replica-02"},
+		{Key: "execute", Name: "execute", Value: "true"},
+	}, settings)
+}
+
+func TestReadConfigFileSettingsErrors(t *testing.T) {
+	{
+		configFile := writeConfigFile(t, "gh-ost.cnf", "[client]\npassword=${GH_OST_TEST_UNSET_VARIABLE}\n")
+		_, err := ReadConfigFileSettings(configFile)
+		require.EqualError(t, err, `Config file key "client.password" references unset environment variable "GH_OST_TEST_UNSET_VARIABLE"`)
+	}
+	{
+		configFile := writeConfigFile(t, "gh-ost.cnf", "[osc]\nchunk_size=500\n[throttle]\nchunk-size=100\n")
+		_, err := ReadConfigFileSettings(configFile)
+		require.EqualError(t, err, `Config file keys "osc.chunk_size" and "throttle.chunk-size" both set --chunk-size`)
+	}
+	{
+		configFile := writeConfigFile(t, "gh-ost.yaml", "- chunk-size\n")
+		_, err := ReadConfigFileSettings(configFile)
+		require.Error(t, err)
+	}
+}
+
+func TestApplyConfigFileSettings(t *testing.T) {
+	{
+		flagSet := newConfigFileTestFlagSet()
+		require.NoError(t, flagSet.Parse([]string{"--chunk-size=200"}))
+		warnings, err := ApplyConfigFileSettings(flagSet, []ConfigFileSetting{
+			{Key: "osc.chunk_size", Name: "chunk-size", Value: "500"},
+			{Key: "osc.max_lag_millis", Name: "max-lag-millis", Value: "3000"},
+			{Key: "execute", Name: "execute", Value: "true"},
+		})
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+		// command line takes precedence
+		require.Equal(t, "200", flagSet.Lookup("chunk-size").Value.String())
+		require.Equal(t, "3000", flagSet.Lookup("max-lag-millis").Value.String())
+		require.Equal(t, "true", flagSet.Lookup("execute").Value.String())
+	}
+	{
+		// explicit flags take precedence even when given their default value
+		flagSet := newConfigFileTestFlagSet()
+		require.NoError(t, flagSet.Parse([]string{"--chunk-size=1000", "--user=wallace"}))
+		warnings, err := ApplyConfigFileSettings(flagSet, []ConfigFileSetting{
+			{Key: "client.user", Name: "user", Value: "gromit"},
+			{Key: "osc.chunk_size", Name: "chunk-size", Value: "500"},
+		})
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+		require.Equal(t, "wallace", flagSet.Lookup("user").Value.String())
+		require.Equal(t, "1000", flagSet.Lookup("chunk-size").Value.String())
+	}
+	{
+		flagSet := newConfigFileTestFlagSet()
+		warnings, err := ApplyConfigFileSettings(flagSet, []ConfigFileSetting{
+			{Key: "client.socket", Name: "socket", Value: "/tmp/mysql.sock"},
+			{Key: "conf", Name: "conf", Value: "other.cnf"},
+			{Key: "osc.chunk_size", Name: "chunk-size", Value: "500"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			`Ignoring unknown config file key "client.socket": there is no --socket flag`,
+			`Ignoring unknown config file key "conf": there is no --conf flag`,
+		}, warnings)
+		require.Equal(t, "", flagSet.Lookup("conf").Value.String())
+		require.Equal(t, "500", flagSet.Lookup("chunk-size").Value.String())
+	}
+	{
+		// a credentials file takes precedence over a password in the config file
+		flagSet := newConfigFileTestFlagSet()
+		flagSet.String("credentials-file", "", "")
+		require.NoError(t, flagSet.Parse([]string{"--credentials-file=/etc/gh-ost/credentials"}))
+		warnings, err := ApplyConfigFileSettings(flagSet, []ConfigFileSetting{
+			{Key: "client.user", Name: "user", Value: "gromit"},
+			{Key: "client.password", Name: "password", Value: "s3cr3t"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{`Ignoring config file key "client.password": credentials are read via --credentials-file`}, warnings)
+		require.Equal(t, "gromit", flagSet.Lookup("user").Value.String())
+		require.Equal(t, "", flagSet.Lookup("password").Value.String())
+	}
+	{
+		flagSet := newConfigFileTestFlagSet()
+		flagSet.String("credentials-file", "", "")
+		warnings, err := ApplyConfigFileSettings(flagSet, []ConfigFileSetting{
+			{Key: "client.password", Name: "password", Value: "s3cr3t"},
+			{Key: "credentials_file", Name: "credentials-file", Value: "/etc/gh-ost/credentials"},
+		})
+		require.NoError(t, err)
+		require.Len(t, warnings, 1)
+		require.Equal(t, "", flagSet.Lookup("password").Value.String())
+		require.Equal(t, "/etc/gh-ost/credentials", flagSet.Lookup("credentials-file").Value.String())
+	}
+	{
+		flagSet := newConfigFileTestFlagSet()
+		_, err := ApplyConfigFileSettings(flagSet, []ConfigFileSetting{{Key: "osc.max_lag_millis", Name: "max-lag-millis", Value: "abc"}})
+		require.ErrorContains(t, err, `Invalid value "abc" for config file key "osc.max_lag_millis"`)
+	}
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	flagSet := newConfigFileTestFlagSet()
+	require.NoError(t, flagSet.Parse([]string{"--user=gromit", "--password=s3cr3t", "--throttle-control-replicas=replica-01:3306,replica-02"}))
+
+	effectiveConfig, err := EffectiveConfig(flagSet)
+	require.NoError(t, err)
+	require.Equal(t, `chunk-size: 1000
+execute: false
+max-lag-millis: 1500
+password: '********'
+throttle-control-replicas: replica-01:3306,replica-02
+user: gromit
+`, effectiveConfig)
+
+	// the effective config reads back as a config file
+	configFile := writeConfigFile(t, "effective.yaml", effectiveConfig)
+	settings, err := ReadConfigFileSettings(configFile)
+	require.NoError(t, err)
+	require.Len(t, settings, 6)
+	require.Equal(t, ConfigFileSetting{Key: "throttle-control-replicas", Name: "throttle-control-replicas", Value: "replica-01:3306,replica-02"}, settings[4])
+}
@@ -9,8 +9,7 @@ import (
 	"context"
 	"fmt"
 	"math"
-	"os"
-	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -22,8 +21,6 @@ import (
 	"github.com/github/gh-ost/go/mysql"
 	"github.com/github/gh-ost/go/sql"
 	"github.com/openark/golib/log"
-
-	"github.com/go-ini/ini"
 )
 
 // RowsEstimateMethod is the type of row number estimation
@@ -51,13 +48,13 @@ const (
 )
 
 const (
-	HTTPStatusOK       = 200
-	MaxEventsBatchSize = 1000
-	ETAUnknown         = math.MinInt64
-)
-
-var (
-	envVariableRegexp = regexp.MustCompile("[$][{](.*)[}]")
+	HTTPStatusOK         = 200
+	MaxEventsBatchSize   = 1000
+	ETAUnknown           = math.MinInt64
+	BinlogBacklogUnknown = -1
+	// BinlogRetentionUnknown marks an unknown binlog retention: not yet measured, or unbounded when
+	// the server does not purge binary logs automatically
+	BinlogRetentionUnknown = -1
 )
 
 type ThrottleCheckResult struct {
@@ -87,6 +84,9 @@ type MigrationContext struct {
 
 	countMutex               sync.Mutex
 	countTableRowsCancelFunc func()
+	rowsCounted              int64
+	rowCountRangeMaxValues   *sql.ColumnValues
+	isRowCountComplete       bool
 	CountTableRows           bool
 	ConcurrentCountTableRows bool
 	AllowedRunningOnMaster   bool
@@ -97,6 +97,8 @@ type MigrationContext struct {
 	SkipStrictMode           bool
 	AllowZeroInDate          bool
 	NullableUniqueKeyAllowed bool
+	NonUniqueKeyAllowed      bool
+	MigrationKey             string
 	ApproveRenamedColumns    bool
 	SkipRenamedColumns       bool
 	IsTungsten               bool
@@ -105,6 +107,8 @@ type MigrationContext struct {
 	GoogleCloudPlatform      bool
 	AzureMySQL               bool
 	AttemptInstantDDL        bool
+	AttemptInplaceDDL        bool
+	CopyFromReplica          bool
 	Resume                   bool
 	Revert                   bool
 	OldTableName             string
@@ -134,11 +138,18 @@ type MigrationContext struct {
 	CliMasterUser     string
 	CliMasterPassword string
 
+	CredentialsFile         string
+	CredentialsCommand      string
+	CredentialsCacheSeconds int64
+
 	HeartbeatIntervalMilliseconds       int64
 	defaultNumRetries                   int64
 	ChunkSize                           int64
 	niceRatio                           float64
 	MaxLagMillisecondsThrottleThreshold int64
+	MaxBinlogBacklog                    int64
+	BinlogRetentionMarginSeconds        int64
+	BinlogRetentionPauseRowCopy         bool
 	throttleControlReplicaKeys          *mysql.InstanceKeyMap
 	ThrottleFlagFile                    string
 	ThrottleAdditionalFlagFile          string
@@ -167,6 +178,10 @@ type MigrationContext struct {
 	Checkpoint                          bool
 	CheckpointIntervalSeconds           int64
 
+	// EventListener, when set, is called upon each hook event with the hook's name, whether or not
+	// hooks are configured. It is called synchronously and must not block.
+	EventListener func(event, message string)
+
 	DropServeSocket bool
 	ServeSocketFile string
 	ServeTCPPort    int64
@@ -184,9 +199,12 @@ type MigrationContext struct {
 
 	Hostname                               string
 	AssumeMasterHostname                   string
+	ActiveActivePeerHostname               string
+	ReplicationChannel                     string
 	ApplierTimeZone                        string
 	ApplierWaitTimeout                     int64
 	TableEngine                            string
+	TableNamesCaseSensitive                bool
 	RowsEstimate                           int64
 	RowsDeltaEstimate                      int64
 	UsedRowsEstimateMethod                 RowsEstimateMethod
@@ -195,8 +213,10 @@ type MigrationContext struct {
 	OriginalBinlogRowImage                 string
 	InspectorConnectionConfig              *mysql.ConnectionConfig
 	InspectorMySQLVersion                  string
+	InspectorServerUUID                    string
 	ApplierConnectionConfig                *mysql.ConnectionConfig
 	ApplierMySQLVersion                    string
+	PeerConnectionConfig                   *mysql.ConnectionConfig
 	StartTime                              time.Time
 	RowCopyStartTime                       time.Time
 	RowCopyEndTime                         time.Time
@@ -210,14 +230,27 @@ type MigrationContext struct {
 	CurrentLag                             int64
 	currentProgress                        uint64
 	etaNanoseonds                          int64
+	etaMinNanoseconds                      int64
+	etaMaxNanoseconds                      int64
 	EtaRowsPerSecond                       int64
+	binlogBacklog                          int64
+	binlogRetentionRemaining               int64
+	binlogRetentionPausingRowCopy          int64
+	applyEventsQueued                      int64
+	dmlApplyRateBits                       uint64
 	ThrottleHTTPIntervalMillis             int64
 	ThrottleHTTPStatusCode                 int64
 	ThrottleHTTPTimeoutMillis              int64
 	controlReplicasLagResult               mysql.ReplicationLagResult
 	TotalRowsCopied                        int64
 	TotalDMLEventsApplied                  int64
+	TotalDMLEventsCoalesced                int64
 	DMLBatchSize                           int64
+	MaxDMLBatchSize                        int64
+	MaxDMLBatchLatencyMillis               int64
+	effectiveDMLBatchSize                  int64
+	CoalesceDMLEvents                      bool
+	MaxDMLStatementSize                    int64
 	isThrottled                            bool
 	throttleReason                         string
 	throttleReasonHint                     ThrottleReasonHint
@@ -248,6 +281,7 @@ type MigrationContext struct {
 	OriginalTableVirtualColumns      *sql.ColumnList
 	OriginalTableUniqueKeys          [](*sql.UniqueKey)
 	OriginalTableAutoIncrement       uint64
+	DDLAlgorithmPrediction           *sql.DDLAlgorithmPrediction
 	GhostTableColumns                *sql.ColumnList
 	GhostTableVirtualColumns         *sql.ColumnList
 	GhostTableUniqueKeys             [](*sql.UniqueKey)
@@ -265,6 +299,10 @@ type MigrationContext struct {
 	InitialStreamerCoords            mysql.BinlogCoordinates
 	ForceTmpTableName                string
 
+	// MigrationKeyUpdatedInPlace is set when the ghost table's primary key has columns the original table
+	// does not have, such as a new AUTO_INCREMENT column, whose values a delete and re-insert would not keep
+	MigrationKeyUpdatedInPlace bool
+
 	IncludeTriggers     bool
 	RemoveTriggerSuffix bool
 	TriggerSuffix       string
@@ -273,10 +311,14 @@ type MigrationContext struct {
 	recentBinlogCoordinates mysql.BinlogCoordinates
 
 	BinlogSyncerMaxReconnectAttempts  int
+	StreamerFailover                  bool
 	AllowSetupMetadataLockInstruments bool
 	SkipMetadataLockCheck             bool
 	IsOpenMetadataLockInstruments     bool
 
+	// DBCache holds this migration's connection pools
+	DBCache *mysql.DBCache
+
 	Log Logger
 }
 
@@ -318,10 +360,17 @@ func NewMigrationContext() *MigrationContext {
 		ChunkSize:                           1000,
 		InspectorConnectionConfig:           mysql.NewConnectionConfig(),
 		ApplierConnectionConfig:             mysql.NewConnectionConfig(),
+		DBCache:                             mysql.NewDBCache(),
 		MaxLagMillisecondsThrottleThreshold: 1500,
 		CutOverLockTimeoutSeconds:           3,
 		DMLBatchSize:                        10,
+		MaxDMLBatchLatencyMillis:            100,
+		MaxDMLStatementSize:                 1024 * 1024,
 		etaNanoseonds:                       ETAUnknown,
+		etaMinNanoseconds:                   ETAUnknown,
+		etaMaxNanoseconds:                   ETAUnknown,
+		binlogBacklog:                       BinlogBacklogUnknown,
+		binlogRetentionRemaining:            BinlogRetentionUnknown,
 		maxLoad:                             NewLoadMap(),
 		criticalLoad:                        NewLoadMap(),
 		throttleMutex:                       &sync.Mutex{},
@@ -370,6 +419,15 @@ func getSafeTableName(baseName string, suffix string) string {
 	return fmt.Sprintf("~%s_%s", baseName[0:len(baseName)-extraCharacters], suffix)
 }
 
+// TableNamesEqual compares database or table names the way the server does: case-exact when
+// the server has case sensitive table names (lower_case_table_names=0), case insensitive otherwise
+func (this *MigrationContext) TableNamesEqual(name, otherName string) bool {
+	if this.TableNamesCaseSensitive {
+		return name == otherName
+	}
+	return strings.EqualFold(name, otherName)
+}
+
 // GetGhostTableName generates the name of ghost table, based on original table name
 // or a given table name
 func (this *MigrationContext) GetGhostTableName() string {
@@ -564,6 +622,33 @@ func (this *MigrationContext) CancelTableRowsCount() {
 	this.countTableRowsCancelFunc = nil
 }
 
+// SetRowCountProgress records the progress of the exact row count: the number of rows
+// counted up to and including given unique key values, and whether counting is complete.
+func (this *MigrationContext) SetRowCountProgress(rowsCounted int64, rangeMaxValues *sql.ColumnValues, isComplete bool) {
+	this.countMutex.Lock()
+	defer this.countMutex.Unlock()
+
+	this.rowsCounted = rowsCounted
+	this.rowCountRangeMaxValues = rangeMaxValues
+	this.isRowCountComplete = isComplete
+}
+
+// GetRowCountProgress returns the progress of the exact row count, see SetRowCountProgress.
+func (this *MigrationContext) GetRowCountProgress() (rowsCounted int64, rangeMaxValues *sql.ColumnValues, isComplete bool) {
+	this.countMutex.Lock()
+	defer this.countMutex.Unlock()
+
+	return this.rowsCounted, this.rowCountRangeMaxValues, this.isRowCountComplete
+}
+
+// SetExactRowsEstimate sets the rows estimate off an exact row count. DML events applied so far are
+// reflected in the count, and so the estimate only follows the rows delta of events applied from now
+// on: large deletes then lower the estimate rather than be counted twice, or not at all.
+func (this *MigrationContext) SetExactRowsEstimate(rowsCounted int64) {
+	atomic.StoreInt64(&this.RowsEstimate, rowsCounted-atomic.LoadInt64(&this.RowsDeltaEstimate))
+	this.UsedRowsEstimateMethod = CountRowsEstimate
+}
+
 // ElapsedTime returns time since very beginning of the process
 func (this *MigrationContext) ElapsedTime() time.Duration {
 	return time.Since(this.StartTime)
@@ -631,6 +716,120 @@ func (this *MigrationContext) GetETASeconds() int64 {
 	return nano / int64(time.Second)
 }
 
+// SetETARange sets the confidence range of the ETA; either end may be ETAUnknown
+func (this *MigrationContext) SetETARange(etaMin, etaMax time.Duration) {
+	atomic.StoreInt64(&this.etaMinNanoseconds, etaMin.Nanoseconds())
+	atomic.StoreInt64(&this.etaMaxNanoseconds, etaMax.Nanoseconds())
+}
+
+// GetETARangeSeconds returns the confidence range of the ETA in seconds; either end may be ETAUnknown
+func (this *MigrationContext) GetETARangeSeconds() (etaMinSeconds, etaMaxSeconds int64) {
+	toSeconds := func(nano int64) int64 {
+		if nano < 0 {
+			return ETAUnknown
+		}
+		return nano / int64(time.Second)
+	}
+	return toSeconds(atomic.LoadInt64(&this.etaMinNanoseconds)), toSeconds(atomic.LoadInt64(&this.etaMaxNanoseconds))
+}
+
+// SetBinlogBacklog sets how far the applied binlog events are behind the binlog head: bytes with
+// file based coordinates, transactions with GTID; BinlogBacklogUnknown when it could not be determined
+func (this *MigrationContext) SetBinlogBacklog(binlogBacklog int64) {
+	atomic.StoreInt64(&this.binlogBacklog, binlogBacklog)
+}
+
+func (this *MigrationContext) GetBinlogBacklog() int64 {
+	return atomic.LoadInt64(&this.binlogBacklog)
+}
+
+// GetBinlogBacklogDescription returns the binlog backlog in human readable form
+func (this *MigrationContext) GetBinlogBacklogDescription() string {
+	binlogBacklog := this.GetBinlogBacklog()
+	if binlogBacklog < 0 {
+		return "N/A"
+	}
+	if this.UseGTIDs {
+		return fmt.Sprintf("%d trx", binlogBacklog)
+	}
+	return fmt.Sprintf("%d bytes", binlogBacklog)
+}
+
+// IsBinlogBacklogExceeded returns true when --max-binlog-backlog is set and the known binlog backlog exceeds it
+func (this *MigrationContext) IsBinlogBacklogExceeded() bool {
+	maxBinlogBacklog := atomic.LoadInt64(&this.MaxBinlogBacklog)
+	return maxBinlogBacklog > 0 && this.GetBinlogBacklog() > maxBinlogBacklog
+}
+
+func (this *MigrationContext) SetMaxBinlogBacklog(maxBinlogBacklog int64) {
+	if maxBinlogBacklog < 0 {
+		maxBinlogBacklog = 0
+	}
+	atomic.StoreInt64(&this.MaxBinlogBacklog, maxBinlogBacklog)
+}
+
+// SetBinlogRetentionRemaining sets the number of seconds until the server is due to purge the oldest binary log
+// the migration needs; 0 when already purged, BinlogRetentionUnknown when it could not be determined
+func (this *MigrationContext) SetBinlogRetentionRemaining(binlogRetentionRemaining int64) {
+	atomic.StoreInt64(&this.binlogRetentionRemaining, binlogRetentionRemaining)
+}
+
+func (this *MigrationContext) GetBinlogRetentionRemaining() int64 {
+	return atomic.LoadInt64(&this.binlogRetentionRemaining)
+}
+
+// GetBinlogRetentionRemainingDescription returns the remaining binlog retention in human readable form
+func (this *MigrationContext) GetBinlogRetentionRemainingDescription() string {
+	binlogRetentionRemaining := this.GetBinlogRetentionRemaining()
+	if binlogRetentionRemaining < 0 {
+		return "N/A"
+	}
+	if binlogRetentionRemaining == 0 {
+		return "purged"
+	}
+	return PrettifyDurationOutput(time.Duration(binlogRetentionRemaining) * time.Second)
+}
+
+// IsBinlogRetentionAtRisk returns true when --binlog-retention-margin-seconds is set and the oldest binary log
+// the migration needs is due to be purged within that margin, or is already purged
+func (this *MigrationContext) IsBinlogRetentionAtRisk() bool {
+	binlogRetentionMarginSeconds := atomic.LoadInt64(&this.BinlogRetentionMarginSeconds)
+	binlogRetentionRemaining := this.GetBinlogRetentionRemaining()
+	return binlogRetentionMarginSeconds > 0 && binlogRetentionRemaining >= 0 && binlogRetentionRemaining < binlogRetentionMarginSeconds
+}
+
+func (this *MigrationContext) SetBinlogRetentionPausingRowCopy(pausing bool) {
+	if pausing {
+		atomic.StoreInt64(&this.binlogRetentionPausingRowCopy, 1)
+	} else {
+		atomic.StoreInt64(&this.binlogRetentionPausingRowCopy, 0)
+	}
+}
+
+// IsBinlogRetentionPausingRowCopy returns true when row copy is paused with --binlog-retention-pause-row-copy,
+// so that applied events move away from binary logs due to be purged
+func (this *MigrationContext) IsBinlogRetentionPausingRowCopy() bool {
+	return atomic.LoadInt64(&this.binlogRetentionPausingRowCopy) > 0
+}
+
+func (this *MigrationContext) SetApplyEventsQueued(applyEventsQueued int64) {
+	atomic.StoreInt64(&this.applyEventsQueued, applyEventsQueued)
+}
+
+// GetApplyEventsQueued returns the number of binlog events streamed but not yet applied
+func (this *MigrationContext) GetApplyEventsQueued() int64 {
+	return atomic.LoadInt64(&this.applyEventsQueued)
+}
+
+func (this *MigrationContext) SetDMLApplyRate(dmlApplyRate float64) {
+	atomic.StoreUint64(&this.dmlApplyRateBits, math.Float64bits(dmlApplyRate))
+}
+
+// GetDMLApplyRate returns the recent rate of applied DML events per second
+func (this *MigrationContext) GetDMLApplyRate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&this.dmlApplyRateBits))
+}
+
 // math.Float64bits([f=0..100])
 
 // GetTotalRowsCopied returns the accurate number of rows being copied (affected)
@@ -716,6 +915,59 @@ func (this *MigrationContext) SetDMLBatchSize(batchSize int64) {
 	atomic.StoreInt64(&this.DMLBatchSize, batchSize)
 }
 
+func (this *MigrationContext) SetMaxDMLBatchSize(batchSize int64) {
+	if batchSize < 0 {
+		batchSize = 0
+	}
+	if batchSize > MaxEventsBatchSize {
+		batchSize = MaxEventsBatchSize
+	}
+	atomic.StoreInt64(&this.MaxDMLBatchSize, batchSize)
+}
+
+// IsDynamicDMLBatchSize returns true when DML batches are sized dynamically, between --dml-batch-size
+// and --max-dml-batch-size
+func (this *MigrationContext) IsDynamicDMLBatchSize() bool {
+	return atomic.LoadInt64(&this.MaxDMLBatchSize) > atomic.LoadInt64(&this.DMLBatchSize)
+}
+
+// GetEffectiveDMLBatchSize returns the size of the next DML batch. This is --dml-batch-size, unless
+// batches are sized dynamically.
+func (this *MigrationContext) GetEffectiveDMLBatchSize() int64 {
+	minBatchSize := atomic.LoadInt64(&this.DMLBatchSize)
+	maxBatchSize := atomic.LoadInt64(&this.MaxDMLBatchSize)
+	if maxBatchSize <= minBatchSize {
+		return minBatchSize
+	}
+	batchSize := atomic.LoadInt64(&this.effectiveDMLBatchSize)
+	if batchSize < minBatchSize {
+		batchSize = minBatchSize
+	}
+	if batchSize > maxBatchSize {
+		batchSize = maxBatchSize
+	}
+	return batchSize
+}
+
+// AdjustDMLBatchSize resizes dynamically sized DML batches, given the latency of the last batch's
+// transaction and the number of events still queued for applying. The batch size halves when a
+// transaction exceeds --max-dml-batch-latency-millis, and doubles when a full batch is queued and
+// transactions complete within half that latency. Returns the size of the next batch.
+func (this *MigrationContext) AdjustDMLBatchSize(queuedEvents int64, latency time.Duration) int64 {
+	if !this.IsDynamicDMLBatchSize() {
+		return this.GetEffectiveDMLBatchSize()
+	}
+	batchSize := this.GetEffectiveDMLBatchSize()
+	maxLatency := time.Duration(atomic.LoadInt64(&this.MaxDMLBatchLatencyMillis)) * time.Millisecond
+	if latency > maxLatency {
+		batchSize = batchSize / 2
+	} else if queuedEvents >= batchSize && latency <= maxLatency/2 {
+		batchSize = batchSize * 2
+	}
+	atomic.StoreInt64(&this.effectiveDMLBatchSize, batchSize)
+	return this.GetEffectiveDMLBatchSize()
+}
+
 func (this *MigrationContext) SetThrottleGeneralCheckResult(checkResult *ThrottleCheckResult) *ThrottleCheckResult {
 	this.throttleMutex.Lock()
 	defer this.throttleMutex.Unlock()
@@ -937,6 +1189,28 @@ func (this *MigrationContext) ApplyCredentials() {
 	}
 }
 
+// SetupCredentialsProvider sets up a credentials provider per --credentials-file or --credentials-command,
+// and resolves the initial credentials from it. Without either, the static credentials apply.
+func (this *MigrationContext) SetupCredentialsProvider() error {
+	var credentialsProvider mysql.CredentialsProvider
+	switch {
+	case this.CredentialsFile != "":
+		credentialsProvider = mysql.NewFileCredentialsProvider(this.CredentialsFile)
+	case this.CredentialsCommand != "":
+		credentialsProvider = mysql.NewExecCredentialsProvider(this.CredentialsCommand, time.Duration(this.CredentialsCacheSeconds)*time.Second)
+	default:
+		return nil
+	}
+	this.InspectorConnectionConfig.CredentialsProvider = credentialsProvider
+	user, password, err := this.InspectorConnectionConfig.GetCredentials()
+	if err != nil {
+		return fmt.Errorf("Unable to read initial credentials: %w", err)
+	}
+	this.InspectorConnectionConfig.User = user
+	this.InspectorConnectionConfig.Password = password
+	return nil
+}
+
 func (this *MigrationContext) SetupTLS() error {
 	if this.UseTLS {
 		return this.InspectorConnectionConfig.UseTLS(this.Uuid, this.TLSCACertificate, this.TLSCertificate, this.TLSKey, this.TLSAllowInsecure)
@@ -944,7 +1218,9 @@ func (this *MigrationContext) SetupTLS() error {
 	return nil
 }
 
-// ReadConfigFile attempts to read the config file, if it exists
+// ReadConfigFile attempts to read the config file, if it exists. Only the credentials and a few
+// throttling keys are kept here, so that credentials can be reloaded upon SIGHUP;
+// ApplyConfigFileSettings maps the full config file onto the command line flags.
 func (this *MigrationContext) ReadConfigFile() error {
 	this.configMutex.Lock()
 	defer this.configMutex.Unlock()
@@ -952,50 +1228,31 @@ func (this *MigrationContext) ReadConfigFile() error {
 	if this.ConfigFile == "" {
 		return nil
 	}
-	cfg, err := ini.Load(this.ConfigFile)
+	settings, err := ReadConfigFileSettings(this.ConfigFile)
 	if err != nil {
 		return err
 	}
 
-	if cfg.Section("client").HasKey("user") {
-		this.config.Client.User = cfg.Section("client").Key("user").String()
-	}
-
-	if cfg.Section("client").HasKey("password") {
-		this.config.Client.Password = cfg.Section("client").Key("password").String()
-	}
-
-	if cfg.Section("osc").HasKey("chunk_size") {
-		this.config.Osc.Chunk_Size, err = cfg.Section("osc").Key("chunk_size").Int64()
-		if err != nil {
-			return fmt.Errorf("Unable to read osc chunk size: %w", err)
+	for _, setting := range settings {
+		switch setting.Name {
+		case "user":
+			this.config.Client.User = setting.Value
+		case "password":
+			this.config.Client.Password = setting.Value
+		case "chunk-size":
+			if this.config.Osc.Chunk_Size, err = strconv.ParseInt(setting.Value, 10, 64); err != nil {
+				return fmt.Errorf("Unable to read osc chunk size: %w", err)
+			}
+		case "max-load":
+			this.config.Osc.Max_Load = setting.Value
+		case "replication-lag-query":
+			this.config.Osc.Replication_Lag_Query = setting.Value
+		case "max-lag-millis":
+			if this.config.Osc.Max_Lag_Millis, err = strconv.ParseInt(setting.Value, 10, 64); err != nil {
+				return fmt.Errorf("Unable to read max lag millis: %w", err)
+			}
 		}
 	}
-
-	if cfg.Section("osc").HasKey("max_load") {
-		this.config.Osc.Max_Load = cfg.Section("osc").Key("max_load").String()
-	}
-
-	if cfg.Section("osc").HasKey("replication_lag_query") {
-		this.config.Osc.Replication_Lag_Query = cfg.Section("osc").Key("replication_lag_query").String()
-	}
-
-	if cfg.Section("osc").HasKey("max_lag_millis") {
-		this.config.Osc.Max_Lag_Millis, err = cfg.Section("osc").Key("max_lag_millis").Int64()
-		if err != nil {
-			return fmt.Errorf("Unable to read max lag millis: %w", err)
-		}
-	}
-
-	// We accept user & password in the form "${SOME_ENV_VARIABLE}" in which case we pull
-	// the given variable from os env
-	if submatch := envVariableRegexp.FindStringSubmatch(this.config.Client.User); len(submatch) > 1 {
-		this.config.Client.User = os.Getenv(submatch[1])
-	}
-	if submatch := envVariableRegexp.FindStringSubmatch(this.config.Client.Password); len(submatch) > 1 {
-		this.config.Client.Password = os.Getenv(submatch[1])
-	}
-
 	return nil
 }
 
@@ -61,6 +61,31 @@ func TestGetTableNames(t *testing.T) {
 	}
 }
 
+func TestTableNamesCase(t *testing.T) {
+	t.Run("case-sensitive", func(t *testing.T) {
+		context := NewMigrationContext()
+		context.TableNamesCaseSensitive = true
+		require.True(t, context.TableNamesEqual("MyTable", "MyTable"))
+		require.False(t, context.TableNamesEqual("MyTable", "MYtable"))
+
+		context.OriginalTableName = "MyTable"
+		other := NewMigrationContext()
+		other.TableNamesCaseSensitive = true
+		other.OriginalTableName = "MYtable"
+		require.Equal(t, "~MyTable_gho", context.GetGhostTableName())
+		require.Equal(t, "~MYtable_gho", other.GetGhostTableName())
+		require.False(t, context.TableNamesEqual(context.GetOldTableName(), other.GetOldTableName()))
+		require.False(t, context.TableNamesEqual(context.GetChangelogTableName(), other.GetChangelogTableName()))
+	})
+	t.Run("case-insensitive", func(t *testing.T) {
+		context := NewMigrationContext()
+		require.False(t, context.TableNamesCaseSensitive)
+		require.True(t, context.TableNamesEqual("MyTable", "MYtable"))
+		require.True(t, context.TableNamesEqual("~MyTable_gho", "~mytable_gho"))
+		require.False(t, context.TableNamesEqual("MyTable", "My_Table"))
+	})
+}
+
 func TestGetTriggerNames(t *testing.T) {
 	{
 		context := NewMigrationContext()
@@ -270,3 +295,72 @@ func TestSetAbortError_ThreadSafe(t *testing.T) {
 		t.Errorf("Stored error %v not in list of sent errors", got)
 	}
 }
+
+func TestAdjustDMLBatchSize(t *testing.T) {
+	migrationContext := NewMigrationContext()
+	migrationContext.SetDMLBatchSize(10)
+	require.False(t, migrationContext.IsDynamicDMLBatchSize())
+	require.Equal(t, int64(10), migrationContext.AdjustDMLBatchSize(500, time.Millisecond))
+
+	migrationContext.SetMaxDMLBatchSize(5000)
+	require.Equal(t, int64(MaxEventsBatchSize), migrationContext.MaxDMLBatchSize)
+	migrationContext.SetMaxDMLBatchSize(40)
+	require.True(t, migrationContext.IsDynamicDMLBatchSize())
+	require.Equal(t, int64(10), migrationContext.GetEffectiveDMLBatchSize())
+
+	// backlog and fast transactions: grow up to --max-dml-batch-size
+	require.Equal(t, int64(20), migrationContext.AdjustDMLBatchSize(500, time.Millisecond))
+	require.Equal(t, int64(40), migrationContext.AdjustDMLBatchSize(500, time.Millisecond))
+	require.Equal(t, int64(40), migrationContext.AdjustDMLBatchSize(500, time.Millisecond))
+
+	// no backlog, or transactions near the latency bound: keep
+	require.Equal(t, int64(40), migrationContext.AdjustDMLBatchSize(0, time.Millisecond))
+	require.Equal(t, int64(40), migrationContext.AdjustDMLBatchSize(500, 80*time.Millisecond))
+
+	// slow transactions: shrink down to --dml-batch-size
+	require.Equal(t, int64(20), migrationContext.AdjustDMLBatchSize(500, 200*time.Millisecond))
+	require.Equal(t, int64(10), migrationContext.AdjustDMLBatchSize(500, 200*time.Millisecond))
+	require.Equal(t, int64(10), migrationContext.AdjustDMLBatchSize(500, 200*time.Millisecond))
+	require.Equal(t, int64(20), migrationContext.AdjustDMLBatchSize(500, time.Millisecond))
+
+	// an interactive --dml-batch-size above the maximum disables dynamic sizing
+	migrationContext.SetDMLBatchSize(50)
+	require.False(t, migrationContext.IsDynamicDMLBatchSize())
+	require.Equal(t, int64(50), migrationContext.GetEffectiveDMLBatchSize())
+}
+
+func TestBinlogRetentionAtRisk(t *testing.T) {
+	context := NewMigrationContext()
+	require.False(t, context.IsBinlogRetentionAtRisk())
+	require.Equal(t, "N/A", context.GetBinlogRetentionRemainingDescription())
+
+	context.SetBinlogRetentionRemaining(1800)
+	require.False(t, context.IsBinlogRetentionAtRisk())
+	require.Equal(t, "30m0s", context.GetBinlogRetentionRemainingDescription())
+
+	context.BinlogRetentionMarginSeconds = 3600
+	require.True(t, context.IsBinlogRetentionAtRisk())
+	context.SetBinlogRetentionRemaining(7200)
+	require.False(t, context.IsBinlogRetentionAtRisk())
+	context.SetBinlogRetentionRemaining(0)
+	require.True(t, context.IsBinlogRetentionAtRisk())
+	require.Equal(t, "purged", context.GetBinlogRetentionRemainingDescription())
+	context.SetBinlogRetentionRemaining(BinlogRetentionUnknown)
+	require.False(t, context.IsBinlogRetentionAtRisk())
+}
+
+func TestSetExactRowsEstimate(t *testing.T) {
+	context := NewMigrationContext()
+	context.RowsEstimate = 1000
+	context.UsedRowsEstimateMethod = TableStatusRowsEstimate
+	// 300 rows deleted while counting are reflected in the count
+	context.RowsDeltaEstimate = -300
+
+	context.SetExactRowsEstimate(700)
+	require.Equal(t, CountRowsEstimate, context.UsedRowsEstimateMethod)
+	require.Equal(t, int64(700), context.RowsEstimate+context.RowsDeltaEstimate)
+
+	// a large delete after counting lowers the estimate
+	context.RowsDeltaEstimate -= 500
+	require.Equal(t, int64(200), context.RowsEstimate+context.RowsDeltaEstimate)
+}
@@ -0,0 +1,99 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package base
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	ThroughputWindowDuration = time.Minute
+	// minThroughputSampledDuration is the minimum non-throttled duration sampled
+	// before rates are considered meaningful
+	minThroughputSampledDuration = 5 * time.Second
+)
+
+type throughputSample struct {
+	timestamp  time.Time
+	rowsCopied int64
+	dmlApplied int64
+	throttled  bool
+}
+
+// ThroughputEstimator keeps a moving window of row copy and DML apply progress samples,
+// from which it computes the recent rates, excluding time spent throttled.
+type ThroughputEstimator struct {
+	window  time.Duration
+	samples []throughputSample
+	mutex   sync.Mutex
+}
+
+func NewThroughputEstimator(window time.Duration) *ThroughputEstimator {
+	return &ThroughputEstimator{
+		window:  window,
+		samples: []throughputSample{},
+	}
+}
+
+// AddSample records the total rows copied and DML events applied at given time, and
+// whether the migration was throttled at that time. Samples older than the window are discarded.
+func (this *ThroughputEstimator) AddSample(timestamp time.Time, rowsCopied, dmlApplied int64, throttled bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	this.samples = append(this.samples, throughputSample{
+		timestamp:  timestamp,
+		rowsCopied: rowsCopied,
+		dmlApplied: dmlApplied,
+		throttled:  throttled,
+	})
+	for len(this.samples) > 2 && timestamp.Sub(this.samples[0].timestamp) > this.window {
+		this.samples = this.samples[1:]
+	}
+}
+
+// Rates returns the row copy rate, its standard deviation across sampled intervals, and the DML
+// apply rate, all per second. Intervals beginning or ending throttled are excluded, along with the
+// rows copied and events applied during them. ok is false while there are too few samples.
+func (this *ThroughputEstimator) Rates() (rowsPerSecond, rowsPerSecondStdDev, dmlPerSecond float64, ok bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	var sampledSeconds float64
+	var rowsCopied, dmlApplied int64
+	intervalRates := []float64{}
+	intervalSeconds := []float64{}
+	for i := 1; i < len(this.samples); i++ {
+		previous, current := this.samples[i-1], this.samples[i]
+		if previous.throttled || current.throttled {
+			continue
+		}
+		seconds := current.timestamp.Sub(previous.timestamp).Seconds()
+		if seconds <= 0 {
+			continue
+		}
+		sampledSeconds += seconds
+		rowsCopied += current.rowsCopied - previous.rowsCopied
+		dmlApplied += current.dmlApplied - previous.dmlApplied
+		intervalRates = append(intervalRates, float64(current.rowsCopied-previous.rowsCopied)/seconds)
+		intervalSeconds = append(intervalSeconds, seconds)
+	}
+	if sampledSeconds < minThroughputSampledDuration.Seconds() {
+		return 0, 0, 0, false
+	}
+	rowsPerSecond = float64(rowsCopied) / sampledSeconds
+	dmlPerSecond = float64(dmlApplied) / sampledSeconds
+
+	// time weighted variance of the per-interval rates
+	var variance float64
+	for i, rate := range intervalRates {
+		variance += intervalSeconds[i] * (rate - rowsPerSecond) * (rate - rowsPerSecond)
+	}
+	rowsPerSecondStdDev = math.Sqrt(variance / sampledSeconds)
+	return rowsPerSecond, rowsPerSecondStdDev, dmlPerSecond, true
+}
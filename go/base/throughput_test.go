@@ -0,0 +1,88 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package base
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThroughputEstimatorRates(t *testing.T) {
+	start := time.Now()
+
+	t.Run("too few samples", func(t *testing.T) {
+		estimator := NewThroughputEstimator(time.Minute)
+		estimator.AddSample(start, 0, 0, false)
+		estimator.AddSample(start.Add(time.Second), 100, 10, false)
+		_, _, _, ok := estimator.Rates()
+		require.False(t, ok)
+	})
+
+	t.Run("steady", func(t *testing.T) {
+		estimator := NewThroughputEstimator(time.Minute)
+		for i := 0; i <= 10; i++ {
+			estimator.AddSample(start.Add(time.Duration(i)*time.Second), int64(i*100), int64(i*10), false)
+		}
+		rowsPerSecond, rowsPerSecondStdDev, dmlPerSecond, ok := estimator.Rates()
+		require.True(t, ok)
+		require.InDelta(t, 100.0, rowsPerSecond, 0.001)
+		require.InDelta(t, 0.0, rowsPerSecondStdDev, 0.001)
+		require.InDelta(t, 10.0, dmlPerSecond, 0.001)
+	})
+
+	t.Run("excludes throttled time", func(t *testing.T) {
+		estimator := NewThroughputEstimator(time.Minute)
+		var rowsCopied int64
+		for i := 0; i <= 30; i++ {
+			throttled := i >= 10 && i < 20
+			if i > 0 && !throttled {
+				rowsCopied += 100
+			}
+			estimator.AddSample(start.Add(time.Duration(i)*time.Second), rowsCopied, 0, throttled)
+		}
+		rowsPerSecond, _, _, ok := estimator.Rates()
+		require.True(t, ok)
+		// the interval ending as throttling lifts is excluded along with its rows
+		require.InDelta(t, 100.0, rowsPerSecond, 0.001)
+	})
+
+	t.Run("moving window", func(t *testing.T) {
+		estimator := NewThroughputEstimator(10 * time.Second)
+		var rowsCopied int64
+		for i := 0; i <= 30; i++ {
+			if i > 0 {
+				if i <= 15 {
+					rowsCopied += 1000
+				} else {
+					rowsCopied += 100
+				}
+			}
+			estimator.AddSample(start.Add(time.Duration(i)*time.Second), rowsCopied, 0, false)
+		}
+		rowsPerSecond, _, _, ok := estimator.Rates()
+		require.True(t, ok)
+		require.InDelta(t, 100.0, rowsPerSecond, 0.001)
+	})
+
+	t.Run("variable", func(t *testing.T) {
+		estimator := NewThroughputEstimator(time.Minute)
+		var rowsCopied int64
+		for i := 0; i <= 10; i++ {
+			if i%2 == 1 {
+				rowsCopied += 150
+			} else if i > 0 {
+				rowsCopied += 50
+			}
+			estimator.AddSample(start.Add(time.Duration(i)*time.Second), rowsCopied, 0, false)
+		}
+		rowsPerSecond, rowsPerSecondStdDev, _, ok := estimator.Rates()
+		require.True(t, ok)
+		require.InDelta(t, 100.0, rowsPerSecond, 0.001)
+		require.InDelta(t, 50.0, rowsPerSecondStdDev, 0.001)
+	})
+}
@@ -0,0 +1,120 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package base
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/openark/golib/log"
+)
+
+// writerLogger logs onto a writer, in the same format as the default logger. Unlike the default logger,
+// which wraps the process wide golib logger, each writerLogger has its own level, such that migrations
+// running in one process log independently. Its Fatal functions log and return an error, but do not exit.
+type writerLogger struct {
+	writer          io.Writer
+	level           log.LogLevel
+	printStackTrace bool
+	mutex           sync.Mutex
+}
+
+func NewWriterLogger(writer io.Writer) *writerLogger {
+	return &writerLogger{
+		writer: writer,
+		level:  log.INFO,
+	}
+}
+
+func (this *writerLogger) logEntry(level log.LogLevel, message string) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if level > this.level {
+		return
+	}
+	fmt.Fprintf(this.writer, "%s %s %s\n", time.Now().Format(log.TimeFormat), level, message)
+	if this.printStackTrace && level <= log.ERROR {
+		fmt.Fprintln(this.writer, string(debug.Stack()))
+	}
+}
+
+func (this *writerLogger) logError(level log.LogLevel, message string) error {
+	this.logEntry(level, message)
+	return errors.New(message)
+}
+
+func (this *writerLogger) Debug(args ...interface{}) {
+	this.logEntry(log.DEBUG, fmt.Sprint(args...))
+}
+
+func (this *writerLogger) Debugf(format string, args ...interface{}) {
+	this.logEntry(log.DEBUG, fmt.Sprintf(format, args...))
+}
+
+func (this *writerLogger) Info(args ...interface{}) {
+	this.logEntry(log.INFO, fmt.Sprint(args...))
+}
+
+func (this *writerLogger) Infof(format string, args ...interface{}) {
+	this.logEntry(log.INFO, fmt.Sprintf(format, args...))
+}
+
+func (this *writerLogger) Warning(args ...interface{}) error {
+	return this.logError(log.WARNING, fmt.Sprint(args...))
+}
+
+func (this *writerLogger) Warningf(format string, args ...interface{}) error {
+	return this.logError(log.WARNING, fmt.Sprintf(format, args...))
+}
+
+func (this *writerLogger) Error(args ...interface{}) error {
+	return this.logError(log.ERROR, fmt.Sprint(args...))
+}
+
+func (this *writerLogger) Errorf(format string, args ...interface{}) error {
+	return this.logError(log.ERROR, fmt.Sprintf(format, args...))
+}
+
+func (this *writerLogger) Errore(err error) error {
+	if err == nil {
+		return nil
+	}
+	this.logEntry(log.ERROR, err.Error())
+	return err
+}
+
+func (this *writerLogger) Fatal(args ...interface{}) error {
+	return this.logError(log.FATAL, fmt.Sprint(args...))
+}
+
+func (this *writerLogger) Fatalf(format string, args ...interface{}) error {
+	return this.logError(log.FATAL, fmt.Sprintf(format, args...))
+}
+
+func (this *writerLogger) Fatale(err error) error {
+	if err == nil {
+		return nil
+	}
+	this.logEntry(log.FATAL, err.Error())
+	return err
+}
+
+func (this *writerLogger) SetLevel(level log.LogLevel) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.level = level
+}
+
+func (this *writerLogger) SetPrintStackTrace(printStackTraceFlag bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.printStackTrace = printStackTraceFlag
+}
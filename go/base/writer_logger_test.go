@@ -0,0 +1,53 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package base
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/openark/golib/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewWriterLogger(&buffer)
+
+	logger.Debugf("debug %d", 1)
+	require.Empty(t, buffer.String())
+
+	logger.Infof("info %d", 2)
+	require.Contains(t, buffer.String(), " INFO info 2\n")
+
+	err := logger.Errorf("error %d", 3)
+	require.EqualError(t, err, "error 3")
+	require.Contains(t, buffer.String(), " ERROR error 3\n")
+
+	// does not exit
+	err = logger.Fatale(errors.New("fatal 4"))
+	require.EqualError(t, err, "fatal 4")
+	require.Contains(t, buffer.String(), " FATAL fatal 4\n")
+	require.NoError(t, logger.Errore(nil))
+
+	logger.SetLevel(log.DEBUG)
+	logger.Debug("debug 5")
+	require.Contains(t, buffer.String(), " DEBUG debug 5\n")
+}
+
+func TestWriterLoggersAreIndependent(t *testing.T) {
+	var quietBuffer, verboseBuffer bytes.Buffer
+	quiet := NewWriterLogger(&quietBuffer)
+	verbose := NewWriterLogger(&verboseBuffer)
+	quiet.SetLevel(log.ERROR)
+	verbose.SetLevel(log.DEBUG)
+
+	quiet.Info("info")
+	verbose.Info("info")
+	require.Empty(t, quietBuffer.String())
+	require.Contains(t, verboseBuffer.String(), " INFO info\n")
+}
@@ -0,0 +1,261 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package binlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// Encrypted binary logs (binlog_encryption=ON, MySQL 8.0.14 and above) start with a fixed size
+// header, followed by the plain binary log content encrypted with AES-256-CTR. The header
+// holds the id of the replication master key in the keyring, and the file password encrypted
+// with that key. The file key and the counter's initial value derive from the file password.
+// A server decrypts binary logs before sending them to replicas: only files read off the
+// filesystem need decrypting.
+const (
+	encryptionHeaderSize    = 512
+	encryptionHeaderVersion = 1
+	filePasswordSize        = 32
+	encryptionIVSize        = 16
+
+	encryptionFieldEnd          = 0
+	encryptionFieldKeyId        = 1
+	encryptionFieldFilePassword = 2
+	encryptionFieldIV           = 3
+)
+
+var encryptedBinlogMagic = []byte{0xfd, 'b', 'i', 'n'}
+
+// keyring_file plugin data file layout
+const (
+	keyringFileVersionLength = 24
+	keyringFileEOF           = "EOF"
+	keyringFileDigestLength  = 32
+	keyringKeyObfuscation    = "*305=Ljt0*!@$Hnm(*-9-w;:"
+)
+
+var keyringFileVersions = []string{"Keyring file version:1.0", "Keyring file version:2.0"}
+
+// Keyring maps key ids onto key data
+type Keyring map[string][]byte
+
+// encryptionHeader is the header of an encrypted binary log file
+type encryptionHeader struct {
+	keyId                 string
+	encryptedFilePassword []byte
+	iv                    []byte
+}
+
+// ReadKeyringFile reads the keys of a keyring_file plugin data file, as set with keyring_file_data
+func ReadKeyringFile(fileName string) (Keyring, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeyring(data)
+}
+
+func parseKeyring(data []byte) (Keyring, error) {
+	keyring := Keyring{}
+	if len(data) == 0 {
+		return keyring, nil
+	}
+	trailerLength := len(keyringFileEOF) + keyringFileDigestLength
+	if len(data) < keyringFileVersionLength+trailerLength {
+		return nil, fmt.Errorf("Keyring file is too short: %d bytes", len(data))
+	}
+	version := string(data[:keyringFileVersionLength])
+	isKnownVersion := false
+	for _, knownVersion := range keyringFileVersions {
+		if version == knownVersion {
+			isKnownVersion = true
+		}
+	}
+	if !isKnownVersion {
+		return nil, fmt.Errorf("Unsupported keyring file version: %q", version)
+	}
+	eofOffset := len(data) - trailerLength
+	if string(data[eofOffset:eofOffset+len(keyringFileEOF)]) != keyringFileEOF {
+		return nil, fmt.Errorf("Keyring file is truncated: no EOF marker")
+	}
+
+	// Each key is a POD: five 8 byte lengths (the POD, key id, key type, user id and key data)
+	// followed by the fields themselves, padded to 8 bytes
+	keys := data[keyringFileVersionLength:eofOffset]
+	for len(keys) > 0 {
+		if len(keys) < 5*8 {
+			return nil, fmt.Errorf("Keyring file has a truncated key")
+		}
+		var lengths [5]uint64
+		for i := range lengths {
+			lengths[i] = binary.LittleEndian.Uint64(keys[i*8:])
+		}
+		podSize, keyIdLength, keyTypeLength, userIdLength, keyLength := lengths[0], lengths[1], lengths[2], lengths[3], lengths[4]
+		if podSize > uint64(len(keys)) || 5*8+keyIdLength+keyTypeLength+userIdLength+keyLength > podSize {
+			return nil, fmt.Errorf("Keyring file has a malformed key")
+		}
+		field := keys[5*8:]
+		keyId := string(field[:keyIdLength])
+		field = field[keyIdLength+keyTypeLength+userIdLength:]
+		key := make([]byte, keyLength)
+		for i := range key {
+			key[i] = field[i] ^ keyringKeyObfuscation[i%len(keyringKeyObfuscation)]
+		}
+		keyring[keyId] = key
+		keys = keys[podSize:]
+	}
+	return keyring, nil
+}
+
+func parseEncryptionHeader(header []byte) (*encryptionHeader, error) {
+	if len(header) != encryptionHeaderSize || !bytes.Equal(header[:len(encryptedBinlogMagic)], encryptedBinlogMagic) {
+		return nil, fmt.Errorf("Not an encrypted binary log header")
+	}
+	if version := header[len(encryptedBinlogMagic)]; version != encryptionHeaderVersion {
+		return nil, fmt.Errorf("Unsupported binary log encryption version: %d", version)
+	}
+	encryptionHeader := &encryptionHeader{}
+	offset := len(encryptedBinlogMagic) + 1
+	readField := func(length int) ([]byte, error) {
+		if offset+length > len(header) {
+			return nil, fmt.Errorf("Truncated binary log encryption header")
+		}
+		field := header[offset : offset+length]
+		offset += length
+		return field, nil
+	}
+	for offset < len(header) && header[offset] != encryptionFieldEnd {
+		fieldType := header[offset]
+		offset++
+		switch fieldType {
+		case encryptionFieldKeyId:
+			length, err := readField(1)
+			if err != nil {
+				return nil, err
+			}
+			keyId, err := readField(int(length[0]))
+			if err != nil {
+				return nil, err
+			}
+			encryptionHeader.keyId = string(keyId)
+		case encryptionFieldFilePassword:
+			encryptedFilePassword, err := readField(filePasswordSize)
+			if err != nil {
+				return nil, err
+			}
+			encryptionHeader.encryptedFilePassword = encryptedFilePassword
+		case encryptionFieldIV:
+			iv, err := readField(encryptionIVSize)
+			if err != nil {
+				return nil, err
+			}
+			encryptionHeader.iv = iv
+		default:
+			return nil, fmt.Errorf("Unknown binary log encryption header field: %d", fieldType)
+		}
+	}
+	if encryptionHeader.keyId == "" || encryptionHeader.encryptedFilePassword == nil || encryptionHeader.iv == nil {
+		return nil, fmt.Errorf("Incomplete binary log encryption header")
+	}
+	return encryptionHeader, nil
+}
+
+// filePassword decrypts the file password with the replication master key (AES-256-CBC, no padding)
+func (this *encryptionHeader) filePassword(keyring Keyring) ([]byte, error) {
+	masterKey, ok := keyring[this.keyId]
+	if !ok {
+		return nil, fmt.Errorf("Key %s not found in keyring", this.keyId)
+	}
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid key %s: %+v", this.keyId, err)
+	}
+	filePassword := make([]byte, filePasswordSize)
+	cipher.NewCBCDecrypter(block, this.iv).CryptBlocks(filePassword, this.encryptedFilePassword)
+	return filePassword, nil
+}
+
+// newFileCipherStream returns the AES-256-CTR stream of a file: the key is the first 32 bytes
+// of the SHA-512 digest of the file password, and the counter starts at the next 16 bytes
+func newFileCipherStream(filePassword []byte) (cipher.Stream, error) {
+	digest := sha512.Sum512(filePassword)
+	block, err := aes.NewCipher(digest[:32])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewCTR(block, digest[32:32+aes.BlockSize]), nil
+}
+
+type binlogFileReader struct {
+	io.Reader
+	io.Closer
+}
+
+// OpenBinlogFile opens a binary log file, decrypting it with the given keyring if it is encrypted.
+// The returned reader is positioned at the first event, past the binary log magic number.
+func OpenBinlogFile(fileName string, keyring Keyring) (io.ReadCloser, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newBinlogReader(bufio.NewReader(file), keyring)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %+v", fileName, err)
+	}
+	return &binlogFileReader{Reader: reader, Closer: file}, nil
+}
+
+func newBinlogReader(reader *bufio.Reader, keyring Keyring) (io.Reader, error) {
+	magic, err := reader.Peek(len(replication.BinLogFileHeader))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(magic, encryptedBinlogMagic) {
+		header := make([]byte, encryptionHeaderSize)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return nil, err
+		}
+		encryptionHeader, err := parseEncryptionHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		filePassword, err := encryptionHeader.filePassword(keyring)
+		if err != nil {
+			return nil, err
+		}
+		stream, err := newFileCipherStream(filePassword)
+		if err != nil {
+			return nil, err
+		}
+		decrypted := &cipher.StreamReader{S: stream, R: reader}
+		magic = make([]byte, len(replication.BinLogFileHeader))
+		if _, err := io.ReadFull(decrypted, magic); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(magic, replication.BinLogFileHeader) {
+			return nil, fmt.Errorf("Decrypted content is not a binary log; is key %s correct?", encryptionHeader.keyId)
+		}
+		return decrypted, nil
+	}
+	if !bytes.Equal(magic, replication.BinLogFileHeader) {
+		return nil, fmt.Errorf("Not a binary log file")
+	}
+	if _, err := reader.Discard(len(magic)); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
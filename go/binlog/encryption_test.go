@@ -0,0 +1,137 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package binlog
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testReplicationKeyId = "MySQLReplicationKey_6b1d4d6a-4f6d-11ee-9d46-0242ac110002_1"
+
+// writeTestKeyringFile writes keys the way the keyring_file plugin does
+func writeTestKeyringFile(t *testing.T, keys map[string][]byte) string {
+	var buf bytes.Buffer
+	buf.WriteString("Keyring file version:2.0")
+	for keyId, key := range keys {
+		keyType, userId := "AES", ""
+		podSize := 5*8 + len(keyId) + len(keyType) + len(userId) + len(key)
+		padding := (8 - podSize%8) % 8
+		for _, length := range []int{podSize + padding, len(keyId), len(keyType), len(userId), len(key)} {
+			require.NoError(t, binary.Write(&buf, binary.LittleEndian, uint64(length)))
+		}
+		buf.WriteString(keyId + keyType + userId)
+		for i, b := range key {
+			buf.WriteByte(b ^ keyringKeyObfuscation[i%len(keyringKeyObfuscation)])
+		}
+		buf.Write(make([]byte, padding))
+	}
+	buf.WriteString(keyringFileEOF)
+	buf.Write(make([]byte, keyringFileDigestLength))
+
+	fileName := filepath.Join(t.TempDir(), "keyring")
+	require.NoError(t, os.WriteFile(fileName, buf.Bytes(), 0600))
+	return fileName
+}
+
+// writeTestEncryptedBinlog encrypts a binary log file the way a server with binlog_encryption=ON does
+func writeTestEncryptedBinlog(t *testing.T, plainFileName string, keyId string, masterKey []byte) string {
+	plain, err := os.ReadFile(plainFileName)
+	require.NoError(t, err)
+
+	filePassword := bytes.Repeat([]byte{0x5a}, filePasswordSize)
+	iv := bytes.Repeat([]byte{0x17}, encryptionIVSize)
+	block, err := aes.NewCipher(masterKey)
+	require.NoError(t, err)
+	encryptedFilePassword := make([]byte, filePasswordSize)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encryptedFilePassword, filePassword)
+
+	header := make([]byte, 0, encryptionHeaderSize)
+	header = append(header, encryptedBinlogMagic...)
+	header = append(header, encryptionHeaderVersion)
+	header = append(header, encryptionFieldKeyId, byte(len(keyId)))
+	header = append(header, keyId...)
+	header = append(header, encryptionFieldFilePassword)
+	header = append(header, encryptedFilePassword...)
+	header = append(header, encryptionFieldIV)
+	header = append(header, iv...)
+	header = header[:encryptionHeaderSize]
+
+	stream, err := newFileCipherStream(filePassword)
+	require.NoError(t, err)
+	encrypted := make([]byte, len(plain))
+	stream.XORKeyStream(encrypted, plain)
+
+	fileName := filepath.Join(t.TempDir(), filepath.Base(plainFileName))
+	require.NoError(t, os.WriteFile(fileName, append(header, encrypted...), 0600))
+	return fileName
+}
+
+func TestReadKeyringFile(t *testing.T) {
+	masterKey := bytes.Repeat([]byte{0x42}, 32)
+	otherKey := []byte("short key")
+	keyring, err := ReadKeyringFile(writeTestKeyringFile(t, map[string][]byte{
+		testReplicationKeyId: masterKey,
+		"other":              otherKey,
+	}))
+	require.NoError(t, err)
+	require.Len(t, keyring, 2)
+	require.Equal(t, masterKey, keyring[testReplicationKeyId])
+	require.Equal(t, otherKey, keyring["other"])
+
+	_, err = parseKeyring([]byte("Keyring file version:9.0EOF" + string(make([]byte, keyringFileDigestLength))))
+	require.Error(t, err)
+}
+
+func TestOpenBinlogFile(t *testing.T) {
+	plainFileName := "testdata/mysql-bin.000066"
+	plain, err := os.ReadFile(plainFileName)
+	require.NoError(t, err)
+
+	t.Run("plain", func(t *testing.T) {
+		reader, err := OpenBinlogFile(plainFileName, nil)
+		require.NoError(t, err)
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, plain[4:], content)
+	})
+
+	masterKey := bytes.Repeat([]byte{0x42}, 32)
+	encryptedFileName := writeTestEncryptedBinlog(t, plainFileName, testReplicationKeyId, masterKey)
+
+	t.Run("encrypted", func(t *testing.T) {
+		reader, err := OpenBinlogFile(encryptedFileName, Keyring{testReplicationKeyId: masterKey})
+		require.NoError(t, err)
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, plain[4:], content)
+	})
+
+	t.Run("encrypted-missing-key", func(t *testing.T) {
+		_, err := OpenBinlogFile(encryptedFileName, Keyring{})
+		require.ErrorContains(t, err, "Key "+testReplicationKeyId+" not found in keyring")
+	})
+
+	t.Run("encrypted-wrong-key", func(t *testing.T) {
+		_, err := OpenBinlogFile(encryptedFileName, Keyring{testReplicationKeyId: bytes.Repeat([]byte{0x43}, 32)})
+		require.ErrorContains(t, err, "Decrypted content is not a binary log")
+	})
+
+	t.Run("not-binlog", func(t *testing.T) {
+		_, err := OpenBinlogFile("testdata/rbr-sample-0.txt", nil)
+		require.ErrorContains(t, err, "Not a binary log file")
+	})
+}
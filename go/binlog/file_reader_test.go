@@ -0,0 +1,46 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package binlog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/github/gh-ost/go/base"
+	"github.com/github/gh-ost/go/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestBinlogEntries(t *testing.T, reader *FileBinlogReader) []*BinlogEntry {
+	entriesChannel := make(chan *BinlogEntry, 1000)
+	require.NoError(t, reader.StreamEvents(func() bool { return false }, entriesChannel))
+	close(entriesChannel)
+	var entries []*BinlogEntry
+	for entry := range entriesChannel {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestFileBinlogReaderStreamEvents(t *testing.T) {
+	migrationContext := base.NewMigrationContext()
+	plainFileName := "testdata/mysql-bin.000066"
+
+	entries := readTestBinlogEntries(t, NewFileBinlogReader(migrationContext, []string{plainFileName}, nil))
+	require.NotEmpty(t, entries)
+	for _, entry := range entries {
+		require.Equal(t, "mysql-bin.000066", entry.Coordinates.(*mysql.FileBinlogCoordinates).LogFile)
+		require.NotNil(t, entry.DmlEvent)
+	}
+
+	masterKey := bytes.Repeat([]byte{0x42}, 32)
+	encryptedFileName := writeTestEncryptedBinlog(t, plainFileName, testReplicationKeyId, masterKey)
+	encryptedEntries := readTestBinlogEntries(t, NewFileBinlogReader(migrationContext, []string{encryptedFileName}, Keyring{testReplicationKeyId: masterKey}))
+	require.Equal(t, entries, encryptedEntries)
+
+	stopped := NewFileBinlogReader(migrationContext, []string{plainFileName}, nil)
+	require.NoError(t, stopped.StreamEvents(func() bool { return true }, make(chan *BinlogEntry)))
+}
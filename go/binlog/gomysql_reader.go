@@ -33,13 +33,22 @@ type GoMySQLReader struct {
 	// LastTrxCoords are the coordinates of the last transaction completely read.
 	// If using the file coordinates it is binlog position of the transaction's XID event.
 	LastTrxCoords mysql.BinlogCoordinates
+	// EmitTransactionBoundaries sends an entry with no DML event at the end of each transaction,
+	// at the transaction's coordinates.
+	EmitTransactionBoundaries bool
 }
 
 // ErrMaxAuthFailures marks authentication failures that crossed the configured limit.
 var ErrMaxAuthFailures = errors.New("max authentication failures reached")
 
-func NewGoMySQLReader(migrationContext *base.MigrationContext) *GoMySQLReader {
-	connectionConfig := migrationContext.InspectorConnectionConfig
+// NewGoMySQLReader creates a binlog reader with the current credentials of the given connection,
+// typically the inspector's. With a credentials provider, go-mysql's own reconnects are disabled:
+// the events streamer reconnects through a new reader instead, so that rotated credentials apply.
+func NewGoMySQLReader(migrationContext *base.MigrationContext, connectionConfig *mysql.ConnectionConfig) (*GoMySQLReader, error) {
+	user, password, err := connectionConfig.GetCredentials()
+	if err != nil {
+		return nil, err
+	}
 	return &GoMySQLReader{
 		migrationContext:        migrationContext,
 		connectionConfig:        connectionConfig,
@@ -49,15 +58,16 @@ func NewGoMySQLReader(migrationContext *base.MigrationContext) *GoMySQLReader {
 			Flavor:                  gomysql.MySQLFlavor,
 			Host:                    connectionConfig.Key.Hostname,
 			Port:                    uint16(connectionConfig.Key.Port),
-			User:                    connectionConfig.User,
-			Password:                connectionConfig.Password,
+			User:                    user,
+			Password:                password,
 			TLSConfig:               connectionConfig.TLSConfig(),
 			UseDecimal:              true,
 			TimestampStringLocation: time.UTC,
 			MaxReconnectAttempts:    migrationContext.BinlogSyncerMaxReconnectAttempts,
+			DisableRetrySync:        connectionConfig.CredentialsProvider != nil,
 			Dialer:                  connectionConfig.Dialer,
 		}),
-	}
+	}, nil
 }
 
 // handleAuthError processes authentication errors and applies circuit breaker logic
@@ -123,7 +133,11 @@ func (this *GoMySQLReader) GetCurrentBinlogCoordinates() mysql.BinlogCoordinates
 }
 
 func (this *GoMySQLReader) handleRowsEvent(ev *replication.BinlogEvent, rowsEvent *replication.RowsEvent, entriesChannel chan<- *BinlogEntry) error {
-	currentCoords := this.GetCurrentBinlogCoordinates()
+	return emitRowsEvent(this.GetCurrentBinlogCoordinates(), ev, rowsEvent, entriesChannel)
+}
+
+// emitRowsEvent sends a binlog entry per row change of a rows event, read at the given coordinates
+func emitRowsEvent(currentCoords mysql.BinlogCoordinates, ev *replication.BinlogEvent, rowsEvent *replication.RowsEvent, entriesChannel chan<- *BinlogEntry) error {
 	dml := ToEventDML(ev.Header.EventType.String())
 	if dml == NotDML {
 		return fmt.Errorf("unknown DML type: %s", ev.Header.EventType.String())
@@ -140,19 +154,26 @@ func (this *GoMySQLReader) handleRowsEvent(ev *replication.BinlogEvent, rowsEven
 			string(rowsEvent.Table.Table),
 			dml,
 		)
+		// With binlog_row_image MINIMAL or NOBLOB, row images may lack columns
+		absentColumns := func(rowIndex int) []int {
+			if rowIndex < len(rowsEvent.SkippedColumns) {
+				return rowsEvent.SkippedColumns[rowIndex]
+			}
+			return nil
+		}
 		switch dml {
 		case InsertDML:
 			{
-				binlogEntry.DmlEvent.NewColumnValues = sql.ToColumnValues(row)
+				binlogEntry.DmlEvent.NewColumnValues = sql.ToPartialColumnValues(row, absentColumns(i))
 			}
 		case UpdateDML:
 			{
-				binlogEntry.DmlEvent.WhereColumnValues = sql.ToColumnValues(row)
-				binlogEntry.DmlEvent.NewColumnValues = sql.ToColumnValues(rowsEvent.Rows[i+1])
+				binlogEntry.DmlEvent.WhereColumnValues = sql.ToPartialColumnValues(row, absentColumns(i))
+				binlogEntry.DmlEvent.NewColumnValues = sql.ToPartialColumnValues(rowsEvent.Rows[i+1], absentColumns(i+1))
 			}
 		case DeleteDML:
 			{
-				binlogEntry.DmlEvent.WhereColumnValues = sql.ToColumnValues(row)
+				binlogEntry.DmlEvent.WhereColumnValues = sql.ToPartialColumnValues(row, absentColumns(i))
 			}
 		}
 
@@ -182,7 +203,9 @@ func (this *GoMySQLReader) StreamEvents(canStopStreaming func() bool, entriesCha
 
 		// Update binlog coords if using file-based coords.
 		// GTID coordinates are updated on receiving GTID events.
-		if !this.migrationContext.UseGTIDs {
+		// Artificial events, such as the rotate event the server sends when the stream starts,
+		// have no position of their own.
+		if !this.migrationContext.UseGTIDs && ev.Header.LogPos > 0 {
 			this.currentCoordinatesMutex.Lock()
 			coords := this.currentCoordinates.(*mysql.FileBinlogCoordinates)
 			prevCoords := coords.Clone().(*mysql.FileBinlogCoordinates)
@@ -218,8 +241,9 @@ func (this *GoMySQLReader) StreamEvents(canStopStreaming func() bool, entriesCha
 			}
 			this.currentCoordinatesMutex.Lock()
 			coords := this.currentCoordinates.(*mysql.FileBinlogCoordinates)
+			this.migrationContext.Log.Infof("rotate to next log from %s:%d to %s", coords.LogFile, coords.LogPos, event.NextLogName)
 			coords.LogFile = string(event.NextLogName)
-			this.migrationContext.Log.Infof("rotate to next log from %s:%d to %s", coords.LogFile, int64(ev.Header.LogPos), event.NextLogName)
+			coords.LogPos = int64(event.Position)
 			this.currentCoordinatesMutex.Unlock()
 		case *replication.XIDEvent:
 			if this.migrationContext.UseGTIDs {
@@ -227,6 +251,9 @@ func (this *GoMySQLReader) StreamEvents(canStopStreaming func() bool, entriesCha
 			} else {
 				this.LastTrxCoords = this.currentCoordinates.Clone()
 			}
+			if this.EmitTransactionBoundaries {
+				entriesChannel <- NewBinlogEntryAt(this.LastTrxCoords.Clone())
+			}
 		case *replication.RowsEvent:
 			if err := this.handleRowsEvent(ev, event, entriesChannel); err != nil {
 				return err
@@ -6,17 +6,15 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"net/url"
 	"os"
 	"os/signal"
-	"regexp"
 	"syscall"
 
 	"github.com/github/gh-ost/go/base"
-	"github.com/github/gh-ost/go/logic"
-	"github.com/github/gh-ost/go/sql"
+	"github.com/github/gh-ost/go/ghost"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/openark/golib/log"
 
@@ -36,7 +34,7 @@ func acceptSignals(migrationContext *base.MigrationContext) {
 			case syscall.SIGHUP:
 				migrationContext.Log.Infof("Received SIGHUP. Reloading configuration")
 				if err := migrationContext.ReadConfigFile(); err != nil {
-					log.Errore(err)
+					migrationContext.Log.Errore(err)
 				} else {
 					migrationContext.MarkPointOfInterest()
 				}
@@ -47,115 +45,133 @@ func acceptSignals(migrationContext *base.MigrationContext) {
 
 // main is the application's entry point. It will either spawn a CLI or HTTP interfaces.
 func main() {
-	migrationContext := base.NewMigrationContext()
-	flag.StringVar(&migrationContext.InspectorConnectionConfig.Key.Hostname, "host", "127.0.0.1", "MySQL hostname (preferably a replica, not the master)")
-	flag.StringVar(&migrationContext.AssumeMasterHostname, "assume-master-host", "", "(optional) explicitly tell gh-ost the identity of the master. Format: some.host.com[:port] This is useful in master-master setups where you wish to pick an explicit master, or in a tungsten-replicator where gh-ost is unable to determine the master")
-	flag.IntVar(&migrationContext.InspectorConnectionConfig.Key.Port, "port", 3306, "MySQL port (preferably a replica, not the master)")
-	flag.Float64Var(&migrationContext.InspectorConnectionConfig.Timeout, "mysql-timeout", 0.0, "Connect, read and write timeout for MySQL")
-	flag.StringVar(&migrationContext.CliUser, "user", "", "MySQL user")
-	flag.StringVar(&migrationContext.CliPassword, "password", "", "MySQL password")
-	flag.StringVar(&migrationContext.CliMasterUser, "master-user", "", "MySQL user on master, if different from that on replica. Requires --assume-master-host")
-	flag.StringVar(&migrationContext.CliMasterPassword, "master-password", "", "MySQL password on master, if different from that on replica. Requires --assume-master-host")
-	flag.StringVar(&migrationContext.ConfigFile, "conf", "", "Config file")
+	options := ghost.DefaultOptions()
+	flag.StringVar(&options.Host, "host", "127.0.0.1", "MySQL hostname (preferably a replica, not the master)")
+	flag.StringVar(&options.AssumeMasterHost, "assume-master-host", "", "(optional) explicitly tell gh-ost the identity of the master. Format: some.host.com[:port] This is useful in master-master setups where you wish to pick an explicit master, or in a tungsten-replicator where gh-ost is unable to determine the master")
+	flag.IntVar(&options.Port, "port", 3306, "MySQL port (preferably a replica, not the master)")
+	flag.StringVar(&options.ReplicationChannel, "replication-channel", "", "(optional) on a multi-source replica, the replication channel whose source owns the migrated table. When not given, gh-ost picks the channel whose source has the migrated table")
+	flag.Float64Var(&options.MySQLTimeout, "mysql-timeout", 0.0, "Connect, read and write timeout for MySQL")
+	flag.StringVar(&options.User, "user", "", "MySQL user")
+	flag.StringVar(&options.Password, "password", "", "MySQL password")
+	flag.StringVar(&options.MasterUser, "master-user", "", "MySQL user on master, if different from that on replica. Requires --assume-master-host")
+	flag.StringVar(&options.MasterPassword, "master-password", "", "MySQL password on master, if different from that on replica. Requires --assume-master-host")
+	flag.StringVar(&options.ConfigFile, "conf", "", "Config file (ini, or YAML when named *.yaml/*.yml). Keys are flag names, e.g. chunk-size or chunk_size, optionally grouped in sections. Flags given on the command line take precedence")
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration, merged from --conf and command line flags, as a YAML config file & exit")
 	askPass := flag.Bool("ask-pass", false, "prompt for MySQL password")
-	charset := flag.String("charset", "utf8mb4,utf8,latin1", "The default charset for the database connection is utf8mb4, utf8, latin1.")
-
-	flag.BoolVar(&migrationContext.UseTLS, "ssl", false, "Enable SSL encrypted connections to MySQL hosts")
-	flag.StringVar(&migrationContext.TLSCACertificate, "ssl-ca", "", "CA certificate in PEM format for TLS connections to MySQL hosts. Requires --ssl")
-	flag.StringVar(&migrationContext.TLSCertificate, "ssl-cert", "", "Certificate in PEM format for TLS connections to MySQL hosts. Requires --ssl")
-	flag.StringVar(&migrationContext.TLSKey, "ssl-key", "", "Key in PEM format for TLS connections to MySQL hosts. Requires --ssl")
-	flag.BoolVar(&migrationContext.TLSAllowInsecure, "ssl-allow-insecure", false, "Skips verification of MySQL hosts' certificate chain and host name. Requires --ssl")
-
-	flag.StringVar(&migrationContext.DatabaseName, "database", "", "database name (mandatory)")
-	flag.StringVar(&migrationContext.OriginalTableName, "table", "", "table name (mandatory)")
-	flag.StringVar(&migrationContext.AlterStatement, "alter", "", "alter statement (mandatory)")
-	flag.BoolVar(&migrationContext.AttemptInstantDDL, "attempt-instant-ddl", false, "Attempt to use instant DDL for this migration first")
-	storageEngine := flag.String("storage-engine", "innodb", "Specify table storage engine (default: 'innodb'). When 'rocksdb': the session transaction isolation level is changed from REPEATABLE_READ to READ_COMMITTED.")
-
-	flag.BoolVar(&migrationContext.CountTableRows, "exact-rowcount", false, "actually count table rows as opposed to estimate them (results in more accurate progress estimation)")
-	flag.BoolVar(&migrationContext.ConcurrentCountTableRows, "concurrent-rowcount", true, "(with --exact-rowcount), when true (default): count rows after row-copy begins, concurrently, and adjust row estimate later on; when false: first count rows, then start row copy")
-	flag.BoolVar(&migrationContext.AllowedRunningOnMaster, "allow-on-master", false, "allow this migration to run directly on master. Preferably it would run on a replica")
-	flag.BoolVar(&migrationContext.AllowedMasterMaster, "allow-master-master", false, "explicitly allow running in a master-master setup")
-	flag.BoolVar(&migrationContext.NullableUniqueKeyAllowed, "allow-nullable-unique-key", false, "allow gh-ost to migrate based on a unique key with nullable columns. As long as no NULL values exist, this should be OK. If NULL values exist in chosen key, data may be corrupted. Use at your own risk!")
-	flag.BoolVar(&migrationContext.ApproveRenamedColumns, "approve-renamed-columns", false, "in case your `ALTER` statement renames columns, gh-ost will note that and offer its interpretation of the rename. By default gh-ost does not proceed to execute. This flag approves that gh-ost's interpretation is correct")
-	flag.BoolVar(&migrationContext.SkipRenamedColumns, "skip-renamed-columns", false, "in case your `ALTER` statement renames columns, gh-ost will note that and offer its interpretation of the rename. By default gh-ost does not proceed to execute. This flag tells gh-ost to skip the renamed columns, i.e. to treat what gh-ost thinks are renamed columns as unrelated columns. NOTE: you may lose column data")
-	flag.BoolVar(&migrationContext.IsTungsten, "tungsten", false, "explicitly let gh-ost know that you are running on a tungsten-replication based topology (you are likely to also provide --assume-master-host)")
-	flag.BoolVar(&migrationContext.DiscardForeignKeys, "discard-foreign-keys", false, "DANGER! This flag will migrate a table that has foreign keys and will NOT create foreign keys on the ghost table, thus your altered table will have NO foreign keys. This is useful for intentional dropping of foreign keys")
-	flag.BoolVar(&migrationContext.SkipForeignKeyChecks, "skip-foreign-key-checks", false, "set to 'true' when you know for certain there are no foreign keys on your table, and wish to skip the time it takes for gh-ost to verify that")
-	flag.BoolVar(&migrationContext.SkipStrictMode, "skip-strict-mode", false, "explicitly tell gh-ost binlog applier not to enforce strict sql mode")
-	flag.BoolVar(&migrationContext.AllowZeroInDate, "allow-zero-in-date", false, "explicitly tell gh-ost binlog applier to ignore NO_ZERO_IN_DATE,NO_ZERO_DATE in sql_mode")
-	flag.BoolVar(&migrationContext.AliyunRDS, "aliyun-rds", false, "set to 'true' when you execute on Aliyun RDS.")
-	flag.BoolVar(&migrationContext.GoogleCloudPlatform, "gcp", false, "set to 'true' when you execute on a 1st generation Google Cloud Platform (GCP).")
-	flag.BoolVar(&migrationContext.AzureMySQL, "azure", false, "set to 'true' when you execute on Azure Database on MySQL.")
-	flag.BoolVar(&migrationContext.UseGTIDs, "gtid", false, "(experimental) set to 'true' to use MySQL GTIDs for binlog positioning.")
-
-	executeFlag := flag.Bool("execute", false, "actually execute the alter & migrate the table. Default is noop: do some tests and exit")
-	flag.BoolVar(&migrationContext.TestOnReplica, "test-on-replica", false, "Have the migration run on a replica, not on the master. At the end of migration replication is stopped, and tables are swapped and immediately swap-revert. Replication remains stopped and you can compare the two tables for building trust")
-	flag.BoolVar(&migrationContext.TestOnReplicaSkipReplicaStop, "test-on-replica-skip-replica-stop", false, "When --test-on-replica is enabled, do not issue commands stop replication (requires --test-on-replica)")
-	flag.BoolVar(&migrationContext.MigrateOnReplica, "migrate-on-replica", false, "Have the migration run on a replica, not on the master. This will do the full migration on the replica including cut-over (as opposed to --test-on-replica)")
-
-	flag.BoolVar(&migrationContext.OkToDropTable, "ok-to-drop-table", false, "Shall the tool drop the old table at end of operation. DROPping tables can be a long locking operation, which is why I'm not doing it by default. I'm an online tool, yes?")
-	flag.BoolVar(&migrationContext.InitiallyDropOldTable, "initially-drop-old-table", false, "Drop a possibly existing OLD table (remains from a previous run?) before beginning operation. Default is to panic and abort if such table exists")
-	flag.BoolVar(&migrationContext.InitiallyDropGhostTable, "initially-drop-ghost-table", false, "Drop a possibly existing Ghost table (remains from a previous run?) before beginning operation. Default is to panic and abort if such table exists")
-	flag.BoolVar(&migrationContext.TimestampOldTable, "timestamp-old-table", false, "Use a timestamp in old table name. This makes old table names unique and non conflicting cross migrations")
-	cutOver := flag.String("cut-over", "atomic", "choose cut-over type (default|atomic, two-step)")
-	flag.BoolVar(&migrationContext.ForceNamedCutOverCommand, "force-named-cut-over", false, "When true, the 'unpostpone|cut-over' interactive command must name the migrated table")
-	flag.BoolVar(&migrationContext.ForceNamedPanicCommand, "force-named-panic", false, "When true, the 'panic' interactive command must name the migrated table")
-
-	flag.BoolVar(&migrationContext.SwitchToRowBinlogFormat, "switch-to-rbr", false, "let this tool automatically switch binary log format to 'ROW' on the replica, if needed. The format will NOT be switched back. I'm too scared to do that, and wish to protect you if you happen to execute another migration while this one is running")
-	flag.BoolVar(&migrationContext.AssumeRBR, "assume-rbr", false, "set to 'true' when you know for certain your server uses 'ROW' binlog_format. gh-ost is unable to tell, event after reading binlog_format, whether the replication process does indeed use 'ROW', and restarts replication to be certain RBR setting is applied. Such operation requires SUPER privileges which you might not have. Setting this flag avoids restarting replication and you can proceed to use gh-ost without SUPER privileges")
-	flag.BoolVar(&migrationContext.CutOverExponentialBackoff, "cut-over-exponential-backoff", false, "Wait exponentially longer intervals between failed cut-over attempts. Wait intervals obey a maximum configurable with 'exponential-backoff-max-interval').")
-	exponentialBackoffMaxInterval := flag.Int64("exponential-backoff-max-interval", 64, "Maximum number of seconds to wait between attempts when performing various operations with exponential backoff.")
-	chunkSize := flag.Int64("chunk-size", 1000, "amount of rows to handle in each iteration (allowed range: 10-100,000)")
-	dmlBatchSize := flag.Int64("dml-batch-size", 10, "batch size for DML events to apply in a single transaction (range 1-1000)")
-	defaultRetries := flag.Int64("default-retries", 60, "Default number of retries for various operations before panicking")
-	flag.BoolVar(&migrationContext.PanicOnWarnings, "panic-on-warnings", false, "Panic when SQL warnings are encountered when copying a batch indicating data loss")
-	cutOverLockTimeoutSeconds := flag.Int64("cut-over-lock-timeout-seconds", 3, "Max number of seconds to hold locks on tables while attempting to cut-over (retry attempted when lock exceeds timeout) or attempting instant DDL")
-	niceRatio := flag.Float64("nice-ratio", 0, "force being 'nice', imply sleep time per chunk time; range: [0.0..100.0]. Example values: 0 is aggressive. 1: for every 1ms spent copying rows, sleep additional 1ms (effectively doubling runtime); 0.7: for every 10ms spend in a rowcopy chunk, spend 7ms sleeping immediately after")
-
-	maxLagMillis := flag.Int64("max-lag-millis", 1500, "replication lag at which to throttle operation")
-	replicationLagQuery := flag.String("replication-lag-query", "", "Deprecated. gh-ost uses an internal, subsecond resolution query")
-	throttleControlReplicas := flag.String("throttle-control-replicas", "", "List of replicas on which to check for lag; comma delimited. Example: myhost1.com:3306,myhost2.com,myhost3.com:3307")
-	throttleQuery := flag.String("throttle-query", "", "when given, issued (every second) to check if operation should throttle. Expecting to return zero for no-throttle, >0 for throttle. Query is issued on the migrated server. Make sure this query is lightweight")
-	throttleHTTP := flag.String("throttle-http", "", "when given, gh-ost checks given URL via HEAD request; any response code other than 200 (OK) causes throttling; make sure it has low latency response")
-	flag.Int64Var(&migrationContext.ThrottleHTTPIntervalMillis, "throttle-http-interval-millis", 100, "Number of milliseconds to wait before triggering another HTTP throttle check")
-	flag.Int64Var(&migrationContext.ThrottleHTTPTimeoutMillis, "throttle-http-timeout-millis", 1000, "Number of milliseconds to use as an HTTP throttle check timeout")
-	ignoreHTTPErrors := flag.Bool("ignore-http-errors", false, "ignore HTTP connection errors during throttle check")
-	heartbeatIntervalMillis := flag.Int64("heartbeat-interval-millis", 100, "how frequently would gh-ost inject a heartbeat value")
-	flag.StringVar(&migrationContext.ThrottleFlagFile, "throttle-flag-file", "", "operation pauses when this file exists; hint: use a file that is specific to the table being altered")
-	flag.StringVar(&migrationContext.ThrottleAdditionalFlagFile, "throttle-additional-flag-file", "/tmp/gh-ost.throttle", "operation pauses when this file exists; hint: keep default, use for throttling multiple gh-ost operations")
-	flag.StringVar(&migrationContext.PostponeCutOverFlagFile, "postpone-cut-over-flag-file", "", "while this file exists, migration will postpone the final stage of swapping tables, and will keep on syncing the ghost table. Cut-over/swapping would be ready to perform the moment the file is deleted.")
-	flag.StringVar(&migrationContext.PanicFlagFile, "panic-flag-file", "", "when this file is created, gh-ost will immediately terminate, without cleanup")
-
-	flag.BoolVar(&migrationContext.DropServeSocket, "initially-drop-socket-file", false, "Should gh-ost forcibly delete an existing socket file. Be careful: this might drop the socket file of a running migration!")
-	flag.StringVar(&migrationContext.ServeSocketFile, "serve-socket-file", "", "Unix socket file to serve on. Default: auto-determined and advertised upon startup")
-	flag.Int64Var(&migrationContext.ServeTCPPort, "serve-tcp-port", 0, "TCP port to serve on. Default: disabled")
-
-	flag.StringVar(&migrationContext.HooksPath, "hooks-path", "", "directory where hook files are found (default: empty, ie. hooks disabled). Hook files found on this path, and conforming to hook naming conventions will be executed")
-	flag.StringVar(&migrationContext.HooksHintMessage, "hooks-hint", "", "arbitrary message to be injected to hooks via GH_OST_HOOKS_HINT, for your convenience")
-	flag.StringVar(&migrationContext.HooksHintOwner, "hooks-hint-owner", "", "arbitrary name of owner to be injected to hooks via GH_OST_HOOKS_HINT_OWNER, for your convenience")
-	flag.StringVar(&migrationContext.HooksHintToken, "hooks-hint-token", "", "arbitrary token to be injected to hooks via GH_OST_HOOKS_HINT_TOKEN, for your convenience")
-	flag.Int64Var(&migrationContext.HooksStatusIntervalSec, "hooks-status-interval", 60, "how many seconds to wait between calling onStatus hook")
-
-	flag.UintVar(&migrationContext.ReplicaServerId, "replica-server-id", 99999, "server id used by gh-ost process. Default: 99999")
-	flag.BoolVar(&migrationContext.AllowSetupMetadataLockInstruments, "allow-setup-metadata-lock-instruments", false, "Validate rename session hold the MDL of original table before unlock tables in cut-over phase")
-	flag.BoolVar(&migrationContext.SkipMetadataLockCheck, "skip-metadata-lock-check", false, "Skip metadata lock check at cut-over time. The checks require performance_schema.metadata_lock to be enabled")
-	flag.IntVar(&migrationContext.BinlogSyncerMaxReconnectAttempts, "binlogsyncer-max-reconnect-attempts", 0, "when master node fails, the maximum number of binlog synchronization attempts to reconnect. 0 is unlimited")
-
-	flag.BoolVar(&migrationContext.IncludeTriggers, "include-triggers", false, "When true, the triggers (if exist) will be created on the new table")
-	flag.StringVar(&migrationContext.TriggerSuffix, "trigger-suffix", "", "Add a suffix to the trigger name (i.e '_v2'). Requires '--include-triggers'")
-	flag.BoolVar(&migrationContext.RemoveTriggerSuffix, "remove-trigger-suffix-if-exists", false, "Remove given suffix from name of trigger. Requires '--include-triggers' and '--trigger-suffix'")
-	flag.BoolVar(&migrationContext.SkipPortValidation, "skip-port-validation", false, "Skip port validation for MySQL connections")
-	flag.BoolVar(&migrationContext.Checkpoint, "checkpoint", false, "Enable migration checkpoints")
-	flag.Int64Var(&migrationContext.CheckpointIntervalSeconds, "checkpoint-seconds", 300, "The number of seconds between checkpoints")
-	flag.BoolVar(&migrationContext.Resume, "resume", false, "Attempt to resume migration from checkpoint")
-	flag.BoolVar(&migrationContext.Revert, "revert", false, "Attempt to revert completed migration")
-	flag.StringVar(&migrationContext.OldTableName, "old-table", "", "The name of the old table when using --revert, e.g. '~mytable_del'")
-
-	maxLoad := flag.String("max-load", "", "Comma delimited status-name=threshold. e.g: 'Threads_running=100,Threads_connected=500'. When status exceeds threshold, app throttles writes")
-	criticalLoad := flag.String("critical-load", "", "Comma delimited status-name=threshold, same format as --max-load. When status exceeds threshold, app panics and quits")
-	flag.Int64Var(&migrationContext.CriticalLoadIntervalMilliseconds, "critical-load-interval-millis", 0, "When 0, migration immediately bails out upon meeting critical-load. When non-zero, a second check is done after given interval, and migration only bails out if 2nd check still meets critical load")
-	flag.Int64Var(&migrationContext.CriticalLoadHibernateSeconds, "critical-load-hibernate-seconds", 0, "When non-zero, critical-load does not panic and bail out; instead, gh-ost goes into hibernation for the specified duration. It will not read/write anything from/to any server")
+	flag.StringVar(&options.CredentialsFile, "credentials-file", "", "File holding the MySQL password, or user=... and password=... lines; re-read whenever modified, for credentials that rotate during the migration. Consulted on each new connection")
+	flag.StringVar(&options.CredentialsCommand, "credentials-command", "", "Shell command printing the MySQL password, or user=... and password=... lines (e.g. a Vault or cloud IAM token client); consulted on each new connection, for credentials that rotate during the migration")
+	flag.Int64Var(&options.CredentialsCacheSeconds, "credentials-cache-seconds", 60, "How long credentials printed by --credentials-command are reused before running the command again")
+	flag.StringVar(&options.Charset, "charset", "utf8mb4,utf8,latin1", "The default charset for the database connection is utf8mb4, utf8, latin1.")
+
+	flag.BoolVar(&options.UseTLS, "ssl", false, "Enable SSL encrypted connections to MySQL hosts")
+	flag.StringVar(&options.TLSCACertificate, "ssl-ca", "", "CA certificate in PEM format for TLS connections to MySQL hosts. Requires --ssl")
+	flag.StringVar(&options.TLSCertificate, "ssl-cert", "", "Certificate in PEM format for TLS connections to MySQL hosts. Requires --ssl")
+	flag.StringVar(&options.TLSKey, "ssl-key", "", "Key in PEM format for TLS connections to MySQL hosts. Requires --ssl")
+	flag.BoolVar(&options.TLSAllowInsecure, "ssl-allow-insecure", false, "Skips verification of MySQL hosts' certificate chain and host name. Requires --ssl")
+
+	flag.StringVar(&options.DatabaseName, "database", "", "database name (mandatory)")
+	flag.StringVar(&options.TableName, "table", "", "table name (mandatory)")
+	flag.StringVar(&options.AlterStatement, "alter", "", "alter statement (mandatory)")
+	flag.BoolVar(&options.AttemptInstantDDL, "attempt-instant-ddl", false, "Attempt to use instant DDL for this migration first")
+	flag.BoolVar(&options.AttemptInplaceDDL, "attempt-inplace-ddl", false, "When the alter is predicted to only change table metadata, attempt ALGORITHM=INPLACE, LOCK=NONE for this migration before copying the table")
+	flag.StringVar(&options.StorageEngine, "storage-engine", "innodb", "Specify table storage engine (default: 'innodb'). When 'rocksdb': the session transaction isolation level is changed from REPEATABLE_READ to READ_COMMITTED.")
+
+	flag.BoolVar(&options.CountTableRows, "exact-rowcount", false, "actually count table rows as opposed to estimate them (results in more accurate progress estimation)")
+	flag.BoolVar(&options.ConcurrentCountTableRows, "concurrent-rowcount", true, "(with --exact-rowcount), when true (default): count rows after row-copy begins, concurrently, and adjust row estimate later on; when false: first count rows, then start row copy")
+	flag.BoolVar(&options.CopyFromReplica, "copy-from-replica", false, "read row-copy chunks on the inspected replica and write them onto the master via batched INSERT IGNORE, rather than INSERT ... SELECT on the master. Reduces read load on the master. Requires briefly read-locking the table on the replica per chunk")
+	flag.BoolVar(&options.AllowedRunningOnMaster, "allow-on-master", false, "allow this migration to run directly on master. Preferably it would run on a replica")
+	flag.BoolVar(&options.AllowedMasterMaster, "allow-master-master", false, "explicitly allow running in a master-master setup")
+	flag.BoolVar(&options.NullableUniqueKeyAllowed, "allow-nullable-unique-key", false, "allow gh-ost to migrate based on a unique key with nullable columns. As long as no NULL values exist, this should be OK. If NULL values exist in chosen key, data may be corrupted. Use at your own risk!")
+	flag.StringVar(&options.MigrationKey, "migration-key", "", "name of the unique key by which to iterate and apply the migration, e.g. when the ALTER changes the PRIMARY KEY. Must be unique and non-nullable on both the original and the ghost table. Default: chosen automatically")
+	flag.BoolVar(&options.NonUniqueKeyAllowed, "allow-non-unique-key", false, "allow gh-ost to migrate a table that has no PRIMARY nor UNIQUE key, iterating a non-unique key and matching binlog events by full row image. Requires binlog_row_image=FULL. See doc/requirements-and-limitations.md for caveats")
+	flag.BoolVar(&options.ApproveRenamedColumns, "approve-renamed-columns", false, "in case your `ALTER` statement renames columns, gh-ost will note that and offer its interpretation of the rename. By default gh-ost does not proceed to execute. This flag approves that gh-ost's interpretation is correct")
+	flag.BoolVar(&options.SkipRenamedColumns, "skip-renamed-columns", false, "in case your `ALTER` statement renames columns, gh-ost will note that and offer its interpretation of the rename. By default gh-ost does not proceed to execute. This flag tells gh-ost to skip the renamed columns, i.e. to treat what gh-ost thinks are renamed columns as unrelated columns. NOTE: you may lose column data")
+	flag.BoolVar(&options.IsTungsten, "tungsten", false, "explicitly let gh-ost know that you are running on a tungsten-replication based topology (you are likely to also provide --assume-master-host)")
+	flag.BoolVar(&options.DiscardForeignKeys, "discard-foreign-keys", false, "DANGER! This flag will migrate a table that has foreign keys and will NOT create foreign keys on the ghost table, thus your altered table will have NO foreign keys. This is useful for intentional dropping of foreign keys")
+	flag.BoolVar(&options.SkipForeignKeyChecks, "skip-foreign-key-checks", false, "set to 'true' when you know for certain there are no foreign keys on your table, and wish to skip the time it takes for gh-ost to verify that")
+	flag.BoolVar(&options.SkipStrictMode, "skip-strict-mode", false, "explicitly tell gh-ost binlog applier not to enforce strict sql mode")
+	flag.BoolVar(&options.AllowZeroInDate, "allow-zero-in-date", false, "explicitly tell gh-ost binlog applier to ignore NO_ZERO_IN_DATE,NO_ZERO_DATE in sql_mode")
+	flag.BoolVar(&options.AliyunRDS, "aliyun-rds", false, "set to 'true' when you execute on Aliyun RDS.")
+	flag.BoolVar(&options.GoogleCloudPlatform, "gcp", false, "set to 'true' when you execute on a 1st generation Google Cloud Platform (GCP).")
+	flag.BoolVar(&options.AzureMySQL, "azure", false, "set to 'true' when you execute on Azure Database on MySQL.")
+	flag.StringVar(&options.ActiveActivePeer, "active-active-peer", "", "(optional) the other master of an active-active master-master setup, where the table is written to on both masters. Format: some.host.com[:port]. gh-ost then runs on one master (requires --allow-on-master, --gtid and log_slave_updates), and sets the peer read_only at cut-over")
+	flag.BoolVar(&options.UseGTIDs, "gtid", false, "(experimental) set to 'true' to use MySQL GTIDs for binlog positioning.")
+
+	flag.BoolVar(&options.Execute, "execute", false, "actually execute the alter & migrate the table. Default is noop: do some tests and exit")
+	flag.BoolVar(&options.TestOnReplica, "test-on-replica", false, "Have the migration run on a replica, not on the master. At the end of migration replication is stopped, and tables are swapped and immediately swap-revert. Replication remains stopped and you can compare the two tables for building trust")
+	flag.BoolVar(&options.TestOnReplicaSkipReplicaStop, "test-on-replica-skip-replica-stop", false, "When --test-on-replica is enabled, do not issue commands stop replication (requires --test-on-replica)")
+	flag.BoolVar(&options.MigrateOnReplica, "migrate-on-replica", false, "Have the migration run on a replica, not on the master. This will do the full migration on the replica including cut-over (as opposed to --test-on-replica)")
+
+	flag.BoolVar(&options.OkToDropTable, "ok-to-drop-table", false, "Shall the tool drop the old table at end of operation. DROPping tables can be a long locking operation, which is why I'm not doing it by default. I'm an online tool, yes?")
+	flag.BoolVar(&options.InitiallyDropOldTable, "initially-drop-old-table", false, "Drop a possibly existing OLD table (remains from a previous run?) before beginning operation. Default is to panic and abort if such table exists")
+	flag.BoolVar(&options.InitiallyDropGhostTable, "initially-drop-ghost-table", false, "Drop a possibly existing Ghost table (remains from a previous run?) before beginning operation. Default is to panic and abort if such table exists")
+	flag.BoolVar(&options.TimestampOldTable, "timestamp-old-table", false, "Use a timestamp in old table name. This makes old table names unique and non conflicting cross migrations")
+	flag.StringVar(&options.CutOver, "cut-over", "atomic", "choose cut-over type (default|atomic, two-step)")
+	flag.BoolVar(&options.ForceNamedCutOverCommand, "force-named-cut-over", false, "When true, the 'unpostpone|cut-over' interactive command must name the migrated table")
+	flag.BoolVar(&options.ForceNamedPanicCommand, "force-named-panic", false, "When true, the 'panic' interactive command must name the migrated table")
+
+	flag.BoolVar(&options.SwitchToRowBinlogFormat, "switch-to-rbr", false, "let this tool automatically switch binary log format to 'ROW' on the replica, if needed. The format will NOT be switched back. I'm too scared to do that, and wish to protect you if you happen to execute another migration while this one is running")
+	flag.BoolVar(&options.AssumeRBR, "assume-rbr", false, "set to 'true' when you know for certain your server uses 'ROW' binlog_format. gh-ost is unable to tell, event after reading binlog_format, whether the replication process does indeed use 'ROW', and restarts replication to be certain RBR setting is applied. Such operation requires SUPER privileges which you might not have. Setting this flag avoids restarting replication and you can proceed to use gh-ost without SUPER privileges")
+	flag.BoolVar(&options.CutOverExponentialBackoff, "cut-over-exponential-backoff", false, "Wait exponentially longer intervals between failed cut-over attempts. Wait intervals obey a maximum configurable with 'exponential-backoff-max-interval').")
+	flag.Int64Var(&options.ExponentialBackoffMaxInterval, "exponential-backoff-max-interval", 64, "Maximum number of seconds to wait between attempts when performing various operations with exponential backoff.")
+	flag.Int64Var(&options.ChunkSize, "chunk-size", 1000, "amount of rows to handle in each iteration (allowed range: 10-100,000)")
+	flag.Int64Var(&options.DMLBatchSize, "dml-batch-size", 10, "batch size for DML events to apply in a single transaction (range 1-1000)")
+	flag.Int64Var(&options.MaxDMLBatchSize, "max-dml-batch-size", 0, "When above --dml-batch-size, DML batches are sized dynamically between --dml-batch-size and this value (up to 1000), based on the apply backlog and transaction latency. 0 disables")
+	flag.Int64Var(&options.MaxDMLBatchLatencyMillis, "max-dml-batch-latency-millis", 100, "With --max-dml-batch-size, DML batches shrink when applying a batch takes longer than this many milliseconds")
+	flag.BoolVar(&options.CoalesceDMLEvents, "coalesce-dml-events", false, "Collapse the DML events of a batch which apply to the same row into a single write onto the ghost table. Reduces writes on hot rows. Disabled when the ghost table has unique keys other than the migration key")
+	flag.Int64Var(&options.MaxDMLStatementSize, "max-dml-statement-size", 1024*1024, "Estimated size (bytes) up to which consecutive inserts, or consecutive deletes, of a DML batch are merged into a single multi-row statement. 0 disables merging")
+	flag.Int64Var(&options.DefaultRetries, "default-retries", 60, "Default number of retries for various operations before panicking")
+	flag.BoolVar(&options.PanicOnWarnings, "panic-on-warnings", false, "Panic when SQL warnings are encountered when copying a batch indicating data loss")
+	flag.Int64Var(&options.CutOverLockTimeoutSeconds, "cut-over-lock-timeout-seconds", 3, "Max number of seconds to hold locks on tables while attempting to cut-over (retry attempted when lock exceeds timeout) or attempting instant DDL")
+	flag.Float64Var(&options.NiceRatio, "nice-ratio", 0, "force being 'nice', imply sleep time per chunk time; range: [0.0..100.0]. Example values: 0 is aggressive. 1: for every 1ms spent copying rows, sleep additional 1ms (effectively doubling runtime); 0.7: for every 10ms spend in a rowcopy chunk, spend 7ms sleeping immediately after")
+
+	flag.Int64Var(&options.MaxLagMillis, "max-lag-millis", 1500, "replication lag at which to throttle operation")
+	flag.Int64Var(&options.MaxBinlogBacklog, "max-binlog-backlog", 0, "Binlog backlog (bytes; or transactions with --gtid) of applied events behind the binlog head, beyond which row copy pauses so that DML events catch up. 0 disables")
+	flag.Int64Var(&options.BinlogRetentionMarginSeconds, "binlog-retention-margin-seconds", 3600, "Warn when the binary logs holding the applied events or the last checkpoint are due to be purged (per binlog_expire_logs_seconds) within this many seconds, or are purged. 0 disables")
+	flag.BoolVar(&options.BinlogRetentionPauseRowCopy, "binlog-retention-pause-row-copy", false, "Pause row copy while the binary logs holding the applied events are due to be purged within --binlog-retention-margin-seconds, so that DML events catch up")
+	flag.StringVar(&options.ReplicationLagQuery, "replication-lag-query", "", "Deprecated. gh-ost uses an internal, subsecond resolution query")
+	flag.StringVar(&options.ThrottleControlReplicas, "throttle-control-replicas", "", "List of replicas on which to check for lag; comma delimited. Example: myhost1.com:3306,myhost2.com,myhost3.com:3307")
+	flag.StringVar(&options.ThrottleQuery, "throttle-query", "", "when given, issued (every second) to check if operation should throttle. Expecting to return zero for no-throttle, >0 for throttle. Query is issued on the migrated server. Make sure this query is lightweight")
+	flag.StringVar(&options.ThrottleHTTP, "throttle-http", "", "when given, gh-ost checks given URL via HEAD request; any response code other than 200 (OK) causes throttling; make sure it has low latency response")
+	flag.Int64Var(&options.ThrottleHTTPIntervalMillis, "throttle-http-interval-millis", 100, "Number of milliseconds to wait before triggering another HTTP throttle check")
+	flag.Int64Var(&options.ThrottleHTTPTimeoutMillis, "throttle-http-timeout-millis", 1000, "Number of milliseconds to use as an HTTP throttle check timeout")
+	flag.BoolVar(&options.IgnoreHTTPErrors, "ignore-http-errors", false, "ignore HTTP connection errors during throttle check")
+	flag.Int64Var(&options.HeartbeatIntervalMillis, "heartbeat-interval-millis", 100, "how frequently would gh-ost inject a heartbeat value")
+	flag.StringVar(&options.ThrottleFlagFile, "throttle-flag-file", "", "operation pauses when this file exists; hint: use a file that is specific to the table being altered")
+	flag.StringVar(&options.ThrottleAdditionalFlagFile, "throttle-additional-flag-file", "/tmp/gh-ost.throttle", "operation pauses when this file exists; hint: keep default, use for throttling multiple gh-ost operations")
+	flag.StringVar(&options.PostponeCutOverFlagFile, "postpone-cut-over-flag-file", "", "while this file exists, migration will postpone the final stage of swapping tables, and will keep on syncing the ghost table. Cut-over/swapping would be ready to perform the moment the file is deleted.")
+	flag.StringVar(&options.PanicFlagFile, "panic-flag-file", "", "when this file is created, gh-ost will immediately terminate, without cleanup")
+
+	flag.BoolVar(&options.DropServeSocket, "initially-drop-socket-file", false, "Should gh-ost forcibly delete an existing socket file. Be careful: this might drop the socket file of a running migration!")
+	flag.StringVar(&options.ServeSocketFile, "serve-socket-file", "", "Unix socket file to serve on. Default: auto-determined and advertised upon startup")
+	flag.Int64Var(&options.ServeTCPPort, "serve-tcp-port", 0, "TCP port to serve on. Default: disabled")
+
+	flag.StringVar(&options.HooksPath, "hooks-path", "", "directory where hook files are found (default: empty, ie. hooks disabled). Hook files found on this path, and conforming to hook naming conventions will be executed")
+	flag.StringVar(&options.HooksHintMessage, "hooks-hint", "", "arbitrary message to be injected to hooks via GH_OST_HOOKS_HINT, for your convenience")
+	flag.StringVar(&options.HooksHintOwner, "hooks-hint-owner", "", "arbitrary name of owner to be injected to hooks via GH_OST_HOOKS_HINT_OWNER, for your convenience")
+	flag.StringVar(&options.HooksHintToken, "hooks-hint-token", "", "arbitrary token to be injected to hooks via GH_OST_HOOKS_HINT_TOKEN, for your convenience")
+	flag.Int64Var(&options.HooksStatusIntervalSec, "hooks-status-interval", 60, "how many seconds to wait between calling onStatus hook")
+
+	flag.UintVar(&options.ReplicaServerId, "replica-server-id", 99999, "server id used by gh-ost process. Default: 99999")
+	flag.BoolVar(&options.AllowSetupMetadataLockInstruments, "allow-setup-metadata-lock-instruments", false, "Validate rename session hold the MDL of original table before unlock tables in cut-over phase")
+	flag.BoolVar(&options.SkipMetadataLockCheck, "skip-metadata-lock-check", false, "Skip metadata lock check at cut-over time. The checks require performance_schema.metadata_lock to be enabled")
+	flag.IntVar(&options.BinlogSyncerMaxReconnectAttempts, "binlogsyncer-max-reconnect-attempts", 0, "when master node fails, the maximum number of binlog synchronization attempts to reconnect. 0 is unlimited")
+	flag.BoolVar(&options.StreamerFailover, "streamer-failover", false, "When the binlog streamer cannot reconnect (see --binlogsyncer-max-reconnect-attempts), re-validate the master and resume streaming at the applied GTID coordinates off another server: one given by the gh-ost-on-streamer-failover hook, a replica of the master, or the master itself. Requires --gtid")
+
+	flag.BoolVar(&options.IncludeTriggers, "include-triggers", false, "When true, the triggers (if exist) will be created on the new table")
+	flag.StringVar(&options.TriggerSuffix, "trigger-suffix", "", "Add a suffix to the trigger name (i.e '_v2'). Requires '--include-triggers'")
+	flag.BoolVar(&options.RemoveTriggerSuffix, "remove-trigger-suffix-if-exists", false, "Remove given suffix from name of trigger. Requires '--include-triggers' and '--trigger-suffix'")
+	flag.BoolVar(&options.SkipPortValidation, "skip-port-validation", false, "Skip port validation for MySQL connections")
+	flag.BoolVar(&options.Checkpoint, "checkpoint", false, "Enable migration checkpoints")
+	flag.Int64Var(&options.CheckpointIntervalSeconds, "checkpoint-seconds", 300, "The number of seconds between checkpoints")
+	flag.BoolVar(&options.Resume, "resume", false, "Attempt to resume migration from checkpoint")
+	flag.BoolVar(&options.Revert, "revert", false, "Attempt to revert completed migration")
+	flag.StringVar(&options.OldTableName, "old-table", "", "The name of the old table when using --revert, e.g. '~mytable_del'")
+
+	flag.StringVar(&options.MaxLoad, "max-load", "", "Comma delimited status-name=threshold. e.g: 'Threads_running=100,Threads_connected=500'. When status exceeds threshold, app throttles writes")
+	flag.StringVar(&options.CriticalLoad, "critical-load", "", "Comma delimited status-name=threshold, same format as --max-load. When status exceeds threshold, app panics and quits")
+	flag.Int64Var(&options.CriticalLoadIntervalMilliseconds, "critical-load-interval-millis", 0, "When 0, migration immediately bails out upon meeting critical-load. When non-zero, a second check is done after given interval, and migration only bails out if 2nd check still meets critical load")
+	flag.Int64Var(&options.CriticalLoadHibernateSeconds, "critical-load-hibernate-seconds", 0, "When non-zero, critical-load does not panic and bail out; instead, gh-ost goes into hibernation for the specified duration. It will not read/write anything from/to any server")
 	quiet := flag.Bool("quiet", false, "quiet")
 	verbose := flag.Bool("verbose", false, "verbose")
 	debug := flag.Bool("debug", false, "debug mode (very verbose)")
@@ -163,7 +179,7 @@ func main() {
 	help := flag.Bool("help", false, "Display usage")
 	version := flag.Bool("version", false, "Print version & exit")
 	checkFlag := flag.Bool("check-flag", false, "Check if another flag exists/supported. This allows for cross-version scripting. Exits with 0 when all additional provided flags exist, nonzero otherwise. You must provide (dummy) values for flags that require a value. Example: gh-ost --check-flag --cut-over-lock-timeout-seconds --nice-ratio 0")
-	flag.StringVar(&migrationContext.ForceTmpTableName, "force-table-names", "", "table name prefix to be used on the temporary tables")
+	flag.StringVar(&options.ForceTmpTableName, "force-table-names", "", "table name prefix to be used on the temporary tables")
 	flag.CommandLine.SetOutput(os.Stdout)
 
 	flag.Parse()
@@ -187,204 +203,73 @@ func main() {
 		fmt.Printf("%s (git commit: %s)\n", AppVersion, GitCommit)
 		return
 	}
+	logger := base.NewDefaultLogger()
+	var configFileWarnings []string
+	if options.ConfigFile != "" {
+		settings, err := base.ReadConfigFileSettings(options.ConfigFile)
+		if err != nil {
+			logger.Fatale(err)
+		}
+		if configFileWarnings, err = base.ApplyConfigFileSettings(flag.CommandLine, settings); err != nil {
+			logger.Fatale(err)
+		}
+	}
 
-	migrationContext.Log.SetLevel(log.ERROR)
+	logger.SetLevel(log.ERROR)
 	if *verbose {
-		migrationContext.Log.SetLevel(log.INFO)
+		logger.SetLevel(log.INFO)
 	}
 	if *debug {
-		migrationContext.Log.SetLevel(log.DEBUG)
+		logger.SetLevel(log.DEBUG)
 	}
 	if *stack {
-		migrationContext.Log.SetPrintStackTrace(*stack)
+		logger.SetPrintStackTrace(*stack)
 	}
 	if *quiet {
 		// Override!!
-		migrationContext.Log.SetLevel(log.ERROR)
+		logger.SetLevel(log.ERROR)
 	}
-
-	if err := migrationContext.SetConnectionConfig(*storageEngine); err != nil {
-		migrationContext.Log.Fatale(err)
-	}
-
-	migrationContext.SetConnectionCharset(*charset)
-
-	if migrationContext.AlterStatement == "" && !migrationContext.Revert {
-		log.Fatal("--alter must be provided and statement must not be empty")
-	}
-	parser := sql.NewParserFromAlterStatement(migrationContext.AlterStatement)
-	migrationContext.AlterStatementOptions = parser.GetAlterStatementOptions()
-
-	if migrationContext.Revert {
-		if migrationContext.Resume {
-			log.Fatal("--revert cannot be used with --resume")
-		}
-		if migrationContext.OldTableName == "" {
-			migrationContext.Log.Fatalf("--revert must be called with --old-table")
-		}
-
-		// options irrelevant to revert mode
-		if migrationContext.AlterStatement != "" {
-			log.Warning("--alter was provided with --revert, it will be ignored")
-		}
-		if migrationContext.AttemptInstantDDL {
-			log.Warning("--attempt-instant-ddl was provided with --revert, it will be ignored")
-		}
-		if migrationContext.IncludeTriggers {
-			log.Warning("--include-triggers was provided with --revert, it will be ignored")
-		}
-		if migrationContext.DiscardForeignKeys {
-			log.Warning("--discard-foreign-keys was provided with --revert, it will be ignored")
-		}
-	}
-
-	if migrationContext.DatabaseName == "" {
-		if parser.HasExplicitSchema() {
-			migrationContext.DatabaseName = parser.GetExplicitSchema()
-		} else {
-			log.Fatal("--database must be provided and database name must not be empty, or --alter must specify database name")
-		}
-	}
-
-	if err := flag.Set("database", url.QueryEscape(migrationContext.DatabaseName)); err != nil {
-		migrationContext.Log.Fatale(err)
-	}
-
-	if migrationContext.OriginalTableName == "" {
-		if parser.HasExplicitTable() {
-			migrationContext.OriginalTableName = parser.GetExplicitTable()
-		} else {
-			log.Fatal("--table must be provided and table name must not be empty, or --alter must specify table name")
-		}
+	for _, warning := range configFileWarnings {
+		logger.Warningf("%s", warning)
 	}
-	migrationContext.Noop = !(*executeFlag)
-	if migrationContext.AllowedRunningOnMaster && migrationContext.TestOnReplica {
-		migrationContext.Log.Fatal("--allow-on-master and --test-on-replica are mutually exclusive")
-	}
-	if migrationContext.AllowedRunningOnMaster && migrationContext.MigrateOnReplica {
-		migrationContext.Log.Fatal("--allow-on-master and --migrate-on-replica are mutually exclusive")
-	}
-	if migrationContext.MigrateOnReplica && migrationContext.TestOnReplica {
-		migrationContext.Log.Fatal("--migrate-on-replica and --test-on-replica are mutually exclusive")
-	}
-	if migrationContext.SwitchToRowBinlogFormat && migrationContext.AssumeRBR {
-		migrationContext.Log.Fatal("--switch-to-rbr and --assume-rbr are mutually exclusive")
-	}
-	if migrationContext.TestOnReplicaSkipReplicaStop {
-		if !migrationContext.TestOnReplica {
-			migrationContext.Log.Fatal("--test-on-replica-skip-replica-stop requires --test-on-replica to be enabled")
-		}
-		migrationContext.Log.Warning("--test-on-replica-skip-replica-stop enabled. We will not stop replication before cut-over. Ensure you have a plugin that does this.")
-	}
-	if migrationContext.CliMasterUser != "" && migrationContext.AssumeMasterHostname == "" {
-		migrationContext.Log.Fatal("--master-user requires --assume-master-host")
-	}
-	if migrationContext.CliMasterPassword != "" && migrationContext.AssumeMasterHostname == "" {
-		migrationContext.Log.Fatal("--master-password requires --assume-master-host")
-	}
-	if migrationContext.TLSCACertificate != "" && !migrationContext.UseTLS {
-		migrationContext.Log.Fatal("--ssl-ca requires --ssl")
-	}
-	if migrationContext.TLSCertificate != "" && !migrationContext.UseTLS {
-		migrationContext.Log.Fatal("--ssl-cert requires --ssl")
-	}
-	if migrationContext.TLSKey != "" && !migrationContext.UseTLS {
-		migrationContext.Log.Fatal("--ssl-key requires --ssl")
-	}
-	if migrationContext.TLSAllowInsecure && !migrationContext.UseTLS {
-		migrationContext.Log.Fatal("--ssl-allow-insecure requires --ssl")
-	}
-	if *replicationLagQuery != "" {
-		migrationContext.Log.Warningf("--replication-lag-query is deprecated")
-	}
-	if migrationContext.IncludeTriggers && migrationContext.TriggerSuffix == "" {
-		migrationContext.Log.Fatalf("--trigger-suffix must be used with --include-triggers")
-	}
-	if !migrationContext.IncludeTriggers && migrationContext.TriggerSuffix != "" {
-		migrationContext.Log.Fatalf("--trigger-suffix cannot be be used without --include-triggers")
-	}
-	if migrationContext.TriggerSuffix != "" {
-		regex := regexp.MustCompile(`^[\da-zA-Z_]+$`)
-
-		if !regex.Match([]byte(migrationContext.TriggerSuffix)) {
-			migrationContext.Log.Fatalf("--trigger-suffix must contain only alpha numeric characters and underscore (0-9,a-z,A-Z,_)")
+	if *printConfig {
+		// Printed before the options are validated, so that incomplete or conflicting configs show too
+		effectiveConfig, err := base.EffectiveConfig(flag.CommandLine)
+		if err != nil {
+			logger.Fatale(err)
 		}
-	}
-	if *storageEngine == "rocksdb" {
-		migrationContext.Log.Warning("RocksDB storage engine support is experimental")
-	}
-	if migrationContext.CheckpointIntervalSeconds < 10 {
-		migrationContext.Log.Fatalf("--checkpoint-seconds should be >=10")
-	}
-	if migrationContext.CountTableRows && migrationContext.PanicOnWarnings {
-		migrationContext.Log.Warning("--exact-rowcount with --panic-on-warnings: row counts cannot be exact due to warning detection")
+		fmt.Fprint(os.Stdout, effectiveConfig)
+		return
 	}
 
-	switch *cutOver {
-	case "atomic", "default", "":
-		migrationContext.CutOverType = base.CutOverAtomic
-	case "two-step":
-		migrationContext.CutOverType = base.CutOverTwoStep
-	default:
-		migrationContext.Log.Fatalf("Unknown cut-over: %s", *cutOver)
-	}
-	if err := migrationContext.ReadConfigFile(); err != nil {
-		migrationContext.Log.Fatale(err)
-	}
-	if err := migrationContext.ReadThrottleControlReplicaKeys(*throttleControlReplicas); err != nil {
-		migrationContext.Log.Fatale(err)
-	}
-	if err := migrationContext.ReadMaxLoad(*maxLoad); err != nil {
-		migrationContext.Log.Fatale(err)
-	}
-	if err := migrationContext.ReadCriticalLoad(*criticalLoad); err != nil {
-		migrationContext.Log.Fatale(err)
-	}
-	if migrationContext.ServeSocketFile == "" {
-		migrationContext.ServeSocketFile = fmt.Sprintf("/tmp/gh-ost.%s.%s.sock", migrationContext.DatabaseName, migrationContext.OriginalTableName)
+	if (options.CredentialsFile != "" || options.CredentialsCommand != "") && *askPass {
+		logger.Fatal("--credentials-file and --credentials-command cannot be used with --password or --ask-pass")
 	}
 	if *askPass {
 		fmt.Println("Password:")
 		bytePassword, err := term.ReadPassword(syscall.Stdin)
 		if err != nil {
-			migrationContext.Log.Fatale(err)
+			logger.Fatale(err)
 		}
-		migrationContext.CliPassword = string(bytePassword)
+		options.Password = string(bytePassword)
 	}
+	options.AppVersion = AppVersion
+	options.Logger = logger
 
-	migrationContext.SetHeartbeatIntervalMilliseconds(*heartbeatIntervalMillis)
-	migrationContext.SetNiceRatio(*niceRatio)
-	migrationContext.SetChunkSize(*chunkSize)
-	migrationContext.SetDMLBatchSize(*dmlBatchSize)
-	migrationContext.SetMaxLagMillisecondsThrottleThreshold(*maxLagMillis)
-	migrationContext.SetThrottleQuery(*throttleQuery)
-	migrationContext.SetThrottleHTTP(*throttleHTTP)
-	migrationContext.SetIgnoreHTTPErrors(*ignoreHTTPErrors)
-	migrationContext.SetDefaultNumRetries(*defaultRetries)
-	migrationContext.ApplyCredentials()
-	if err := migrationContext.SetupTLS(); err != nil {
-		migrationContext.Log.Fatale(err)
-	}
-	if err := migrationContext.SetCutOverLockTimeoutSeconds(*cutOverLockTimeoutSeconds); err != nil {
-		migrationContext.Log.Errore(err)
-	}
-	if err := migrationContext.SetExponentialBackoffMaxInterval(*exponentialBackoffMaxInterval); err != nil {
-		migrationContext.Log.Errore(err)
+	migration, err := ghost.NewMigration(options)
+	if err != nil {
+		logger.Fatale(err)
 	}
+	migrationContext := migration.Context()
 
 	log.Infof("starting gh-ost %+v (git commit: %s)", AppVersion, GitCommit)
 	acceptSignals(migrationContext)
 
-	migrator := logic.NewMigrator(migrationContext, AppVersion)
-	var err error
-	if migrationContext.Revert {
-		err = migrator.Revert()
-	} else {
-		err = migrator.Migrate()
-	}
-
-	if err != nil {
-		migrator.ExecOnFailureHook()
+	if err := migration.Run(); err != nil {
+		if errors.Is(err, ghost.ErrDetached) {
+			fmt.Fprintln(os.Stdout, "# Detached")
+			return
+		}
 		migrationContext.Log.Fatale(err)
 	}
 	fmt.Fprintln(os.Stdout, "# Done")
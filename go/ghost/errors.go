@@ -0,0 +1,55 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package ghost
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/github/gh-ost/go/logic"
+)
+
+var (
+	// ErrMissingOption marks a mandatory option that is not provided
+	ErrMissingOption = errors.New("missing option")
+	// ErrConflictingOptions marks options which cannot be used together
+	ErrConflictingOptions = errors.New("conflicting options")
+	// ErrInvalidOption marks an option with an invalid value
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrNotPostponingCutOver is returned when commanding cut-over while it is not postponed
+	ErrNotPostponingCutOver = errors.New("cut-over is not being postponed")
+	// ErrDetached is returned by Run when the migration detaches, to be re-attached with --resume
+	ErrDetached = logic.ErrMigrationDetached
+)
+
+// OptionError is returned by NewMigration when options fail validation. Option is the name of the
+// offending command line flag; use errors.Is with ErrMissingOption, ErrConflictingOptions or
+// ErrInvalidOption to tell the kind of failure.
+type OptionError struct {
+	Option  string
+	Kind    error
+	Message string
+}
+
+func (this *OptionError) Error() string {
+	return this.Message
+}
+
+func (this *OptionError) Unwrap() error {
+	return this.Kind
+}
+
+func missingOption(option string, format string, args ...interface{}) error {
+	return &OptionError{Option: option, Kind: ErrMissingOption, Message: fmt.Sprintf(format, args...)}
+}
+
+func conflictingOptions(option string, format string, args ...interface{}) error {
+	return &OptionError{Option: option, Kind: ErrConflictingOptions, Message: fmt.Sprintf(format, args...)}
+}
+
+func invalidOption(option string, err error) error {
+	return &OptionError{Option: option, Kind: ErrInvalidOption, Message: err.Error()}
+}
@@ -0,0 +1,551 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package ghost
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/github/gh-ost/go/base"
+	"github.com/github/gh-ost/go/logic"
+	"github.com/github/gh-ost/go/sql"
+)
+
+// EventsBufferSize is the capacity of the events channel. Events are dropped rather than
+// block the migration when the channel is full.
+const EventsBufferSize = 100
+
+var triggerSuffixRegexp = regexp.MustCompile(`^[\da-zA-Z_]+$`)
+var replicationChannelRegexp = regexp.MustCompile(`^[\da-zA-Z_\-.]{1,64}$`)
+
+// runningReplicaServerIds are the replica server ids of migrations running in this process. MySQL
+// disconnects a binlog client once another one connects with the same server id.
+var (
+	runningReplicaServerIds      = make(map[uint]bool)
+	runningReplicaServerIdsMutex sync.Mutex
+)
+
+func claimReplicaServerId(serverId uint) error {
+	runningReplicaServerIdsMutex.Lock()
+	defer runningReplicaServerIdsMutex.Unlock()
+
+	if runningReplicaServerIds[serverId] {
+		return conflictingOptions("replica-server-id", "--replica-server-id %d is in use by another migration in this process", serverId)
+	}
+	runningReplicaServerIds[serverId] = true
+	return nil
+}
+
+func releaseReplicaServerId(serverId uint) {
+	runningReplicaServerIdsMutex.Lock()
+	defer runningReplicaServerIdsMutex.Unlock()
+	delete(runningReplicaServerIds, serverId)
+}
+
+// Event is a migration lifecycle event. Name is that of the hook the event triggers, e.g.
+// "gh-ost-on-startup", "gh-ost-on-status" or "gh-ost-on-success"; see doc/hooks.md.
+type Event struct {
+	Name     string
+	Message  string
+	Time     time.Time
+	Progress Progress
+}
+
+// Progress is a snapshot of migration progress
+type Progress struct {
+	RowsCopied     int64
+	RowsEstimate   int64
+	DMLApplied     int64
+	ProgressPct    float64
+	ETA            time.Duration // negative while unknown
+	Lag            time.Duration
+	IsThrottled    bool
+	ThrottleReason string
+}
+
+// Migration is a handle on a single migration: run it, follow its events and control it.
+// Each migration has its own context; several migrations may run in one process.
+type Migration struct {
+	migrationContext *base.MigrationContext
+	migrator         *logic.Migrator
+	events           chan Event
+	eventsMutex      sync.Mutex
+	running          int64
+}
+
+// NewMigration validates and defaults options as the gh-ost command line does, and prepares a
+// migration. Invalid options return an *OptionError.
+func NewMigration(options *Options) (*Migration, error) {
+	migrationContext, err := NewMigrationContext(options)
+	if err != nil {
+		return nil, err
+	}
+	migration := &Migration{
+		migrationContext: migrationContext,
+		events:           make(chan Event, EventsBufferSize),
+	}
+	migrationContext.EventListener = migration.onEvent
+	migration.migrator = logic.NewMigrator(migrationContext, options.AppVersion)
+	return migration, nil
+}
+
+// NewMigrationContext validates and defaults options as the gh-ost command line does, and returns
+// the resulting migration context. Invalid options return an *OptionError.
+func NewMigrationContext(options *Options) (*base.MigrationContext, error) {
+	migrationContext := base.NewMigrationContext()
+	migrationContext.Log = options.Logger
+	if migrationContext.Log == nil {
+		migrationContext.Log = base.NewWriterLogger(os.Stderr)
+	}
+	applyOptions(migrationContext, options)
+
+	if err := migrationContext.SetConnectionConfig(options.StorageEngine); err != nil {
+		return nil, invalidOption("storage-engine", err)
+	}
+	migrationContext.SetConnectionCharset(options.Charset)
+
+	if migrationContext.AlterStatement == "" && !migrationContext.Revert {
+		return nil, missingOption("alter", "--alter must be provided and statement must not be empty")
+	}
+	parser := sql.NewParserFromAlterStatement(migrationContext.AlterStatement)
+	migrationContext.AlterStatementOptions = parser.GetAlterStatementOptions()
+
+	if migrationContext.Revert {
+		if migrationContext.Resume {
+			return nil, conflictingOptions("revert", "--revert cannot be used with --resume")
+		}
+		if migrationContext.OldTableName == "" {
+			return nil, missingOption("old-table", "--revert must be called with --old-table")
+		}
+
+		// options irrelevant to revert mode
+		if migrationContext.AlterStatement != "" {
+			migrationContext.Log.Warning("--alter was provided with --revert, it will be ignored")
+		}
+		if migrationContext.AttemptInstantDDL {
+			migrationContext.Log.Warning("--attempt-instant-ddl was provided with --revert, it will be ignored")
+		}
+		if migrationContext.AttemptInplaceDDL {
+			migrationContext.Log.Warning("--attempt-inplace-ddl was provided with --revert, it will be ignored")
+		}
+		if migrationContext.IncludeTriggers {
+			migrationContext.Log.Warning("--include-triggers was provided with --revert, it will be ignored")
+		}
+		if migrationContext.DiscardForeignKeys {
+			migrationContext.Log.Warning("--discard-foreign-keys was provided with --revert, it will be ignored")
+		}
+	}
+
+	if migrationContext.DatabaseName == "" {
+		if !parser.HasExplicitSchema() {
+			return nil, missingOption("database", "--database must be provided and database name must not be empty, or --alter must specify database name")
+		}
+		migrationContext.DatabaseName = parser.GetExplicitSchema()
+	}
+	migrationContext.DatabaseName = url.QueryEscape(migrationContext.DatabaseName)
+
+	if migrationContext.OriginalTableName == "" {
+		if !parser.HasExplicitTable() {
+			return nil, missingOption("table", "--table must be provided and table name must not be empty, or --alter must specify table name")
+		}
+		migrationContext.OriginalTableName = parser.GetExplicitTable()
+	}
+	if err := validateOptions(migrationContext, options); err != nil {
+		return nil, err
+	}
+
+	switch options.CutOver {
+	case "atomic", "default", "":
+		migrationContext.CutOverType = base.CutOverAtomic
+	case "two-step":
+		migrationContext.CutOverType = base.CutOverTwoStep
+	default:
+		return nil, invalidOption("cut-over", fmt.Errorf("Unknown cut-over: %s", options.CutOver))
+	}
+	if err := migrationContext.ReadConfigFile(); err != nil {
+		return nil, invalidOption("conf", err)
+	}
+	if err := migrationContext.ReadThrottleControlReplicaKeys(options.ThrottleControlReplicas); err != nil {
+		return nil, invalidOption("throttle-control-replicas", err)
+	}
+	if err := migrationContext.ReadMaxLoad(options.MaxLoad); err != nil {
+		return nil, invalidOption("max-load", err)
+	}
+	if err := migrationContext.ReadCriticalLoad(options.CriticalLoad); err != nil {
+		return nil, invalidOption("critical-load", err)
+	}
+	if migrationContext.ServeSocketFile == "" {
+		migrationContext.ServeSocketFile = fmt.Sprintf("/tmp/gh-ost.%s.%s.sock", migrationContext.DatabaseName, migrationContext.OriginalTableName)
+	}
+
+	migrationContext.SetHeartbeatIntervalMilliseconds(options.HeartbeatIntervalMillis)
+	migrationContext.SetNiceRatio(options.NiceRatio)
+	migrationContext.SetChunkSize(options.ChunkSize)
+	migrationContext.SetDMLBatchSize(options.DMLBatchSize)
+	migrationContext.SetMaxDMLBatchSize(options.MaxDMLBatchSize)
+	migrationContext.SetMaxLagMillisecondsThrottleThreshold(options.MaxLagMillis)
+	migrationContext.SetMaxBinlogBacklog(options.MaxBinlogBacklog)
+	migrationContext.SetThrottleQuery(options.ThrottleQuery)
+	migrationContext.SetThrottleHTTP(options.ThrottleHTTP)
+	migrationContext.SetIgnoreHTTPErrors(options.IgnoreHTTPErrors)
+	migrationContext.SetDefaultNumRetries(options.DefaultRetries)
+	migrationContext.ApplyCredentials()
+	if err := migrationContext.SetupCredentialsProvider(); err != nil {
+		return nil, invalidOption("credentials-file", err)
+	}
+	if err := migrationContext.SetupTLS(); err != nil {
+		return nil, invalidOption("ssl", err)
+	}
+	if err := migrationContext.SetCutOverLockTimeoutSeconds(options.CutOverLockTimeoutSeconds); err != nil {
+		migrationContext.Log.Errore(err)
+	}
+	if err := migrationContext.SetExponentialBackoffMaxInterval(options.ExponentialBackoffMaxInterval); err != nil {
+		migrationContext.Log.Errore(err)
+	}
+	return migrationContext, nil
+}
+
+// applyOptions copies options which map directly onto the migration context
+func applyOptions(migrationContext *base.MigrationContext, options *Options) {
+	migrationContext.InspectorConnectionConfig.Key.Hostname = options.Host
+	migrationContext.InspectorConnectionConfig.Key.Port = options.Port
+	migrationContext.InspectorConnectionConfig.Timeout = options.MySQLTimeout
+	migrationContext.InspectorConnectionConfig.Network = options.Network
+	migrationContext.InspectorConnectionConfig.Dialer = options.Dialer
+	migrationContext.CliUser = options.User
+	migrationContext.CliPassword = options.Password
+	migrationContext.CliMasterUser = options.MasterUser
+	migrationContext.CliMasterPassword = options.MasterPassword
+	migrationContext.AssumeMasterHostname = options.AssumeMasterHost
+	migrationContext.ActiveActivePeerHostname = options.ActiveActivePeer
+	migrationContext.ReplicationChannel = options.ReplicationChannel
+	migrationContext.ConfigFile = options.ConfigFile
+	migrationContext.CredentialsFile = options.CredentialsFile
+	migrationContext.CredentialsCommand = options.CredentialsCommand
+	migrationContext.CredentialsCacheSeconds = options.CredentialsCacheSeconds
+	migrationContext.UseTLS = options.UseTLS
+	migrationContext.TLSCACertificate = options.TLSCACertificate
+	migrationContext.TLSCertificate = options.TLSCertificate
+	migrationContext.TLSKey = options.TLSKey
+	migrationContext.TLSAllowInsecure = options.TLSAllowInsecure
+	migrationContext.ReplicaServerId = options.ReplicaServerId
+	migrationContext.SkipPortValidation = options.SkipPortValidation
+	migrationContext.BinlogSyncerMaxReconnectAttempts = options.BinlogSyncerMaxReconnectAttempts
+	migrationContext.StreamerFailover = options.StreamerFailover
+	migrationContext.UseGTIDs = options.UseGTIDs
+	migrationContext.IsTungsten = options.IsTungsten
+	migrationContext.AliyunRDS = options.AliyunRDS
+	migrationContext.GoogleCloudPlatform = options.GoogleCloudPlatform
+	migrationContext.AzureMySQL = options.AzureMySQL
+
+	migrationContext.DatabaseName = options.DatabaseName
+	migrationContext.OriginalTableName = options.TableName
+	migrationContext.AlterStatement = options.AlterStatement
+	migrationContext.Noop = !options.Execute
+	migrationContext.AttemptInstantDDL = options.AttemptInstantDDL
+	migrationContext.AttemptInplaceDDL = options.AttemptInplaceDDL
+	migrationContext.CountTableRows = options.CountTableRows
+	migrationContext.ConcurrentCountTableRows = options.ConcurrentCountTableRows
+	migrationContext.CopyFromReplica = options.CopyFromReplica
+	migrationContext.AllowedRunningOnMaster = options.AllowedRunningOnMaster
+	migrationContext.AllowedMasterMaster = options.AllowedMasterMaster
+	migrationContext.NullableUniqueKeyAllowed = options.NullableUniqueKeyAllowed
+	migrationContext.NonUniqueKeyAllowed = options.NonUniqueKeyAllowed
+	migrationContext.MigrationKey = options.MigrationKey
+	migrationContext.ApproveRenamedColumns = options.ApproveRenamedColumns
+	migrationContext.SkipRenamedColumns = options.SkipRenamedColumns
+	migrationContext.DiscardForeignKeys = options.DiscardForeignKeys
+	migrationContext.SkipForeignKeyChecks = options.SkipForeignKeyChecks
+	migrationContext.SkipStrictMode = options.SkipStrictMode
+	migrationContext.AllowZeroInDate = options.AllowZeroInDate
+	migrationContext.TestOnReplica = options.TestOnReplica
+	migrationContext.TestOnReplicaSkipReplicaStop = options.TestOnReplicaSkipReplicaStop
+	migrationContext.MigrateOnReplica = options.MigrateOnReplica
+	migrationContext.OkToDropTable = options.OkToDropTable
+	migrationContext.InitiallyDropOldTable = options.InitiallyDropOldTable
+	migrationContext.InitiallyDropGhostTable = options.InitiallyDropGhostTable
+	migrationContext.TimestampOldTable = options.TimestampOldTable
+	migrationContext.SwitchToRowBinlogFormat = options.SwitchToRowBinlogFormat
+	migrationContext.AssumeRBR = options.AssumeRBR
+	migrationContext.CoalesceDMLEvents = options.CoalesceDMLEvents
+	migrationContext.MaxDMLStatementSize = options.MaxDMLStatementSize
+	migrationContext.MaxDMLBatchLatencyMillis = options.MaxDMLBatchLatencyMillis
+	migrationContext.PanicOnWarnings = options.PanicOnWarnings
+	migrationContext.IncludeTriggers = options.IncludeTriggers
+	migrationContext.TriggerSuffix = options.TriggerSuffix
+	migrationContext.RemoveTriggerSuffix = options.RemoveTriggerSuffix
+	migrationContext.ForceTmpTableName = options.ForceTmpTableName
+	migrationContext.Checkpoint = options.Checkpoint
+	migrationContext.CheckpointIntervalSeconds = options.CheckpointIntervalSeconds
+	migrationContext.BinlogRetentionMarginSeconds = options.BinlogRetentionMarginSeconds
+	migrationContext.BinlogRetentionPauseRowCopy = options.BinlogRetentionPauseRowCopy
+	migrationContext.Resume = options.Resume
+	migrationContext.Revert = options.Revert
+	migrationContext.OldTableName = options.OldTableName
+
+	migrationContext.ThrottleHTTPIntervalMillis = options.ThrottleHTTPIntervalMillis
+	migrationContext.ThrottleHTTPTimeoutMillis = options.ThrottleHTTPTimeoutMillis
+	migrationContext.ThrottleFlagFile = options.ThrottleFlagFile
+	migrationContext.ThrottleAdditionalFlagFile = options.ThrottleAdditionalFlagFile
+	migrationContext.CriticalLoadIntervalMilliseconds = options.CriticalLoadIntervalMilliseconds
+	migrationContext.CriticalLoadHibernateSeconds = options.CriticalLoadHibernateSeconds
+
+	migrationContext.CutOverExponentialBackoff = options.CutOverExponentialBackoff
+	migrationContext.PostponeCutOverFlagFile = options.PostponeCutOverFlagFile
+	migrationContext.ForceNamedCutOverCommand = options.ForceNamedCutOverCommand
+	migrationContext.ForceNamedPanicCommand = options.ForceNamedPanicCommand
+	migrationContext.AllowSetupMetadataLockInstruments = options.AllowSetupMetadataLockInstruments
+	migrationContext.SkipMetadataLockCheck = options.SkipMetadataLockCheck
+
+	migrationContext.PanicFlagFile = options.PanicFlagFile
+	migrationContext.DropServeSocket = options.DropServeSocket
+	migrationContext.ServeSocketFile = options.ServeSocketFile
+	migrationContext.ServeTCPPort = options.ServeTCPPort
+
+	migrationContext.HooksPath = options.HooksPath
+	migrationContext.HooksHintMessage = options.HooksHintMessage
+	migrationContext.HooksHintOwner = options.HooksHintOwner
+	migrationContext.HooksHintToken = options.HooksHintToken
+	migrationContext.HooksStatusIntervalSec = options.HooksStatusIntervalSec
+}
+
+// validateOptions checks for conflicting and dependent options
+func validateOptions(migrationContext *base.MigrationContext, options *Options) error {
+	if migrationContext.AllowedRunningOnMaster && migrationContext.TestOnReplica {
+		return conflictingOptions("allow-on-master", "--allow-on-master and --test-on-replica are mutually exclusive")
+	}
+	if migrationContext.AllowedRunningOnMaster && migrationContext.MigrateOnReplica {
+		return conflictingOptions("allow-on-master", "--allow-on-master and --migrate-on-replica are mutually exclusive")
+	}
+	if migrationContext.MigrateOnReplica && migrationContext.TestOnReplica {
+		return conflictingOptions("migrate-on-replica", "--migrate-on-replica and --test-on-replica are mutually exclusive")
+	}
+	if migrationContext.NonUniqueKeyAllowed && migrationContext.Resume {
+		return conflictingOptions("allow-non-unique-key", "--allow-non-unique-key cannot be used with --resume")
+	}
+	if migrationContext.NonUniqueKeyAllowed && migrationContext.MigrationKey != "" {
+		return conflictingOptions("migration-key", "--migration-key cannot be used with --allow-non-unique-key")
+	}
+	if migrationContext.CopyFromReplica && (migrationContext.TestOnReplica || migrationContext.MigrateOnReplica) {
+		return conflictingOptions("copy-from-replica", "--copy-from-replica cannot be used with --test-on-replica or --migrate-on-replica")
+	}
+	if migrationContext.SwitchToRowBinlogFormat && migrationContext.AssumeRBR {
+		return conflictingOptions("switch-to-rbr", "--switch-to-rbr and --assume-rbr are mutually exclusive")
+	}
+	if migrationContext.TestOnReplicaSkipReplicaStop {
+		if !migrationContext.TestOnReplica {
+			return missingOption("test-on-replica", "--test-on-replica-skip-replica-stop requires --test-on-replica to be enabled")
+		}
+		migrationContext.Log.Warning("--test-on-replica-skip-replica-stop enabled. We will not stop replication before cut-over. Ensure you have a plugin that does this.")
+	}
+	if migrationContext.ActiveActivePeerHostname != "" {
+		if !migrationContext.AllowedRunningOnMaster {
+			return missingOption("allow-on-master", "--active-active-peer requires --allow-on-master: gh-ost must connect to one of the masters")
+		}
+		if !migrationContext.UseGTIDs {
+			return missingOption("gtid", "--active-active-peer requires --gtid")
+		}
+	}
+	if migrationContext.StreamerFailover {
+		if !migrationContext.UseGTIDs {
+			return missingOption("gtid", "--streamer-failover requires --gtid")
+		}
+		if migrationContext.BinlogSyncerMaxReconnectAttempts <= 0 {
+			return missingOption("binlogsyncer-max-reconnect-attempts", "--streamer-failover requires a positive --binlogsyncer-max-reconnect-attempts")
+		}
+		if migrationContext.ActiveActivePeerHostname != "" {
+			return conflictingOptions("streamer-failover", "--streamer-failover cannot be used with --active-active-peer")
+		}
+		if migrationContext.TestOnReplica || migrationContext.MigrateOnReplica {
+			return conflictingOptions("streamer-failover", "--streamer-failover cannot be used with --test-on-replica or --migrate-on-replica")
+		}
+		if migrationContext.CopyFromReplica {
+			return conflictingOptions("streamer-failover", "--streamer-failover cannot be used with --copy-from-replica")
+		}
+	}
+	if migrationContext.ReplicationChannel != "" && !replicationChannelRegexp.MatchString(migrationContext.ReplicationChannel) {
+		return invalidOption("replication-channel", fmt.Errorf("--replication-channel must be up to 64 alpha numeric characters, underscore, dash and dot"))
+	}
+	if migrationContext.CliMasterUser != "" && migrationContext.AssumeMasterHostname == "" {
+		return missingOption("assume-master-host", "--master-user requires --assume-master-host")
+	}
+	if migrationContext.CliMasterPassword != "" && migrationContext.AssumeMasterHostname == "" {
+		return missingOption("assume-master-host", "--master-password requires --assume-master-host")
+	}
+	if migrationContext.CredentialsFile != "" && migrationContext.CredentialsCommand != "" {
+		return conflictingOptions("credentials-file", "--credentials-file and --credentials-command are mutually exclusive")
+	}
+	if (migrationContext.CredentialsFile != "" || migrationContext.CredentialsCommand != "") && migrationContext.CliPassword != "" {
+		return conflictingOptions("password", "--credentials-file and --credentials-command cannot be used with --password or --ask-pass")
+	}
+	if migrationContext.CredentialsCacheSeconds < 0 {
+		return invalidOption("credentials-cache-seconds", fmt.Errorf("--credentials-cache-seconds must be non-negative"))
+	}
+	if options.MaxDMLBatchSize > 0 && options.MaxDMLBatchSize < options.DMLBatchSize {
+		return conflictingOptions("max-dml-batch-size", "--max-dml-batch-size must not be lower than --dml-batch-size")
+	}
+	if migrationContext.MaxDMLBatchLatencyMillis <= 0 {
+		return invalidOption("max-dml-batch-latency-millis", fmt.Errorf("--max-dml-batch-latency-millis must be positive"))
+	}
+	if migrationContext.MaxDMLStatementSize < 0 {
+		return invalidOption("max-dml-statement-size", fmt.Errorf("--max-dml-statement-size must be non-negative"))
+	}
+	if migrationContext.TLSCACertificate != "" && !migrationContext.UseTLS {
+		return missingOption("ssl", "--ssl-ca requires --ssl")
+	}
+	if migrationContext.TLSCertificate != "" && !migrationContext.UseTLS {
+		return missingOption("ssl", "--ssl-cert requires --ssl")
+	}
+	if migrationContext.TLSKey != "" && !migrationContext.UseTLS {
+		return missingOption("ssl", "--ssl-key requires --ssl")
+	}
+	if migrationContext.TLSAllowInsecure && !migrationContext.UseTLS {
+		return missingOption("ssl", "--ssl-allow-insecure requires --ssl")
+	}
+	if options.ReplicationLagQuery != "" {
+		migrationContext.Log.Warningf("--replication-lag-query is deprecated")
+	}
+	if migrationContext.IncludeTriggers && migrationContext.TriggerSuffix == "" {
+		return missingOption("trigger-suffix", "--trigger-suffix must be used with --include-triggers")
+	}
+	if !migrationContext.IncludeTriggers && migrationContext.TriggerSuffix != "" {
+		return missingOption("include-triggers", "--trigger-suffix cannot be be used without --include-triggers")
+	}
+	if migrationContext.TriggerSuffix != "" && !triggerSuffixRegexp.MatchString(migrationContext.TriggerSuffix) {
+		return invalidOption("trigger-suffix", fmt.Errorf("--trigger-suffix must contain only alpha numeric characters and underscore (0-9,a-z,A-Z,_)"))
+	}
+	if options.StorageEngine == "rocksdb" {
+		migrationContext.Log.Warning("RocksDB storage engine support is experimental")
+	}
+	if migrationContext.CheckpointIntervalSeconds < 10 {
+		return invalidOption("checkpoint-seconds", fmt.Errorf("--checkpoint-seconds should be >=10"))
+	}
+	if migrationContext.BinlogRetentionMarginSeconds < 0 {
+		return invalidOption("binlog-retention-margin-seconds", fmt.Errorf("--binlog-retention-margin-seconds should be >=0"))
+	}
+	if migrationContext.BinlogRetentionPauseRowCopy && migrationContext.BinlogRetentionMarginSeconds == 0 {
+		return missingOption("binlog-retention-margin-seconds", "--binlog-retention-pause-row-copy requires a positive --binlog-retention-margin-seconds")
+	}
+	if migrationContext.CountTableRows && migrationContext.PanicOnWarnings {
+		migrationContext.Log.Warning("--exact-rowcount with --panic-on-warnings: row counts cannot be exact due to warning detection")
+	}
+	return nil
+}
+
+// Context returns the migration context
+func (this *Migration) Context() *base.MigrationContext {
+	return this.migrationContext
+}
+
+// Events returns the channel of migration lifecycle events, closed once Run returns
+func (this *Migration) Events() <-chan Event {
+	return this.events
+}
+
+func (this *Migration) onEvent(name, message string) {
+	this.eventsMutex.Lock()
+	defer this.eventsMutex.Unlock()
+
+	if atomic.LoadInt64(&this.running) != 1 {
+		return
+	}
+	select {
+	case this.events <- Event{Name: name, Message: message, Time: time.Now(), Progress: this.Progress()}:
+	default:
+		// nobody keeping up with events; we do not block the migration
+	}
+}
+
+// Run runs the migration, or the revert with Options.Revert, and blocks until it completes or fails.
+// Run may only be called once. Migrations running concurrently in a process must have distinct
+// replica server ids.
+func (this *Migration) Run() (err error) {
+	if !atomic.CompareAndSwapInt64(&this.running, 0, 1) {
+		return fmt.Errorf("Migration of %s.%s has already run", sql.EscapeName(this.migrationContext.DatabaseName), sql.EscapeName(this.migrationContext.OriginalTableName))
+	}
+	if err := claimReplicaServerId(this.migrationContext.ReplicaServerId); err != nil {
+		this.closeEvents()
+		return err
+	}
+	defer releaseReplicaServerId(this.migrationContext.ReplicaServerId)
+	defer this.closeEvents()
+
+	if this.migrationContext.Revert {
+		err = this.migrator.Revert()
+	} else {
+		err = this.migrator.Migrate()
+	}
+	if err != nil && !errors.Is(err, ErrDetached) {
+		this.migrator.ExecOnFailureHook()
+	}
+	return err
+}
+
+func (this *Migration) closeEvents() {
+	this.eventsMutex.Lock()
+	defer this.eventsMutex.Unlock()
+	atomic.StoreInt64(&this.running, 2)
+	close(this.events)
+}
+
+// Progress returns a snapshot of the migration progress
+func (this *Migration) Progress() Progress {
+	isThrottled, throttleReason, _ := this.migrationContext.IsThrottled()
+	eta := time.Duration(-1)
+	if etaSeconds := this.migrationContext.GetETASeconds(); etaSeconds >= 0 {
+		eta = time.Duration(etaSeconds) * time.Second
+	}
+	return Progress{
+		RowsCopied:     this.migrationContext.GetTotalRowsCopied(),
+		RowsEstimate:   atomic.LoadInt64(&this.migrationContext.RowsEstimate) + atomic.LoadInt64(&this.migrationContext.RowsDeltaEstimate),
+		DMLApplied:     atomic.LoadInt64(&this.migrationContext.TotalDMLEventsApplied),
+		ProgressPct:    this.migrationContext.GetProgressPct(),
+		ETA:            eta,
+		Lag:            this.migrationContext.GetCurrentLagDuration(),
+		IsThrottled:    isThrottled,
+		ThrottleReason: throttleReason,
+	}
+}
+
+// Throttle forces throttling, as the `throttle` interactive command does
+func (this *Migration) Throttle() {
+	atomic.StoreInt64(&this.migrationContext.ThrottleCommandedByUser, 1)
+}
+
+// Unthrottle ends forced throttling, as the `no-throttle` interactive command does. Other
+// throttling reasons may still apply.
+func (this *Migration) Unthrottle() {
+	atomic.StoreInt64(&this.migrationContext.ThrottleCommandedByUser, 0)
+}
+
+// CutOver ends postponing the cut-over, as the `unpostpone` interactive command does.
+// It returns ErrNotPostponingCutOver unless the migration is postponing its cut-over.
+func (this *Migration) CutOver() error {
+	if atomic.LoadInt64(&this.migrationContext.IsPostponingCutOver) == 0 {
+		return ErrNotPostponingCutOver
+	}
+	atomic.StoreInt64(&this.migrationContext.UserCommandedUnpostponeFlag, 1)
+	return nil
+}
+
+// Detach writes a checkpoint and then stops the migration without cleanup, as the `detach` interactive
+// command does. Run returns ErrDetached; a new migration given --resume re-attaches from the checkpoint.
+func (this *Migration) Detach() error {
+	_, err := this.migrator.Detach()
+	return err
+}
+
+// Abort aborts the migration without cleanup, as the `panic` interactive command does.
+// Run returns the given reason.
+func (this *Migration) Abort(reason error) {
+	_ = base.SendWithContext(this.migrationContext.GetContext(), this.migrationContext.PanicAbort, reason)
+}
@@ -0,0 +1,257 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package ghost
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/github/gh-ost/go/base"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOptions() *Options {
+	options := DefaultOptions()
+	options.DatabaseName = "test"
+	options.TableName = "gh_ost_test"
+	options.AlterStatement = "ALTER TABLE gh_ost_test ADD COLUMN i INT"
+	return options
+}
+
+func TestNewMigrationContext(t *testing.T) {
+	options := newTestOptions()
+	options.ChunkSize = 500
+	options.CutOver = "two-step"
+	migrationContext, err := NewMigrationContext(options)
+	require.NoError(t, err)
+	require.Equal(t, "test", migrationContext.DatabaseName)
+	require.Equal(t, "gh_ost_test", migrationContext.OriginalTableName)
+	require.True(t, migrationContext.Noop)
+	require.Equal(t, int64(500), migrationContext.ChunkSize)
+	require.Equal(t, int64(10), migrationContext.DMLBatchSize)
+	require.Equal(t, int64(1500), migrationContext.MaxLagMillisecondsThrottleThreshold)
+	require.Equal(t, base.CutOverTwoStep, migrationContext.CutOverType)
+	require.Equal(t, "/tmp/gh-ost.test.gh_ost_test.sock", migrationContext.ServeSocketFile)
+	require.Equal(t, 3306, migrationContext.InspectorConnectionConfig.Key.Port)
+}
+
+func TestNewMigrationContextFromAlterStatement(t *testing.T) {
+	options := DefaultOptions()
+	options.AlterStatement = "ALTER TABLE `my-db`.`gh_ost_test` ADD COLUMN i INT"
+	options.Execute = true
+	migrationContext, err := NewMigrationContext(options)
+	require.NoError(t, err)
+	require.Equal(t, "my-db", migrationContext.DatabaseName)
+	require.Equal(t, "gh_ost_test", migrationContext.OriginalTableName)
+	require.False(t, migrationContext.Noop)
+}
+
+func TestNewMigrationContextErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Options)
+		option string
+		kind   error
+	}{
+		{
+			name:   "missing alter",
+			modify: func(o *Options) { o.AlterStatement = "" },
+			option: "alter",
+			kind:   ErrMissingOption,
+		},
+		{
+			name:   "missing table",
+			modify: func(o *Options) { o.TableName = ""; o.AlterStatement = "ADD COLUMN i INT" },
+			option: "table",
+			kind:   ErrMissingOption,
+		},
+		{
+			name:   "on master and on replica",
+			modify: func(o *Options) { o.AllowedRunningOnMaster = true; o.TestOnReplica = true },
+			option: "allow-on-master",
+			kind:   ErrConflictingOptions,
+		},
+		{
+			name:   "revert without old table",
+			modify: func(o *Options) { o.Revert = true },
+			option: "old-table",
+			kind:   ErrMissingOption,
+		},
+		{
+			name:   "ssl-ca without ssl",
+			modify: func(o *Options) { o.TLSCACertificate = "/tmp/ca.pem" },
+			option: "ssl",
+			kind:   ErrMissingOption,
+		},
+		{
+			name:   "bad trigger suffix",
+			modify: func(o *Options) { o.IncludeTriggers = true; o.TriggerSuffix = "_v-2" },
+			option: "trigger-suffix",
+			kind:   ErrInvalidOption,
+		},
+		{
+			name:   "unknown cut-over",
+			modify: func(o *Options) { o.CutOver = "three-step" },
+			option: "cut-over",
+			kind:   ErrInvalidOption,
+		},
+		{
+			name:   "max-dml-batch-size below dml-batch-size",
+			modify: func(o *Options) { o.DMLBatchSize = 50; o.MaxDMLBatchSize = 20 },
+			option: "max-dml-batch-size",
+			kind:   ErrConflictingOptions,
+		},
+		{
+			name:   "migration-key with non-unique key",
+			modify: func(o *Options) { o.MigrationKey = "uk"; o.NonUniqueKeyAllowed = true },
+			option: "migration-key",
+			kind:   ErrConflictingOptions,
+		},
+		{
+			name:   "active-active peer without gtid",
+			modify: func(o *Options) { o.ActiveActivePeer = "peer:3306"; o.AllowedRunningOnMaster = true },
+			option: "gtid",
+			kind:   ErrMissingOption,
+		},
+		{
+			name:   "bad replication channel",
+			modify: func(o *Options) { o.ReplicationChannel = "source'1" },
+			option: "replication-channel",
+			kind:   ErrInvalidOption,
+		},
+		{
+			name:   "negative binlog retention margin",
+			modify: func(o *Options) { o.BinlogRetentionMarginSeconds = -1 },
+			option: "binlog-retention-margin-seconds",
+			kind:   ErrInvalidOption,
+		},
+		{
+			name:   "binlog retention pause without margin",
+			modify: func(o *Options) { o.BinlogRetentionPauseRowCopy = true },
+			option: "binlog-retention-margin-seconds",
+			kind:   ErrMissingOption,
+		},
+		{
+			name:   "streamer failover without gtid",
+			modify: func(o *Options) { o.StreamerFailover = true; o.BinlogSyncerMaxReconnectAttempts = 3 },
+			option: "gtid",
+			kind:   ErrMissingOption,
+		},
+		{
+			name:   "streamer failover without reconnect attempts",
+			modify: func(o *Options) { o.StreamerFailover = true; o.UseGTIDs = true },
+			option: "binlogsyncer-max-reconnect-attempts",
+			kind:   ErrMissingOption,
+		},
+		{
+			name: "streamer failover on replica",
+			modify: func(o *Options) {
+				o.StreamerFailover = true
+				o.UseGTIDs = true
+				o.BinlogSyncerMaxReconnectAttempts = 3
+				o.TestOnReplica = true
+			},
+			option: "streamer-failover",
+			kind:   ErrConflictingOptions,
+		},
+		{
+			name:   "bad max-load",
+			modify: func(o *Options) { o.MaxLoad = "Threads_running" },
+			option: "max-load",
+			kind:   ErrInvalidOption,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := newTestOptions()
+			test.modify(options)
+			_, err := NewMigrationContext(options)
+			require.Error(t, err)
+			require.ErrorIs(t, err, test.kind)
+
+			var optionError *OptionError
+			require.True(t, errors.As(err, &optionError))
+			require.Equal(t, test.option, optionError.Option)
+		})
+	}
+}
+
+func TestMigrationControl(t *testing.T) {
+	migration, err := NewMigration(newTestOptions())
+	require.NoError(t, err)
+	migrationContext := migration.Context()
+
+	migration.Throttle()
+	require.Equal(t, int64(1), atomic.LoadInt64(&migrationContext.ThrottleCommandedByUser))
+	migration.Unthrottle()
+	require.Equal(t, int64(0), atomic.LoadInt64(&migrationContext.ThrottleCommandedByUser))
+
+	require.ErrorIs(t, migration.CutOver(), ErrNotPostponingCutOver)
+	atomic.StoreInt64(&migrationContext.IsPostponingCutOver, 1)
+	require.NoError(t, migration.CutOver())
+	require.Equal(t, int64(1), atomic.LoadInt64(&migrationContext.UserCommandedUnpostponeFlag))
+
+	abortErr := errors.New("aborted by test")
+	go migration.Abort(abortErr)
+	require.Equal(t, abortErr, <-migrationContext.PanicAbort)
+}
+
+func TestMigrationEvents(t *testing.T) {
+	migration, err := NewMigration(newTestOptions())
+	require.NoError(t, err)
+	migrationContext := migration.Context()
+
+	// not running: events are not delivered
+	migrationContext.EventListener("gh-ost-on-startup", "")
+	require.Len(t, migration.Events(), 0)
+
+	atomic.StoreInt64(&migration.running, 1)
+	migrationContext.EventListener("gh-ost-on-status", "Copy: 0/0 0.0%")
+	event := <-migration.Events()
+	require.Equal(t, "gh-ost-on-status", event.Name)
+	require.Equal(t, "Copy: 0/0 0.0%", event.Message)
+
+	// a full channel does not block
+	for i := 0; i < EventsBufferSize+1; i++ {
+		migrationContext.EventListener("gh-ost-on-status", "")
+	}
+	require.Len(t, migration.Events(), EventsBufferSize)
+}
+
+func TestMigrationsAreIsolated(t *testing.T) {
+	options := newTestOptions()
+	first, err := NewMigration(options)
+	require.NoError(t, err)
+
+	options.TableName = "gh_ost_test2"
+	options.ChunkSize = 2000
+	second, err := NewMigration(options)
+	require.NoError(t, err)
+
+	first.Throttle()
+	require.Equal(t, int64(0), atomic.LoadInt64(&second.Context().ThrottleCommandedByUser))
+	require.Equal(t, int64(1000), first.Context().ChunkSize)
+	require.Equal(t, int64(2000), second.Context().ChunkSize)
+	require.NotEqual(t, first.Context().ServeSocketFile, second.Context().ServeSocketFile)
+	require.NotSame(t, first.Context().DBCache, second.Context().DBCache)
+	require.NotSame(t, first.Context().Log, second.Context().Log)
+}
+
+func TestMigrationReplicaServerIdInUse(t *testing.T) {
+	require.NoError(t, claimReplicaServerId(12345))
+	defer releaseReplicaServerId(12345)
+
+	options := newTestOptions()
+	options.ReplicaServerId = 12345
+	migration, err := NewMigration(options)
+	require.NoError(t, err)
+
+	err = migration.Run()
+	require.ErrorIs(t, err, ErrConflictingOptions)
+	_, open := <-migration.Events()
+	require.False(t, open)
+}
@@ -0,0 +1,186 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package ghost
+
+import (
+	"github.com/github/gh-ost/go/base"
+
+	gomysqlclient "github.com/go-mysql-org/go-mysql/client"
+)
+
+// Options configure a migration. Each option corresponds to the gh-ost command line flag noted
+// in its comment, and is validated and defaulted the same way. Start off DefaultOptions().
+type Options struct {
+	// Connection
+
+	Host                             string  // --host
+	Port                             int     // --port
+	MySQLTimeout                     float64 // --mysql-timeout
+	User                             string  // --user
+	Password                         string  // --password
+	MasterUser                       string  // --master-user
+	MasterPassword                   string  // --master-password
+	AssumeMasterHost                 string  // --assume-master-host
+	ActiveActivePeer                 string  // --active-active-peer
+	ReplicationChannel               string  // --replication-channel
+	ConfigFile                       string  // --conf
+	CredentialsFile                  string  // --credentials-file
+	CredentialsCommand               string  // --credentials-command
+	CredentialsCacheSeconds          int64   // --credentials-cache-seconds
+	Charset                          string  // --charset
+	UseTLS                           bool    // --ssl
+	TLSCACertificate                 string  // --ssl-ca
+	TLSCertificate                   string  // --ssl-cert
+	TLSKey                           string  // --ssl-key
+	TLSAllowInsecure                 bool    // --ssl-allow-insecure
+	ReplicaServerId                  uint    // --replica-server-id
+	SkipPortValidation               bool    // --skip-port-validation
+	BinlogSyncerMaxReconnectAttempts int     // --binlogsyncer-max-reconnect-attempts
+	StreamerFailover                 bool    // --streamer-failover
+	StorageEngine                    string  // --storage-engine
+	UseGTIDs                         bool    // --gtid
+	IsTungsten                       bool    // --tungsten
+	AliyunRDS                        bool    // --aliyun-rds
+	GoogleCloudPlatform              bool    // --gcp
+	AzureMySQL                       bool    // --azure
+	// Network is the go-sql-driver network name to connect with; no command line equivalent
+	Network string
+	// Dialer is used by binlog connections; no command line equivalent
+	Dialer gomysqlclient.Dialer
+
+	// Migration
+
+	DatabaseName                 string // --database
+	TableName                    string // --table
+	AlterStatement               string // --alter
+	Execute                      bool   // --execute; when false the migration is a noop
+	AttemptInstantDDL            bool   // --attempt-instant-ddl
+	AttemptInplaceDDL            bool   // --attempt-inplace-ddl
+	CountTableRows               bool   // --exact-rowcount
+	ConcurrentCountTableRows     bool   // --concurrent-rowcount
+	CopyFromReplica              bool   // --copy-from-replica
+	AllowedRunningOnMaster       bool   // --allow-on-master
+	AllowedMasterMaster          bool   // --allow-master-master
+	NullableUniqueKeyAllowed     bool   // --allow-nullable-unique-key
+	NonUniqueKeyAllowed          bool   // --allow-non-unique-key
+	MigrationKey                 string // --migration-key
+	ApproveRenamedColumns        bool   // --approve-renamed-columns
+	SkipRenamedColumns           bool   // --skip-renamed-columns
+	DiscardForeignKeys           bool   // --discard-foreign-keys
+	SkipForeignKeyChecks         bool   // --skip-foreign-key-checks
+	SkipStrictMode               bool   // --skip-strict-mode
+	AllowZeroInDate              bool   // --allow-zero-in-date
+	TestOnReplica                bool   // --test-on-replica
+	TestOnReplicaSkipReplicaStop bool   // --test-on-replica-skip-replica-stop
+	MigrateOnReplica             bool   // --migrate-on-replica
+	OkToDropTable                bool   // --ok-to-drop-table
+	InitiallyDropOldTable        bool   // --initially-drop-old-table
+	InitiallyDropGhostTable      bool   // --initially-drop-ghost-table
+	TimestampOldTable            bool   // --timestamp-old-table
+	SwitchToRowBinlogFormat      bool   // --switch-to-rbr
+	AssumeRBR                    bool   // --assume-rbr
+	ChunkSize                    int64  // --chunk-size
+	DMLBatchSize                 int64  // --dml-batch-size
+	MaxDMLBatchSize              int64  // --max-dml-batch-size
+	MaxDMLBatchLatencyMillis     int64  // --max-dml-batch-latency-millis
+	CoalesceDMLEvents            bool   // --coalesce-dml-events
+	MaxDMLStatementSize          int64  // --max-dml-statement-size
+	DefaultRetries               int64  // --default-retries
+	PanicOnWarnings              bool   // --panic-on-warnings
+	IncludeTriggers              bool   // --include-triggers
+	TriggerSuffix                string // --trigger-suffix
+	RemoveTriggerSuffix          bool   // --remove-trigger-suffix-if-exists
+	ForceTmpTableName            string // --force-table-names
+	Checkpoint                   bool   // --checkpoint
+	CheckpointIntervalSeconds    int64  // --checkpoint-seconds
+	Resume                       bool   // --resume
+	Revert                       bool   // --revert
+	OldTableName                 string // --old-table
+
+	// Throttling
+
+	NiceRatio                        float64 // --nice-ratio
+	MaxLagMillis                     int64   // --max-lag-millis
+	MaxBinlogBacklog                 int64   // --max-binlog-backlog
+	BinlogRetentionMarginSeconds     int64   // --binlog-retention-margin-seconds
+	BinlogRetentionPauseRowCopy      bool    // --binlog-retention-pause-row-copy
+	ReplicationLagQuery              string  // --replication-lag-query (deprecated)
+	ThrottleControlReplicas          string  // --throttle-control-replicas
+	ThrottleQuery                    string  // --throttle-query
+	ThrottleHTTP                     string  // --throttle-http
+	ThrottleHTTPIntervalMillis       int64   // --throttle-http-interval-millis
+	ThrottleHTTPTimeoutMillis        int64   // --throttle-http-timeout-millis
+	IgnoreHTTPErrors                 bool    // --ignore-http-errors
+	HeartbeatIntervalMillis          int64   // --heartbeat-interval-millis
+	ThrottleFlagFile                 string  // --throttle-flag-file
+	ThrottleAdditionalFlagFile       string  // --throttle-additional-flag-file
+	MaxLoad                          string  // --max-load
+	CriticalLoad                     string  // --critical-load
+	CriticalLoadIntervalMilliseconds int64   // --critical-load-interval-millis
+	CriticalLoadHibernateSeconds     int64   // --critical-load-hibernate-seconds
+
+	// Cut-over
+
+	CutOver                           string // --cut-over
+	CutOverLockTimeoutSeconds         int64  // --cut-over-lock-timeout-seconds
+	CutOverExponentialBackoff         bool   // --cut-over-exponential-backoff
+	ExponentialBackoffMaxInterval     int64  // --exponential-backoff-max-interval
+	PostponeCutOverFlagFile           string // --postpone-cut-over-flag-file
+	ForceNamedCutOverCommand          bool   // --force-named-cut-over
+	ForceNamedPanicCommand            bool   // --force-named-panic
+	AllowSetupMetadataLockInstruments bool   // --allow-setup-metadata-lock-instruments
+	SkipMetadataLockCheck             bool   // --skip-metadata-lock-check
+
+	// Interactive control
+
+	PanicFlagFile   string // --panic-flag-file
+	DropServeSocket bool   // --initially-drop-socket-file
+	ServeSocketFile string // --serve-socket-file
+	ServeTCPPort    int64  // --serve-tcp-port
+
+	// Hooks
+
+	HooksPath              string // --hooks-path
+	HooksHintMessage       string // --hooks-hint
+	HooksHintOwner         string // --hooks-hint-owner
+	HooksHintToken         string // --hooks-hint-token
+	HooksStatusIntervalSec int64  // --hooks-status-interval
+
+	// Logger receives the migration's log. When nil, the migration logs onto stderr with its own
+	// logger, independently of other migrations in the process.
+	Logger base.Logger
+	// AppVersion is reported in the changelog table and hooks
+	AppVersion string
+}
+
+// DefaultOptions returns options with the command line defaults
+func DefaultOptions() *Options {
+	return &Options{
+		Host:                          "127.0.0.1",
+		Port:                          3306,
+		CredentialsCacheSeconds:       60,
+		Charset:                       "utf8mb4,utf8,latin1",
+		ReplicaServerId:               99999,
+		StorageEngine:                 "innodb",
+		ConcurrentCountTableRows:      true,
+		ChunkSize:                     1000,
+		DMLBatchSize:                  10,
+		MaxDMLBatchLatencyMillis:      100,
+		MaxDMLStatementSize:           1024 * 1024,
+		DefaultRetries:                60,
+		CheckpointIntervalSeconds:     300,
+		MaxLagMillis:                  1500,
+		ThrottleHTTPIntervalMillis:    100,
+		ThrottleHTTPTimeoutMillis:     1000,
+		HeartbeatIntervalMillis:       100,
+		ThrottleAdditionalFlagFile:    "/tmp/gh-ost.throttle",
+		CutOver:                       "atomic",
+		CutOverLockTimeoutSeconds:     3,
+		ExponentialBackoffMaxInterval: 64,
+		HooksStatusIntervalSec:        60,
+		AppVersion:                    "unversioned",
+	}
+}
@@ -32,15 +32,24 @@ import (
 const (
 	GhostChangelogTableComment = "gh-ost changelog"
 	atomicCutOverMagicHint     = "ghost-cut-over-sentry"
+	// CopyFromReplicaInsertBatchSize is the max number of rows in a single insert statement under --copy-from-replica
+	CopyFromReplicaInsertBatchSize = 500
 )
 
 // ErrNoCheckpointFound is returned when an empty checkpoint table is queried.
 var ErrNoCheckpointFound = errors.New("no checkpoint found in _ghk table")
 
+// ErrStaleReplicaChunk is returned when rows read off the replica precede DML events already applied onto the ghost table.
+var ErrStaleReplicaChunk = errors.New("replica chunk is stale: ghost table already has events applied beyond the chunk's coordinates")
+
+// ErrNotWritableMaster is returned when the applier's server is found to no longer be the writable master.
+var ErrNotWritableMaster = errors.New("seems to no longer be the master")
+
 type dmlBuildResult struct {
 	query     string
 	args      []interface{}
 	rowsDelta int64
+	rowsCount int64
 	err       error
 }
 
@@ -49,6 +58,7 @@ func newDmlBuildResult(query string, args []interface{}, rowsDelta int64, err er
 		query:     query,
 		args:      args,
 		rowsDelta: rowsDelta,
+		rowsCount: 1,
 		err:       err,
 	}
 }
@@ -78,11 +88,15 @@ type Applier struct {
 	LastIterationRangeMutex     sync.Mutex
 	LastIterationRangeMinValues *sql.ColumnValues
 	LastIterationRangeMaxValues *sql.ColumnValues
+	// RowCopyRangeExhaustedFlag is set once the entire migration range has been copied
+	RowCopyRangeExhaustedFlag int64
 
-	dmlDeleteQueryBuilder        *sql.DMLDeleteQueryBuilder
-	dmlInsertQueryBuilder        *sql.DMLInsertQueryBuilder
-	dmlUpdateQueryBuilder        *sql.DMLUpdateQueryBuilder
-	checkpointInsertQueryBuilder *sql.CheckpointInsertQueryBuilder
+	dmlDeleteQueryBuilder         *sql.DMLDeleteQueryBuilder
+	dmlInsertQueryBuilder         *sql.DMLInsertQueryBuilder
+	dmlUpdateQueryBuilder         *sql.DMLUpdateQueryBuilder
+	dmlRowImageDeleteQueryBuilder *sql.DMLRowImageDeleteQueryBuilder
+	dmlRowImageInsertQueryBuilder *sql.DMLRowImageInsertQueryBuilder
+	checkpointInsertQueryBuilder  *sql.CheckpointInsertQueryBuilder
 }
 
 func NewApplier(migrationContext *base.MigrationContext) *Applier {
@@ -112,11 +126,11 @@ func (this *Applier) compileMigrationKeyWarningRegex() (*regexp.Regexp, error) {
 func (this *Applier) InitDBConnections() (err error) {
 	applierUri := this.connectionConfig.GetDBUri(this.migrationContext.DatabaseName)
 	uriWithMulti := fmt.Sprintf("%s&multiStatements=true", applierUri)
-	if this.db, _, err = mysql.GetDB(this.migrationContext.Uuid, uriWithMulti); err != nil {
+	if this.db, _, err = this.migrationContext.DBCache.GetDB(uriWithMulti, this.connectionConfig); err != nil {
 		return err
 	}
 	singletonApplierUri := fmt.Sprintf("%s&timeout=0", applierUri)
-	if this.singletonDB, _, err = mysql.GetDB(this.migrationContext.Uuid, singletonApplierUri); err != nil {
+	if this.singletonDB, _, err = this.migrationContext.DBCache.GetDB(singletonApplierUri, this.connectionConfig); err != nil {
 		return err
 	}
 	this.singletonDB.SetMaxOpenConns(1)
@@ -173,6 +187,26 @@ func (this *Applier) prepareQueries() (err error) {
 	); err != nil {
 		return err
 	}
+	if this.migrationContext.UniqueKey.IsNonUnique {
+		if this.dmlRowImageDeleteQueryBuilder, err = sql.NewDMLRowImageDeleteQueryBuilder(
+			this.migrationContext.GetGhostDatabaseName(),
+			this.migrationContext.GetGhostTableName(),
+			this.migrationContext.OriginalTableColumns,
+			this.migrationContext.SharedColumns,
+			this.migrationContext.MappedSharedColumns,
+		); err != nil {
+			return err
+		}
+		if this.dmlRowImageInsertQueryBuilder, err = sql.NewDMLRowImageInsertQueryBuilder(
+			this.migrationContext.GetGhostDatabaseName(),
+			this.migrationContext.GetGhostTableName(),
+			this.migrationContext.OriginalTableColumns,
+			this.migrationContext.SharedColumns,
+			this.migrationContext.MappedSharedColumns,
+		); err != nil {
+			return err
+		}
+	}
 	if this.migrationContext.Checkpoint {
 		if this.checkpointInsertQueryBuilder, err = sql.NewCheckpointQueryBuilder(
 			this.migrationContext.DatabaseName,
@@ -199,6 +233,30 @@ func (this *Applier) validateAndReadGlobalVariables() error {
 	return nil
 }
 
+// validateWritableMaster checks that the applier's server still is the topology's writable master, as it
+// may no longer be after a master failover. It returns the server's server_uuid.
+func (this *Applier) validateWritableMaster() (serverUUID string, err error) {
+	var readOnly, superReadOnly bool
+	query := `select /* gh-ost */ @@global.read_only, @@global.super_read_only, @@global.server_uuid`
+	if err := this.db.QueryRow(query).Scan(&readOnly, &superReadOnly, &serverUUID); err != nil {
+		return "", err
+	}
+	if readOnly || superReadOnly {
+		return "", fmt.Errorf("%s is read_only, and %w. Bailing out", this.connectionConfig.Key.String(), ErrNotWritableMaster)
+	}
+	if !this.migrationContext.AllowedMasterMaster {
+		masterKey, err := mysql.GetMasterKeyFromSlaveStatus(this.migrationContext.ApplierMySQLVersion, this.connectionConfig, "")
+		if err != nil {
+			return "", err
+		}
+		if masterKey != nil && masterKey.IsValid() {
+			return "", fmt.Errorf("%s replicates from %s, and %w. Bailing out", this.connectionConfig.Key.String(), masterKey.String(), ErrNotWritableMaster)
+		}
+	}
+	this.migrationContext.Log.Debugf("%s validated as writable master", this.connectionConfig.Key.String())
+	return serverUUID, nil
+}
+
 // generateSqlModeQuery return a `sql_mode = ...` query, to be wrapped with a `set session` or `set global`,
 // based on gh-ost configuration:
 // - User may skip strict mode
@@ -226,6 +284,16 @@ func (this *Applier) generateInstantDDLQuery() string {
 	)
 }
 
+// generateInplaceDDLQuery returns the SQL for this ALTER operation
+// with an INPLACE, non-locking assertion
+func (this *Applier) generateInplaceDDLQuery() string {
+	return fmt.Sprintf(`ALTER /* gh-ost */ TABLE %s.%s %s, ALGORITHM=INPLACE, LOCK=NONE`,
+		sql.EscapeName(this.migrationContext.DatabaseName),
+		sql.EscapeName(this.migrationContext.OriginalTableName),
+		this.migrationContext.AlterStatementOptions,
+	)
+}
+
 // readTableColumns reads table columns on applier
 func (this *Applier) readTableColumns() (err error) {
 	this.migrationContext.Log.Infof("Examining table structure on applier")
@@ -240,7 +308,10 @@ func (this *Applier) readTableColumns() (err error) {
 func (this *Applier) showTableStatus(databaseName, tableName string) (rowMap sqlutils.RowMap) {
 	query := fmt.Sprintf(`show /* gh-ost */ table status from %s like '%s'`, sql.EscapeName(databaseName), tableName)
 	sqlutils.QueryRowsMap(this.db, query, func(m sqlutils.RowMap) error {
-		rowMap = m
+		// LIKE may also match tables whose name differs by case, or by the '_' wildcard
+		if this.migrationContext.TableNamesEqual(m.GetString("Name"), tableName) {
+			rowMap = m
+		}
 		return nil
 	})
 	return rowMap
@@ -269,7 +340,7 @@ func (this *Applier) ValidateOrDropExistingTables() error {
 		}
 	}
 	if len(this.migrationContext.GetOldTableName()) > mysql.MaxTableNameLength {
-		this.migrationContext.Log.Fatalf("--timestamp-old-table defined, but resulting table name (%s) is too long (only %d characters allowed)", this.migrationContext.GetOldTableName(), mysql.MaxTableNameLength)
+		return fmt.Errorf("--timestamp-old-table defined, but resulting table name (%s) is too long (only %d characters allowed)", this.migrationContext.GetOldTableName(), mysql.MaxTableNameLength)
 	}
 
 	if this.tableExists(this.migrationContext.GetGhostDatabaseName(), this.migrationContext.GetOldTableName()) {
@@ -295,7 +366,20 @@ func (this *Applier) ValidateOrDropExistingTables() error {
 func (this *Applier) AttemptInstantDDL() error {
 	query := this.generateInstantDDLQuery()
 	this.migrationContext.Log.Infof("INSTANT DDL query is: %s", query)
+	return this.attemptDirectDDL(query)
+}
 
+// AttemptInplaceDDL attempts to apply the alter directly onto the original table with ALGORITHM=INPLACE
+// and LOCK=NONE. This is only attempted for operations predicted to only change the table's metadata,
+// such as dropping or renaming an index, which are then quick to run.
+func (this *Applier) AttemptInplaceDDL() error {
+	query := this.generateInplaceDDLQuery()
+	this.migrationContext.Log.Infof("INPLACE DDL query is: %s", query)
+	return this.attemptDirectDDL(query)
+}
+
+// attemptDirectDDL runs given alter directly onto the original table, bounding the wait on its metadata lock
+func (this *Applier) attemptDirectDDL(query string) error {
 	// Reuse cut-over-lock-timeout from regular migration process to reduce risk
 	// in situations where there may be long-running transactions.
 	tableLockTimeoutSeconds := this.migrationContext.CutOverLockTimeoutSeconds * 2
@@ -304,7 +388,7 @@ func (this *Applier) AttemptInstantDDL() error {
 	if _, err := this.db.Exec(lockTimeoutQuery); err != nil {
 		return err
 	}
-	// We don't need a trx, because for instant DDL the SQL mode doesn't matter.
+	// We don't need a trx, because for instant and metadata only DDL the SQL mode doesn't matter.
 	return retryOnLockWaitTimeout(func() error {
 		_, err := this.db.Exec(query)
 		return err
@@ -464,21 +548,30 @@ func (this *Applier) CreateChangelogTable() error {
 }
 
 // Create the checkpoint table to store the chunk copy and applier state.
-// There are two sets of columns with the same types as the shared unique key,
-// one for IterationMinValues and one for IterationMaxValues.
+// There are three sets of columns with the same types as the shared unique key,
+// one for IterationMinValues, one for IterationMaxValues and one for the exact
+// row count's progress.
+// checkpointTableColumnDefs define the checkpoint table's columns, ahead of the unique key columns.
+// Columns following gh_ost_is_cutover were added over time, see UpgradeCheckpointTable.
+var checkpointTableColumnDefs = []string{
+	"`gh_ost_chk_id` bigint auto_increment primary key",
+	"`gh_ost_chk_timestamp` bigint",
+	"`gh_ost_chk_coords` text charset ascii",
+	"`gh_ost_chk_iteration` bigint",
+	"`gh_ost_rows_copied` bigint",
+	"`gh_ost_dml_applied` bigint",
+	"`gh_ost_is_cutover` tinyint(1) DEFAULT '0'",
+	"`gh_ost_rows_counted` bigint",
+	"`gh_ost_is_count_complete` tinyint(1) DEFAULT '0'",
+	"`gh_ost_chk_heartbeat` varchar(64) charset ascii",
+	"`gh_ost_chk_server_uuid` varchar(64) charset ascii",
+}
+
 func (this *Applier) CreateCheckpointTable() error {
 	if err := this.DropCheckpointTable(); err != nil {
 		return err
 	}
-	colDefs := []string{
-		"`gh_ost_chk_id` bigint auto_increment primary key",
-		"`gh_ost_chk_timestamp` bigint",
-		"`gh_ost_chk_coords` text charset ascii",
-		"`gh_ost_chk_iteration` bigint",
-		"`gh_ost_rows_copied` bigint",
-		"`gh_ost_dml_applied` bigint",
-		"`gh_ost_is_cutover` tinyint(1) DEFAULT '0'",
-	}
+	colDefs := append([]string{}, checkpointTableColumnDefs...)
 	for _, col := range this.migrationContext.UniqueKey.Columns.Columns() {
 		if col.MySQLType == "" {
 			return fmt.Errorf("CreateCheckpoinTable: column %s has no type information. applyColumnTypes must be called", sql.EscapeName(col.Name))
@@ -494,6 +587,12 @@ func (this *Applier) CreateCheckpointTable() error {
 		colDefs = append(colDefs, colDef)
 	}
 
+	for _, col := range this.migrationContext.UniqueKey.Columns.Columns() {
+		countColName := sql.TruncateColumnName(col.Name, sql.MaxColumnNameLength-4) + "_cnt"
+		colDef := fmt.Sprintf("%s %s", sql.EscapeName(countColName), col.MySQLType)
+		colDefs = append(colDefs, colDef)
+	}
+
 	query := fmt.Sprintf("create /* gh-ost */ table %s.%s (\n %s\n)",
 		sql.EscapeName(this.migrationContext.DatabaseName),
 		sql.EscapeName(this.migrationContext.GetCheckpointTableName()),
@@ -667,11 +766,16 @@ func (this *Applier) WriteCheckpoint(chk *Checkpoint) (int64, error) {
 	var insertId int64
 	uniqueKeyArgs := sqlutils.Args(chk.IterationRangeMin.AbstractValues()...)
 	uniqueKeyArgs = append(uniqueKeyArgs, chk.IterationRangeMax.AbstractValues()...)
+	if chk.RowCountRangeMax != nil {
+		uniqueKeyArgs = append(uniqueKeyArgs, chk.RowCountRangeMax.AbstractValues()...)
+	} else {
+		uniqueKeyArgs = append(uniqueKeyArgs, make([]interface{}, this.migrationContext.UniqueKey.Columns.Len())...)
+	}
 	query, uniqueKeyArgs, err := this.checkpointInsertQueryBuilder.BuildQuery(uniqueKeyArgs)
 	if err != nil {
 		return insertId, err
 	}
-	args := sqlutils.Args(chk.LastTrxCoords.String(), chk.Iteration, chk.RowsCopied, chk.DMLApplied, chk.IsCutover)
+	args := sqlutils.Args(chk.LastTrxCoords.String(), chk.Iteration, chk.RowsCopied, chk.DMLApplied, chk.IsCutover, chk.RowsCounted, chk.IsCountComplete, chk.Heartbeat, chk.ServerUUID)
 	args = append(args, uniqueKeyArgs...)
 	res, err := this.db.Exec(query, args...)
 	if err != nil {
@@ -680,18 +784,75 @@ func (this *Applier) WriteCheckpoint(chk *Checkpoint) (int64, error) {
 	return res.LastInsertId()
 }
 
+// UpgradeCheckpointTable adds onto a checkpoint table created by an earlier gh-ost version the columns
+// it lacks, in place, so that checkpoints are read and written by their position. The added columns
+// are empty on existing checkpoints, which then resume as they would have with the earlier version.
+func (this *Applier) UpgradeCheckpointTable() error {
+	columnTypes := make(map[string]string)
+	query := `select /* gh-ost */ column_name as name, column_type as type from information_schema.columns where table_schema = ? and table_name = ?`
+	err := sqlutils.QueryRowsMap(this.db, query, func(m sqlutils.RowMap) error {
+		columnTypes[m.GetString("name")] = m.GetString("type")
+		return nil
+	}, this.migrationContext.DatabaseName, this.migrationContext.GetCheckpointTableName())
+	if err != nil {
+		return err
+	}
+	if len(columnTypes) == 0 {
+		return fmt.Errorf("Checkpoint table %s.%s not found", sql.EscapeName(this.migrationContext.DatabaseName), sql.EscapeName(this.migrationContext.GetCheckpointTableName()))
+	}
+
+	var addColumns []string
+	var previousColName string
+	for _, colDef := range checkpointTableColumnDefs {
+		colName := strings.Split(colDef, "`")[1]
+		if _, ok := columnTypes[colName]; !ok {
+			addColumns = append(addColumns, fmt.Sprintf("add column %s after %s", colDef, sql.EscapeName(previousColName)))
+		}
+		previousColName = colName
+	}
+	uniqueKeyColumns := this.migrationContext.UniqueKey.Columns.Columns()
+	previousColName = sql.TruncateColumnName(uniqueKeyColumns[len(uniqueKeyColumns)-1].Name, sql.MaxColumnNameLength-4) + "_max"
+	for _, col := range uniqueKeyColumns {
+		truncatedColName := sql.TruncateColumnName(col.Name, sql.MaxColumnNameLength-4)
+		countColName := truncatedColName + "_cnt"
+		if _, ok := columnTypes[countColName]; !ok {
+			addColumns = append(addColumns, fmt.Sprintf("add column %s %s after %s", sql.EscapeName(countColName), columnTypes[truncatedColName+"_min"], sql.EscapeName(previousColName)))
+		}
+		previousColName = countColName
+	}
+
+	for _, addColumn := range addColumns {
+		query := fmt.Sprintf("alter /* gh-ost */ table %s.%s %s",
+			sql.EscapeName(this.migrationContext.DatabaseName),
+			sql.EscapeName(this.migrationContext.GetCheckpointTableName()),
+			addColumn,
+		)
+		if _, err := sqlutils.ExecNoPrepare(this.db, query); err != nil {
+			return err
+		}
+	}
+	if len(addColumns) > 0 {
+		this.migrationContext.Log.Infof("Upgraded checkpoint table with %d columns", len(addColumns))
+	}
+	return nil
+}
+
 func (this *Applier) ReadLastCheckpoint() (*Checkpoint, error) {
 	row := this.db.QueryRow(fmt.Sprintf(`select /* gh-ost */ * from %s.%s order by gh_ost_chk_id desc limit 1`, sql.EscapeName(this.migrationContext.DatabaseName), sql.EscapeName(this.migrationContext.GetCheckpointTableName())))
 	chk := &Checkpoint{
 		IterationRangeMin: sql.NewColumnValues(this.migrationContext.UniqueKey.Columns.Len()),
 		IterationRangeMax: sql.NewColumnValues(this.migrationContext.UniqueKey.Columns.Len()),
+		RowCountRangeMax:  sql.NewColumnValues(this.migrationContext.UniqueKey.Columns.Len()),
 	}
 
 	var coordStr string
 	var timestamp int64
-	ptrs := []interface{}{&chk.Id, &timestamp, &coordStr, &chk.Iteration, &chk.RowsCopied, &chk.DMLApplied, &chk.IsCutover}
+	var rowsCounted gosql.NullInt64
+	var heartbeat, serverUUID gosql.NullString
+	ptrs := []interface{}{&chk.Id, &timestamp, &coordStr, &chk.Iteration, &chk.RowsCopied, &chk.DMLApplied, &chk.IsCutover, &rowsCounted, &chk.IsCountComplete, &heartbeat, &serverUUID}
 	ptrs = append(ptrs, chk.IterationRangeMin.ValuesPointers...)
 	ptrs = append(ptrs, chk.IterationRangeMax.ValuesPointers...)
+	ptrs = append(ptrs, chk.RowCountRangeMax.ValuesPointers...)
 	err := row.Scan(ptrs...)
 	if err != nil {
 		if errors.Is(err, gosql.ErrNoRows) {
@@ -700,6 +861,13 @@ func (this *Applier) ReadLastCheckpoint() (*Checkpoint, error) {
 		return nil, err
 	}
 	chk.Timestamp = time.Unix(timestamp, 0)
+	chk.RowsCounted = rowsCounted.Int64
+	chk.Heartbeat = heartbeat.String
+	chk.ServerUUID = serverUUID.String
+	if chk.RowCountRangeMax.AbstractValues()[0] == nil {
+		// row count had not begun
+		chk.RowCountRangeMax = nil
+	}
 	if this.migrationContext.UseGTIDs {
 		gtidCoords, err := mysql.NewGTIDBinlogCoordinates(coordStr)
 		if err != nil {
@@ -962,35 +1130,10 @@ func (this *Applier) ApplyIterationInsertQuery() (chunkSize int64, rowsAffected
 		}
 
 		if this.migrationContext.PanicOnWarnings {
-			//nolint:execinquery
-			rows, err := tx.Query("SHOW WARNINGS")
+			sqlWarnings, err := this.readRowCopySQLWarnings(tx)
 			if err != nil {
 				return nil, err
 			}
-			defer rows.Close()
-			if err = rows.Err(); err != nil {
-				return nil, err
-			}
-
-			// Compile regex once before loop to avoid performance penalty and handle errors properly
-			migrationKeyRegex, err := this.compileMigrationKeyWarningRegex()
-			if err != nil {
-				return nil, err
-			}
-
-			var sqlWarnings []string
-			for rows.Next() {
-				var level, message string
-				var code int
-				if err := rows.Scan(&level, &code, &message); err != nil {
-					this.migrationContext.Log.Warningf("Failed to read SHOW WARNINGS row")
-					continue
-				}
-				if strings.Contains(message, "Duplicate entry") && migrationKeyRegex.MatchString(message) {
-					continue
-				}
-				sqlWarnings = append(sqlWarnings, fmt.Sprintf("%s: %s (%d)", level, message, code))
-			}
 			this.migrationContext.MigrationLastInsertSQLWarnings = sqlWarnings
 		}
 
@@ -1014,6 +1157,110 @@ func (this *Applier) ApplyIterationInsertQuery() (chunkSize int64, rowsAffected
 	return chunkSize, rowsAffected, duration, nil
 }
 
+// ApplyIterationRowsInsertQuery writes the given rows, as read by the inspector for the current
+// iteration range, onto the ghost table via batched `insert ignore` statements. All batches
+// are written in a single transaction.
+func (this *Applier) ApplyIterationRowsInsertQuery(rows [][]interface{}) (rowsAffected int64, duration time.Duration, err error) {
+	startTime := time.Now()
+	if len(rows) == 0 {
+		return 0, time.Since(startTime), nil
+	}
+	tx, err := this.db.Begin()
+	if err != nil {
+		return rowsAffected, duration, err
+	}
+	defer tx.Rollback()
+
+	sessionQuery := fmt.Sprintf(`SET SESSION time_zone = '%s'`, this.migrationContext.ApplierTimeZone)
+	sessionQuery = fmt.Sprintf("%s, %s", sessionQuery, this.generateSqlModeQuery())
+	if _, err := tx.Exec(sessionQuery); err != nil {
+		return rowsAffected, duration, err
+	}
+
+	mappedSharedColumns := this.migrationContext.MappedSharedColumns.Names()
+	var sqlWarnings []string
+	for len(rows) > 0 {
+		batchSize := len(rows)
+		if batchSize > CopyFromReplicaInsertBatchSize {
+			batchSize = CopyFromReplicaInsertBatchSize
+		}
+		query, err := sql.BuildMultiRowInsertIgnorePreparedQuery(
+			this.migrationContext.GetGhostDatabaseName(),
+			this.migrationContext.GetGhostTableName(),
+			mappedSharedColumns,
+			batchSize,
+		)
+		if err != nil {
+			return rowsAffected, duration, err
+		}
+		args := make([]interface{}, 0, batchSize*len(mappedSharedColumns))
+		for _, row := range rows[:batchSize] {
+			args = append(args, row...)
+		}
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return rowsAffected, duration, err
+		}
+		if this.migrationContext.PanicOnWarnings {
+			batchSQLWarnings, err := this.readRowCopySQLWarnings(tx)
+			if err != nil {
+				return rowsAffected, duration, err
+			}
+			sqlWarnings = append(sqlWarnings, batchSQLWarnings...)
+		}
+		batchRowsAffected, _ := result.RowsAffected()
+		rowsAffected += batchRowsAffected
+		rows = rows[batchSize:]
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, duration, err
+	}
+	if this.migrationContext.PanicOnWarnings {
+		this.migrationContext.MigrationLastInsertSQLWarnings = sqlWarnings
+	}
+	duration = time.Since(startTime)
+	this.migrationContext.Log.Debugf(
+		"Issued INSERT of replica rows on range: [%s]..[%s]; iteration: %d",
+		this.migrationContext.MigrationIterationRangeMinValues,
+		this.migrationContext.MigrationIterationRangeMaxValues,
+		this.migrationContext.GetIteration())
+	return rowsAffected, duration, nil
+}
+
+// readRowCopySQLWarnings reads the warnings of the last row copy statement executed in given transaction.
+// Duplicate entry warnings on the migration key are expected of INSERT IGNORE, and are skipped.
+func (this *Applier) readRowCopySQLWarnings(tx *gosql.Tx) (sqlWarnings []string, err error) {
+	//nolint:execinquery
+	rows, err := tx.Query("SHOW WARNINGS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Compile regex once before loop to avoid performance penalty and handle errors properly
+	migrationKeyRegex, err := this.compileMigrationKeyWarningRegex()
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var level, message string
+		var code int
+		if err := rows.Scan(&level, &code, &message); err != nil {
+			this.migrationContext.Log.Warningf("Failed to read SHOW WARNINGS row")
+			continue
+		}
+		if strings.Contains(message, "Duplicate entry") && migrationKeyRegex.MatchString(message) {
+			continue
+		}
+		sqlWarnings = append(sqlWarnings, fmt.Sprintf("%s: %s (%d)", level, message, code))
+	}
+	return sqlWarnings, nil
+}
+
 // LockOriginalTable places a write lock on the original table
 func (this *Applier) LockOriginalTable() error {
 	query := fmt.Sprintf(`lock /* gh-ost */ tables %s.%s write`,
@@ -1123,7 +1370,7 @@ func (this *Applier) RenameTablesRollback() (renameError error) {
 // and have them written to the binary log, so that we can then read them via streamer.
 func (this *Applier) StopSlaveIOThread() error {
 	replicaTerm := mysql.ReplicaTermFor(this.migrationContext.ApplierMySQLVersion, `slave`)
-	query := fmt.Sprintf("stop /* gh-ost */ %s io_thread", replicaTerm)
+	query := fmt.Sprintf("stop /* gh-ost */ %s io_thread%s", replicaTerm, mysql.ReplicationChannelClause(this.migrationContext.ReplicationChannel))
 	this.migrationContext.Log.Infof("Stopping replication IO thread")
 	if _, err := sqlutils.ExecNoPrepare(this.db, query); err != nil {
 		return err
@@ -1135,7 +1382,7 @@ func (this *Applier) StopSlaveIOThread() error {
 // StartSlaveIOThread is applicable with --test-on-replica
 func (this *Applier) StartSlaveIOThread() error {
 	replicaTerm := mysql.ReplicaTermFor(this.migrationContext.ApplierMySQLVersion, `slave`)
-	query := fmt.Sprintf("start /* gh-ost */ %s io_thread", replicaTerm)
+	query := fmt.Sprintf("start /* gh-ost */ %s io_thread%s", replicaTerm, mysql.ReplicationChannelClause(this.migrationContext.ReplicationChannel))
 	this.migrationContext.Log.Infof("Starting replication IO thread")
 	if _, err := sqlutils.ExecNoPrepare(this.db, query); err != nil {
 		return err
@@ -1147,7 +1394,7 @@ func (this *Applier) StartSlaveIOThread() error {
 // StopSlaveSQLThread is applicable with --test-on-replica
 func (this *Applier) StopSlaveSQLThread() error {
 	replicaTerm := mysql.ReplicaTermFor(this.migrationContext.ApplierMySQLVersion, `slave`)
-	query := fmt.Sprintf("stop /* gh-ost */ %s sql_thread", replicaTerm)
+	query := fmt.Sprintf("stop /* gh-ost */ %s sql_thread%s", replicaTerm, mysql.ReplicationChannelClause(this.migrationContext.ReplicationChannel))
 	this.migrationContext.Log.Infof("Verifying SQL thread is stopped")
 	if _, err := sqlutils.ExecNoPrepare(this.db, query); err != nil {
 		return err
@@ -1159,7 +1406,7 @@ func (this *Applier) StopSlaveSQLThread() error {
 // StartSlaveSQLThread is applicable with --test-on-replica
 func (this *Applier) StartSlaveSQLThread() error {
 	replicaTerm := mysql.ReplicaTermFor(this.migrationContext.ApplierMySQLVersion, `slave`)
-	query := fmt.Sprintf("start /* gh-ost */ %s sql_thread", replicaTerm)
+	query := fmt.Sprintf("start /* gh-ost */ %s sql_thread%s", replicaTerm, mysql.ReplicationChannelClause(this.migrationContext.ReplicationChannel))
 	this.migrationContext.Log.Infof("Verifying SQL thread is running")
 	if _, err := sqlutils.ExecNoPrepare(this.db, query); err != nil {
 		return err
@@ -1177,7 +1424,7 @@ func (this *Applier) StopReplication() error {
 		return err
 	}
 
-	readBinlogCoordinates, executeBinlogCoordinates, err := mysql.GetReplicationBinlogCoordinates(this.migrationContext.ApplierMySQLVersion, this.db, this.migrationContext.UseGTIDs)
+	readBinlogCoordinates, executeBinlogCoordinates, err := mysql.GetReplicationBinlogCoordinates(this.migrationContext.ApplierMySQLVersion, this.db, this.migrationContext.UseGTIDs, this.migrationContext.ReplicationChannel)
 	if err != nil {
 		return err
 	}
@@ -1471,6 +1718,20 @@ func (this *Applier) AtomicCutoverRename(sessionIdChan chan int64, tablesRenamed
 	return nil
 }
 
+// WaitForExecutedGTIDs waits for the applier to have executed given GTID coordinates, as
+// replicated from elsewhere
+func (this *Applier) WaitForExecutedGTIDs(coords mysql.BinlogCoordinates, timeoutSeconds int64) error {
+	var timedOut int64
+	query := `select /* gh-ost */ wait_for_executed_gtid_set(?, ?)`
+	if err := this.db.QueryRow(query, coords.String(), timeoutSeconds).Scan(&timedOut); err != nil {
+		return err
+	}
+	if timedOut != 0 {
+		return fmt.Errorf("Timeout of %d seconds waiting for %s to execute %s", timeoutSeconds, this.connectionConfig.Key.String(), coords.String())
+	}
+	return nil
+}
+
 func (this *Applier) ShowStatusVariable(variableName string) (result int64, err error) {
 	query := fmt.Sprintf(`show /* gh-ost */ global status like '%s'`, variableName)
 	if err := this.db.QueryRow(query).Scan(&variableName, &result); err != nil {
@@ -1498,6 +1759,12 @@ func (this *Applier) updateModifiesUniqueKeyColumns(dmlEvent *binlog.BinlogDMLEv
 // buildDMLEventQuery creates a query to operate on the ghost table, based on an intercepted binlog
 // event entry on the original table.
 func (this *Applier) buildDMLEventQuery(dmlEvent *binlog.BinlogDMLEvent) []*dmlBuildResult {
+	if this.migrationContext.UniqueKey.IsNonUnique {
+		return this.buildRowImageDMLEventQuery(dmlEvent)
+	}
+	if isPartialRowImage(dmlEvent) {
+		return this.buildPartialRowImageDMLEventQuery(dmlEvent)
+	}
 	switch dmlEvent.DML {
 	case binlog.DeleteDML:
 		{
@@ -1511,7 +1778,9 @@ func (this *Applier) buildDMLEventQuery(dmlEvent *binlog.BinlogDMLEvent) []*dmlB
 		}
 	case binlog.UpdateDML:
 		{
-			if _, isModified := this.updateModifiesUniqueKeyColumns(dmlEvent); isModified {
+			if _, isModified := this.updateModifiesUniqueKeyColumns(dmlEvent); isModified && this.migrationContext.MigrationKeyUpdatedInPlace {
+				return this.buildInPlaceKeyUpdateQueries(dmlEvent)
+			} else if isModified {
 				results := make([]*dmlBuildResult, 0, 2)
 				dmlEvent.DML = binlog.DeleteDML
 				results = append(results, this.buildDMLEventQuery(dmlEvent)...)
@@ -1528,6 +1797,290 @@ func (this *Applier) buildDMLEventQuery(dmlEvent *binlog.BinlogDMLEvent) []*dmlB
 	return []*dmlBuildResult{newDmlBuildResultError(fmt.Errorf("Unknown dml event type: %+v", dmlEvent.DML))}
 }
 
+// buildInPlaceKeyUpdateQueries creates the queries for an UPDATE event modifying the migration key, when
+// the ghost table's primary key has columns of its own (see MigrationKeyUpdatedInPlace). A delete and
+// re-insert would assign the row new values for these columns. Instead, the row is updated in place, then
+// inserted in case it was not yet copied onto the ghost table. When it was, the insert is ignored as a
+// duplicate on the migration key.
+func (this *Applier) buildInPlaceKeyUpdateQueries(dmlEvent *binlog.BinlogDMLEvent) []*dmlBuildResult {
+	updateQuery, updateArgs, err := this.dmlUpdateQueryBuilder.BuildQuery(dmlEvent.NewColumnValues.AbstractValues(), dmlEvent.WhereColumnValues.AbstractValues())
+	if err != nil {
+		return []*dmlBuildResult{newDmlBuildResultError(err)}
+	}
+	insertQuery, insertArgs, err := this.dmlInsertQueryBuilder.BuildQuery(dmlEvent.NewColumnValues.AbstractValues())
+	return []*dmlBuildResult{
+		newDmlBuildResult(updateQuery, updateArgs, 0, nil),
+		newDmlBuildResult(insertQuery, insertArgs, 0, err),
+	}
+}
+
+// isPartialRowImage checks whether any of a DML event's row images lacks columns, as with binlog_row_image
+// MINIMAL or NOBLOB
+func isPartialRowImage(dmlEvent *binlog.BinlogDMLEvent) bool {
+	return (dmlEvent.WhereColumnValues != nil && dmlEvent.WhereColumnValues.IsPartial()) ||
+		(dmlEvent.NewColumnValues != nil && dmlEvent.NewColumnValues.IsPartial())
+}
+
+// hasMigrationKeyColumns checks whether a row image has the values of all of the migration key's columns
+func (this *Applier) hasMigrationKeyColumns(columnValues *sql.ColumnValues) bool {
+	for _, column := range this.migrationContext.UniqueKey.Columns.Columns() {
+		if !columnValues.IsPresent(this.migrationContext.OriginalTableColumns.Ordinals[column.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// buildPartialRowImageDMLEventQuery creates the queries for a DML event with partial row images, as logged
+// with binlog_row_image MINIMAL or NOBLOB. Before images must have the migration key's columns, and inserts
+// must have full images. An update sets the columns present in its after image. An update modifying the
+// migration key deletes the row, then copies it anew from the original table, as its after image may lack
+// columns to insert. With MigrationKeyUpdatedInPlace, the row is instead updated in place, then copied in
+// case it was not yet copied onto the ghost table.
+func (this *Applier) buildPartialRowImageDMLEventQuery(dmlEvent *binlog.BinlogDMLEvent) []*dmlBuildResult {
+	if dmlEvent.DML == binlog.InsertDML {
+		return []*dmlBuildResult{newDmlBuildResultError(fmt.Errorf("Insert event on %s has a partial row image, and cannot be applied. With binlog_row_image=%s, inserts must specify all columns", sql.EscapeName(this.migrationContext.OriginalTableName), this.migrationContext.OriginalBinlogRowImage))}
+	}
+	if !this.hasMigrationKeyColumns(dmlEvent.WhereColumnValues) {
+		return []*dmlBuildResult{newDmlBuildResultError(fmt.Errorf("%s event on %s lacks columns of migration key %s in its before image, and cannot be applied", dmlEvent.DML, sql.EscapeName(this.migrationContext.OriginalTableName), this.migrationContext.UniqueKey.Name))}
+	}
+	whereArgs := dmlEvent.WhereColumnValues.AbstractValues()
+	if dmlEvent.DML == binlog.DeleteDML {
+		query, uniqueKeyArgs, err := this.dmlDeleteQueryBuilder.BuildQuery(whereArgs)
+		return []*dmlBuildResult{newDmlBuildResult(query, uniqueKeyArgs, -1, err)}
+	}
+
+	newArgs := dmlEvent.NewColumnValues.AbstractValues()
+	uniqueKeyArgs := make([]interface{}, 0, this.migrationContext.UniqueKey.Len())
+	isKeyModified := false
+	for _, column := range this.migrationContext.UniqueKey.Columns.Columns() {
+		tableOrdinal := this.migrationContext.OriginalTableColumns.Ordinals[column.Name]
+		if dmlEvent.NewColumnValues.IsPresent(tableOrdinal) && !reflect.DeepEqual(whereArgs[tableOrdinal], newArgs[tableOrdinal]) {
+			isKeyModified = true
+			uniqueKeyArgs = append(uniqueKeyArgs, newArgs[tableOrdinal])
+		} else {
+			uniqueKeyArgs = append(uniqueKeyArgs, whereArgs[tableOrdinal])
+		}
+	}
+	if isKeyModified {
+		copyQuery, copyArgs, err := sql.BuildRangeInsertPreparedQuery(
+			this.migrationContext.DatabaseName,
+			this.migrationContext.OriginalTableName,
+			this.migrationContext.GetGhostDatabaseName(),
+			this.migrationContext.GetGhostTableName(),
+			this.migrationContext.SharedColumns.Names(),
+			this.migrationContext.MappedSharedColumns.Names(),
+			this.migrationContext.UniqueKey.Name,
+			&this.migrationContext.UniqueKey.Columns,
+			uniqueKeyArgs,
+			uniqueKeyArgs,
+			true,
+			this.migrationContext.IsTransactionalTable(),
+			false,
+		)
+		if err != nil {
+			return []*dmlBuildResult{newDmlBuildResultError(err)}
+		}
+		if this.migrationContext.MigrationKeyUpdatedInPlace {
+			updateQuery, updateArgs, err := this.dmlUpdateQueryBuilder.BuildPartialQuery(newArgs, whereArgs, dmlEvent.NewColumnValues.IsPresent)
+			return []*dmlBuildResult{
+				newDmlBuildResult(updateQuery, updateArgs, 0, err),
+				newDmlBuildResult(copyQuery, copyArgs, 0, nil),
+			}
+		}
+		deleteQuery, deleteArgs, err := this.dmlDeleteQueryBuilder.BuildQuery(whereArgs)
+		return []*dmlBuildResult{
+			newDmlBuildResult(deleteQuery, deleteArgs, -1, err),
+			newDmlBuildResult(copyQuery, copyArgs, 1, nil),
+		}
+	}
+	query, updateArgs, err := this.dmlUpdateQueryBuilder.BuildPartialQuery(newArgs, whereArgs, dmlEvent.NewColumnValues.IsPresent)
+	if err == nil && query == "" {
+		// none of the shared columns changed
+		return nil
+	}
+	return []*dmlBuildResult{newDmlBuildResult(query, updateArgs, 0, err)}
+}
+
+// buildDMLEventQueries creates the queries to apply given events onto the ghost table. Consecutive
+// inserts, and consecutive deletes, are merged into multi-row statements of up to --max-dml-statement-size
+// estimated bytes. Events are not merged when migrating by a non-unique key, nor are inserts with
+// PanicOnWarnings.
+func (this *Applier) buildDMLEventQueries(dmlEvents []*binlog.BinlogDMLEvent) ([]*dmlBuildResult, error) {
+	buildResults := make([]*dmlBuildResult, 0, len(dmlEvents))
+	maxStatementSize := this.migrationContext.MaxDMLStatementSize
+
+	var runDML binlog.EventDML
+	var runRowsArgs [][]interface{}
+	var runSize int64
+	flushRun := func() error {
+		if len(runRowsArgs) == 0 {
+			return nil
+		}
+		buildResult := this.buildMultiRowDMLEventQuery(runDML, runRowsArgs)
+		runRowsArgs = nil
+		runSize = 0
+		if buildResult.err != nil {
+			return buildResult.err
+		}
+		buildResults = append(buildResults, buildResult)
+		return nil
+	}
+
+	for _, dmlEvent := range dmlEvents {
+		var rowArgs []interface{}
+		mergeable := maxStatementSize > 0 && !this.migrationContext.UniqueKey.IsNonUnique
+		switch dmlEvent.DML {
+		case binlog.InsertDML:
+			// SHOW WARNINGS lists up to max_error_count warnings per statement, which a multi-row insert ignore may
+			// exceed with duplicates on the migration key, hiding warnings PanicOnWarnings must catch
+			rowArgs = dmlEvent.NewColumnValues.AbstractValues()
+			mergeable = mergeable && !dmlEvent.NewColumnValues.IsPartial() && !this.migrationContext.PanicOnWarnings
+		case binlog.DeleteDML:
+			rowArgs = dmlEvent.WhereColumnValues.AbstractValues()
+			mergeable = mergeable && this.hasMigrationKeyColumns(dmlEvent.WhereColumnValues)
+		default:
+			mergeable = false
+		}
+		if !mergeable {
+			if err := flushRun(); err != nil {
+				return nil, err
+			}
+			for _, buildResult := range this.buildDMLEventQuery(dmlEvent) {
+				if buildResult.err != nil {
+					return nil, buildResult.err
+				}
+				buildResults = append(buildResults, buildResult)
+			}
+			continue
+		}
+		rowSize := estimatedArgsSize(rowArgs)
+		if len(runRowsArgs) > 0 && (dmlEvent.DML != runDML || runSize+rowSize > maxStatementSize) {
+			if err := flushRun(); err != nil {
+				return nil, err
+			}
+		}
+		runDML = dmlEvent.DML
+		runRowsArgs = append(runRowsArgs, rowArgs)
+		runSize += rowSize
+	}
+	if err := flushRun(); err != nil {
+		return nil, err
+	}
+	return buildResults, nil
+}
+
+// buildMultiRowDMLEventQuery creates a single query applying a run of insert events, or a run of delete
+// events, given by their row arguments.
+func (this *Applier) buildMultiRowDMLEventQuery(dml binlog.EventDML, rowsArgs [][]interface{}) *dmlBuildResult {
+	var query string
+	var args []interface{}
+	var err error
+	var rowsDelta int64
+	switch dml {
+	case binlog.InsertDML:
+		rowsDelta = 1
+		if len(rowsArgs) == 1 {
+			query, args, err = this.dmlInsertQueryBuilder.BuildQuery(rowsArgs[0])
+		} else {
+			query, args, err = this.dmlInsertQueryBuilder.BuildMultiRowQuery(rowsArgs)
+		}
+	case binlog.DeleteDML:
+		rowsDelta = -1
+		if len(rowsArgs) == 1 {
+			query, args, err = this.dmlDeleteQueryBuilder.BuildQuery(rowsArgs[0])
+		} else {
+			query, args, err = this.dmlDeleteQueryBuilder.BuildMultiRowQuery(rowsArgs)
+		}
+	default:
+		return newDmlBuildResultError(fmt.Errorf("Unexpected dml event type for a multi-row query: %+v", dml))
+	}
+	buildResult := newDmlBuildResult(query, args, rowsDelta, err)
+	buildResult.rowsCount = int64(len(rowsArgs))
+	return buildResult
+}
+
+// estimatedArgsSize estimates the size of given arguments once interpolated into a statement. Strings
+// are assumed to be fully escaped.
+func estimatedArgsSize(args []interface{}) (size int64) {
+	for _, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			size += int64(2*len(arg) + 2)
+		case []byte:
+			size += int64(2*len(arg) + 9)
+		default:
+			size += 24
+		}
+	}
+	return size
+}
+
+// uncopiedRangeExclusion returns a condition which holds when the given row's key is outside the part
+// of the migration range which is yet to be copied. It is empty when there is no such part.
+func (this *Applier) uncopiedRangeExclusion(rowArgs []interface{}) (condition string, conditionArgs []interface{}, err error) {
+	if atomic.LoadInt64(&this.RowCopyRangeExhaustedFlag) > 0 || !this.migrationContext.HasMigrationRange() {
+		return "", nil, nil
+	}
+	rangeStartValues := this.migrationContext.MigrationRangeMinValues
+	includeRangeStartValues := true
+	this.LastIterationRangeMutex.Lock()
+	if this.LastIterationRangeMaxValues != nil {
+		rangeStartValues = this.LastIterationRangeMaxValues.Clone()
+		includeRangeStartValues = false
+	}
+	this.LastIterationRangeMutex.Unlock()
+
+	condition, err = sql.BuildRangeExclusionPreparedClause(&this.migrationContext.UniqueKey.Columns, includeRangeStartValues)
+	if err != nil {
+		return "", nil, err
+	}
+	conditionArgs, err = sql.BuildRangeExclusionArgs(
+		this.migrationContext.OriginalTableColumns,
+		&this.migrationContext.UniqueKey.Columns,
+		rowArgs,
+		rangeStartValues.AbstractValues(),
+		this.migrationContext.MigrationRangeMaxValues.AbstractValues(),
+	)
+	return condition, conditionArgs, err
+}
+
+// buildRowImageDMLEventQuery creates queries to operate on the ghost table when migrating by a
+// non-unique key. Rows are identified by their full image, and an update is applied as a delete of
+// its before image followed by an insert of its after image. Changes to rows whose key is in the
+// part of the migration range yet to be copied are not applied: row copy reads chunks consistently
+// with the binlog coordinates up to which events were applied, and so will include these changes.
+func (this *Applier) buildRowImageDMLEventQuery(dmlEvent *binlog.BinlogDMLEvent) []*dmlBuildResult {
+	buildDelete := func(rowArgs []interface{}) *dmlBuildResult {
+		condition, conditionArgs, err := this.uncopiedRangeExclusion(rowArgs)
+		if err != nil {
+			return newDmlBuildResultError(err)
+		}
+		query, args, err := this.dmlRowImageDeleteQueryBuilder.BuildQuery(rowArgs, condition, conditionArgs)
+		return newDmlBuildResult(query, args, -1, err)
+	}
+	buildInsert := func(rowArgs []interface{}) *dmlBuildResult {
+		condition, conditionArgs, err := this.uncopiedRangeExclusion(rowArgs)
+		if err != nil {
+			return newDmlBuildResultError(err)
+		}
+		query, args, err := this.dmlRowImageInsertQueryBuilder.BuildQuery(rowArgs, condition, conditionArgs)
+		return newDmlBuildResult(query, args, 1, err)
+	}
+	switch dmlEvent.DML {
+	case binlog.DeleteDML:
+		return []*dmlBuildResult{buildDelete(dmlEvent.WhereColumnValues.AbstractValues())}
+	case binlog.InsertDML:
+		return []*dmlBuildResult{buildInsert(dmlEvent.NewColumnValues.AbstractValues())}
+	case binlog.UpdateDML:
+		return []*dmlBuildResult{
+			buildDelete(dmlEvent.WhereColumnValues.AbstractValues()),
+			buildInsert(dmlEvent.NewColumnValues.AbstractValues()),
+		}
+	}
+	return []*dmlBuildResult{newDmlBuildResultError(fmt.Errorf("Unknown dml event type: %+v", dmlEvent.DML))}
+}
+
 // executeBatchWithWarningChecking executes a batch of DML statements with SHOW WARNINGS
 // interleaved after each statement to detect warnings from any statement in the batch.
 // This is used when PanicOnWarnings is enabled to ensure warnings from middle statements
@@ -1580,9 +2133,9 @@ func (this *Applier) executeBatchWithWarningChecking(ctx context.Context, tx *go
 	// Pattern: [at SHOW WARNINGS #1] -> read warnings -> NextResultSet() -> [at SHOW WARNINGS #2] -> ...
 	for i := 0; i < len(buildResults); i++ {
 		// We can't get exact rows affected with QueryContext (needed for reading SHOW WARNINGS).
-		// Use the theoretical delta (+1 for INSERT, -1 for DELETE, 0 for UPDATE) as an approximation.
+		// Use the theoretical delta (+1 per INSERT row, -1 per DELETE row, 0 for UPDATE) as an approximation.
 		// This may be inaccurate (e.g., INSERT IGNORE with duplicate affects 0 rows but we count +1).
-		totalDelta += buildResults[i].rowsDelta
+		totalDelta += buildResults[i].rowsDelta * buildResults[i].rowsCount
 
 		// Read warnings from this statement's SHOW WARNINGS result set
 		var sqlWarnings []string
@@ -1634,6 +2187,12 @@ func (this *Applier) ApplyDMLEventQueries(dmlEvents [](*binlog.BinlogDMLEvent))
 	var totalDelta int64
 	ctx := context.Background()
 
+	appliedDMLEvents := dmlEvents
+	coalescedCount := 0
+	if this.migrationContext.CoalesceDMLEvents && len(dmlEvents) > 1 {
+		appliedDMLEvents, coalescedCount = coalesceDMLEvents(dmlEvents, &this.migrationContext.UniqueKey.Columns, this.migrationContext.OriginalTableColumns)
+	}
+
 	err := func() error {
 		conn, err := this.db.Conn(ctx)
 		if err != nil {
@@ -1656,22 +2215,21 @@ func (this *Applier) ApplyDMLEventQueries(dmlEvents [](*binlog.BinlogDMLEvent))
 			return err
 		}
 
-		buildResults := make([]*dmlBuildResult, 0, len(dmlEvents))
+		buildResults, err := this.buildDMLEventQueries(appliedDMLEvents)
+		if err != nil {
+			return rollback(err)
+		}
 		nArgs := 0
-		for _, dmlEvent := range dmlEvents {
-			for _, buildResult := range this.buildDMLEventQuery(dmlEvent) {
-				if buildResult.err != nil {
-					return rollback(buildResult.err)
-				}
-				nArgs += len(buildResult.args)
-				buildResults = append(buildResults, buildResult)
-			}
+		for _, buildResult := range buildResults {
+			nArgs += len(buildResult.args)
 		}
 
 		// When PanicOnWarnings is enabled, we need to check warnings after each statement
 		// in the batch. SHOW WARNINGS only shows warnings from the last statement in a
 		// multi-statement query, so we interleave SHOW WARNINGS after each DML statement.
-		if this.migrationContext.PanicOnWarnings {
+		if len(buildResults) == 0 {
+			// Nothing to apply, e.g. partial row image updates which change no shared column
+		} else if this.migrationContext.PanicOnWarnings {
 			totalDelta, err = this.executeBatchWithWarningChecking(ctx, tx, buildResults)
 			if err != nil {
 				return rollback(err)
@@ -1704,8 +2262,8 @@ func (this *Applier) ApplyDMLEventQueries(dmlEvents [](*binlog.BinlogDMLEvent))
 
 				mysqlRes := res.(drivermysql.Result)
 
-				// each DML is either a single insert (delta +1), update (delta +0) or delete (delta -1).
-				// multiplying by the rows actually affected (either 0 or 1) will give an accurate row delta for this DML event
+				// each DML is either an insert (delta +1 per row), update (delta +0) or delete (delta -1 per row).
+				// multiplying by the rows actually affected will give an accurate row delta for this DML statement
 				for i, rowsAffected := range mysqlRes.AllRowsAffected() {
 					totalDelta += buildResults[i].rowsDelta * rowsAffected
 				}
@@ -1728,10 +2286,11 @@ func (this *Applier) ApplyDMLEventQueries(dmlEvents [](*binlog.BinlogDMLEvent))
 	}
 	// no error
 	atomic.AddInt64(&this.migrationContext.TotalDMLEventsApplied, int64(len(dmlEvents)))
+	atomic.AddInt64(&this.migrationContext.TotalDMLEventsCoalesced, int64(coalescedCount))
 	if this.migrationContext.CountTableRows {
 		atomic.AddInt64(&this.migrationContext.RowsDeltaEstimate, totalDelta)
 	}
-	this.migrationContext.Log.Debugf("ApplyDMLEventQueries() applied %d events in one transaction", len(dmlEvents))
+	this.migrationContext.Log.Debugf("ApplyDMLEventQueries() applied %d events in one transaction, %d of which coalesced", len(dmlEvents), coalescedCount)
 	return nil
 }
 
@@ -10,6 +10,7 @@ import (
 	gosql "database/sql"
 	"errors"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -188,6 +189,338 @@ func TestApplierBuildDMLEventQuery(t *testing.T) {
 	})
 }
 
+func TestApplierBuildDMLEventQueryMigrationKeyUpdate(t *testing.T) {
+	columns := sql.NewColumnList([]string{"a", "b", "v"})
+
+	migrationContext := base.NewMigrationContext()
+	migrationContext.DatabaseName = "test"
+	migrationContext.OriginalTableName = "test"
+	migrationContext.OriginalTableColumns = columns
+	migrationContext.SharedColumns = columns
+	migrationContext.MappedSharedColumns = columns
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:    "PRIMARY",
+		Columns: *sql.NewColumnList([]string{"a", "b"}),
+	}
+
+	applier := NewApplier(migrationContext)
+	require.NoError(t, applier.prepareQueries())
+
+	updateEvent := func() *binlog.BinlogDMLEvent {
+		return &binlog.BinlogDMLEvent{
+			DatabaseName:      "test",
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: sql.ToColumnValues([]interface{}{1, 2, "x"}),
+			NewColumnValues:   sql.ToColumnValues([]interface{}{1, 3, "x"}),
+		}
+	}
+
+	t.Run("delete and insert", func(t *testing.T) {
+		res := applier.buildDMLEventQuery(updateEvent())
+		require.Len(t, res, 2)
+		require.NoError(t, res[0].err)
+		require.NoError(t, res[1].err)
+		require.Contains(t, res[0].query, "delete /* gh-ost")
+		require.Equal(t, []interface{}{1, 2}, res[0].args)
+		require.Equal(t, int64(-1), res[0].rowsDelta)
+		require.Contains(t, res[1].query, "insert /* gh-ost")
+		require.Equal(t, []interface{}{1, 3, "x"}, res[1].args)
+		require.Equal(t, int64(1), res[1].rowsDelta)
+	})
+
+	t.Run("in place", func(t *testing.T) {
+		migrationContext.MigrationKeyUpdatedInPlace = true
+		defer func() { migrationContext.MigrationKeyUpdatedInPlace = false }()
+
+		res := applier.buildDMLEventQuery(updateEvent())
+		require.Len(t, res, 2)
+		require.NoError(t, res[0].err)
+		require.NoError(t, res[1].err)
+		require.Contains(t, res[0].query, "update /* gh-ost")
+		require.Equal(t, []interface{}{1, 3, "x", 1, 2}, res[0].args)
+		require.Equal(t, int64(0), res[0].rowsDelta)
+		require.Contains(t, res[1].query, "insert /* gh-ost")
+		require.Equal(t, []interface{}{1, 3, "x"}, res[1].args)
+		require.Equal(t, int64(0), res[1].rowsDelta)
+	})
+}
+
+func TestApplierBuildPartialRowImageDMLEventQuery(t *testing.T) {
+	columns := sql.NewColumnList([]string{"id", "item_id", "item_text"})
+
+	migrationContext := base.NewMigrationContext()
+	migrationContext.DatabaseName = "test"
+	migrationContext.OriginalTableName = "test"
+	migrationContext.OriginalBinlogRowImage = "MINIMAL"
+	migrationContext.OriginalTableColumns = columns
+	migrationContext.SharedColumns = columns
+	migrationContext.MappedSharedColumns = columns
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:    "PRIMARY",
+		Columns: *sql.NewColumnList([]string{"id"}),
+	}
+
+	applier := NewApplier(migrationContext)
+	require.NoError(t, applier.prepareQueries())
+
+	t.Run("insert", func(t *testing.T) {
+		res := applier.buildDMLEventQuery(&binlog.BinlogDMLEvent{
+			DML:             binlog.InsertDML,
+			NewColumnValues: sql.ToPartialColumnValues([]interface{}{1, 42, nil}, []int{2}),
+		})
+		require.Len(t, res, 1)
+		require.ErrorContains(t, res[0].err, "partial row image")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		res := applier.buildDMLEventQuery(&binlog.BinlogDMLEvent{
+			DML:               binlog.DeleteDML,
+			WhereColumnValues: sql.ToPartialColumnValues([]interface{}{1, nil, nil}, []int{1, 2}),
+		})
+		require.Len(t, res, 1)
+		require.NoError(t, res[0].err)
+		require.Contains(t, res[0].query, "delete /* gh-ost")
+		require.Equal(t, []interface{}{1}, res[0].args)
+		require.Equal(t, int64(-1), res[0].rowsDelta)
+	})
+
+	t.Run("delete without key", func(t *testing.T) {
+		res := applier.buildDMLEventQuery(&binlog.BinlogDMLEvent{
+			DML:               binlog.DeleteDML,
+			WhereColumnValues: sql.ToPartialColumnValues([]interface{}{nil, 42, nil}, []int{0, 2}),
+		})
+		require.Len(t, res, 1)
+		require.ErrorContains(t, res[0].err, "lacks columns of migration key")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		res := applier.buildDMLEventQuery(&binlog.BinlogDMLEvent{
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: sql.ToPartialColumnValues([]interface{}{1, nil, nil}, []int{1, 2}),
+			NewColumnValues:   sql.ToPartialColumnValues([]interface{}{nil, 43, nil}, []int{0, 2}),
+		})
+		require.Len(t, res, 1)
+		require.NoError(t, res[0].err)
+		require.Contains(t, res[0].query, "update /* gh-ost")
+		require.Contains(t, res[0].query, "`item_id`=?\n")
+		require.NotContains(t, res[0].query, "`item_text`=?")
+		require.Equal(t, []interface{}{43, 1}, res[0].args)
+		require.Equal(t, int64(0), res[0].rowsDelta)
+	})
+
+	t.Run("update of migration key", func(t *testing.T) {
+		res := applier.buildDMLEventQuery(&binlog.BinlogDMLEvent{
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: sql.ToPartialColumnValues([]interface{}{1, nil, nil}, []int{1, 2}),
+			NewColumnValues:   sql.ToPartialColumnValues([]interface{}{2, nil, nil}, []int{1, 2}),
+		})
+		require.Len(t, res, 2)
+		require.NoError(t, res[0].err)
+		require.NoError(t, res[1].err)
+		require.Contains(t, res[0].query, "delete /* gh-ost")
+		require.Equal(t, []interface{}{1}, res[0].args)
+		require.Contains(t, res[1].query, "insert /* gh-ost `test`.`test` */ ignore")
+		require.Contains(t, res[1].query, "select `id`, `item_id`, `item_text`")
+		require.Equal(t, []interface{}{2, 2, 2, 2}, res[1].args)
+		require.Equal(t, int64(1), res[1].rowsDelta)
+	})
+
+	t.Run("update of migration key in place", func(t *testing.T) {
+		migrationContext.MigrationKeyUpdatedInPlace = true
+		defer func() { migrationContext.MigrationKeyUpdatedInPlace = false }()
+
+		res := applier.buildDMLEventQuery(&binlog.BinlogDMLEvent{
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: sql.ToPartialColumnValues([]interface{}{1, nil, nil}, []int{1, 2}),
+			NewColumnValues:   sql.ToPartialColumnValues([]interface{}{2, nil, nil}, []int{1, 2}),
+		})
+		require.Len(t, res, 2)
+		require.NoError(t, res[0].err)
+		require.NoError(t, res[1].err)
+		require.Contains(t, res[0].query, "update /* gh-ost")
+		require.Contains(t, res[0].query, "`id`=?\n")
+		require.NotContains(t, res[0].query, "`item_id`=?")
+		require.Equal(t, []interface{}{2, 1}, res[0].args)
+		require.Equal(t, int64(0), res[0].rowsDelta)
+		require.Contains(t, res[1].query, "insert /* gh-ost `test`.`test` */ ignore")
+		require.Equal(t, []interface{}{2, 2, 2, 2}, res[1].args)
+		require.Equal(t, int64(0), res[1].rowsDelta)
+	})
+
+	t.Run("update of unshared columns", func(t *testing.T) {
+		migrationContext.SharedColumns = sql.NewColumnList([]string{"id", "item_id"})
+		migrationContext.MappedSharedColumns = migrationContext.SharedColumns
+		defer func() {
+			migrationContext.SharedColumns = columns
+			migrationContext.MappedSharedColumns = columns
+			require.NoError(t, applier.prepareQueries())
+		}()
+		require.NoError(t, applier.prepareQueries())
+
+		res := applier.buildDMLEventQuery(&binlog.BinlogDMLEvent{
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: sql.ToPartialColumnValues([]interface{}{1, nil, nil}, []int{1, 2}),
+			NewColumnValues:   sql.ToPartialColumnValues([]interface{}{nil, nil, "text"}, []int{0, 1}),
+		})
+		require.Empty(t, res)
+	})
+}
+
+func TestApplierBuildRowImageDMLEventQuery(t *testing.T) {
+	columns := sql.NewColumnList([]string{"id", "item_id"})
+	keyColumns := sql.NewColumnList([]string{"item_id"})
+
+	migrationContext := base.NewMigrationContext()
+	migrationContext.DatabaseName = "test"
+	migrationContext.OriginalTableName = "test"
+	migrationContext.OriginalTableColumns = columns
+	migrationContext.SharedColumns = columns
+	migrationContext.MappedSharedColumns = columns
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:        t.Name(),
+		Columns:     *keyColumns,
+		IsNonUnique: true,
+	}
+	migrationContext.MigrationRangeMinValues = sql.ToColumnValues([]interface{}{1})
+	migrationContext.MigrationRangeMaxValues = sql.ToColumnValues([]interface{}{100})
+
+	applier := NewApplier(migrationContext)
+	require.NoError(t, applier.prepareQueries())
+
+	t.Run("update", func(t *testing.T) {
+		binlogEvent := &binlog.BinlogDMLEvent{
+			DatabaseName:      "test",
+			DML:               binlog.UpdateDML,
+			NewColumnValues:   sql.ToColumnValues([]interface{}{123456, 43}),
+			WhereColumnValues: sql.ToColumnValues([]interface{}{123456, 42}),
+		}
+		res := applier.buildDMLEventQuery(binlogEvent)
+		require.Len(t, res, 2)
+		require.NoError(t, res[0].err)
+		require.NoError(t, res[1].err)
+		require.Contains(t, res[0].query, "delete /* gh-ost")
+		require.Contains(t, res[0].query, "and not ((?) >= (?) and (?) <= (?)) limit 1")
+		require.Equal(t, []interface{}{123456, 42, 42, 1, 42, 100}, res[0].args)
+		require.Equal(t, int64(-1), res[0].rowsDelta)
+		require.Contains(t, res[1].query, "insert /* gh-ost")
+		require.Equal(t, []interface{}{123456, 43, 43, 1, 43, 100}, res[1].args)
+		require.Equal(t, int64(1), res[1].rowsDelta)
+	})
+
+	t.Run("range exhausted", func(t *testing.T) {
+		atomic.StoreInt64(&applier.RowCopyRangeExhaustedFlag, 1)
+		defer atomic.StoreInt64(&applier.RowCopyRangeExhaustedFlag, 0)
+
+		binlogEvent := &binlog.BinlogDMLEvent{
+			DatabaseName:      "test",
+			DML:               binlog.DeleteDML,
+			WhereColumnValues: sql.ToColumnValues([]interface{}{123456, 42}),
+		}
+		res := applier.buildDMLEventQuery(binlogEvent)
+		require.Len(t, res, 1)
+		require.NoError(t, res[0].err)
+		require.NotContains(t, res[0].query, "not (")
+		require.Equal(t, []interface{}{123456, 42}, res[0].args)
+	})
+}
+
+func TestApplierBuildDMLEventQueries(t *testing.T) {
+	columns := sql.NewColumnList([]string{"id", "item_id"})
+
+	migrationContext := base.NewMigrationContext()
+	migrationContext.DatabaseName = "test"
+	migrationContext.OriginalTableName = "test"
+	migrationContext.GhostDatabaseName = "bbdataarchive"
+	migrationContext.OriginalTableColumns = columns
+	migrationContext.SharedColumns = columns
+	migrationContext.MappedSharedColumns = columns
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:    t.Name(),
+		Columns: *sql.NewColumnList([]string{"id"}),
+	}
+
+	applier := NewApplier(migrationContext)
+	require.NoError(t, applier.prepareQueries())
+
+	insertEvent := func(id int) *binlog.BinlogDMLEvent {
+		return &binlog.BinlogDMLEvent{DML: binlog.InsertDML, NewColumnValues: sql.ToColumnValues([]interface{}{id, 42})}
+	}
+	deleteEvent := func(id int) *binlog.BinlogDMLEvent {
+		return &binlog.BinlogDMLEvent{DML: binlog.DeleteDML, WhereColumnValues: sql.ToColumnValues([]interface{}{id, 42})}
+	}
+	updateEvent := func(id int) *binlog.BinlogDMLEvent {
+		return &binlog.BinlogDMLEvent{
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: sql.ToColumnValues([]interface{}{id, 42}),
+			NewColumnValues:   sql.ToColumnValues([]interface{}{id, 43}),
+		}
+	}
+	dmlEvents := []*binlog.BinlogDMLEvent{
+		insertEvent(1), insertEvent(2), insertEvent(3),
+		updateEvent(1),
+		deleteEvent(2), deleteEvent(3),
+		insertEvent(4),
+	}
+
+	t.Run("merged", func(t *testing.T) {
+		res, err := applier.buildDMLEventQueries(dmlEvents)
+		require.NoError(t, err)
+		require.Len(t, res, 4)
+
+		require.Contains(t, res[0].query, "(?, ?), (?, ?), (?, ?)")
+		require.Equal(t, []interface{}{1, 42, 2, 42, 3, 42}, res[0].args)
+		require.Equal(t, int64(1), res[0].rowsDelta)
+		require.Equal(t, int64(3), res[0].rowsCount)
+
+		require.Contains(t, res[1].query, "update")
+
+		require.Contains(t, res[2].query, "(`id`) in ((?), (?))")
+		require.Equal(t, []interface{}{2, 3}, res[2].args)
+		require.Equal(t, int64(-1), res[2].rowsDelta)
+		require.Equal(t, int64(2), res[2].rowsCount)
+
+		require.Equal(t, applier.buildDMLEventQuery(insertEvent(4))[0].query, res[3].query)
+		require.Equal(t, int64(1), res[3].rowsCount)
+	})
+
+	t.Run("bounded by statement size", func(t *testing.T) {
+		migrationContext.MaxDMLStatementSize = 2 * estimatedArgsSize([]interface{}{1, 42})
+		defer func() { migrationContext.MaxDMLStatementSize = 1024 * 1024 }()
+
+		res, err := applier.buildDMLEventQueries(dmlEvents[:3])
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+		require.Equal(t, int64(2), res[0].rowsCount)
+		require.Equal(t, int64(1), res[1].rowsCount)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		migrationContext.MaxDMLStatementSize = 0
+		defer func() { migrationContext.MaxDMLStatementSize = 1024 * 1024 }()
+
+		res, err := applier.buildDMLEventQueries(dmlEvents)
+		require.NoError(t, err)
+		require.Len(t, res, len(dmlEvents))
+	})
+
+	t.Run("inserts not merged with panic on warnings", func(t *testing.T) {
+		migrationContext.PanicOnWarnings = true
+		defer func() { migrationContext.PanicOnWarnings = false }()
+
+		res, err := applier.buildDMLEventQueries(dmlEvents)
+		require.NoError(t, err)
+		require.Len(t, res, 6)
+		for i := 0; i < 3; i++ {
+			require.Equal(t, applier.buildDMLEventQuery(insertEvent(i + 1))[0].query, res[i].query)
+			require.Equal(t, []interface{}{i + 1, 42}, res[i].args)
+		}
+		require.Contains(t, res[3].query, "update")
+		require.Contains(t, res[4].query, "(`id`) in ((?), (?))")
+		require.Equal(t, int64(2), res[4].rowsCount)
+		require.Equal(t, applier.buildDMLEventQuery(insertEvent(4))[0].query, res[5].query)
+	})
+}
+
 func TestApplierInstantDDL(t *testing.T) {
 	migrationContext := base.NewMigrationContext()
 	migrationContext.DatabaseName = "test"
@@ -200,6 +533,11 @@ func TestApplierInstantDDL(t *testing.T) {
 		stmt := applier.generateInstantDDLQuery()
 		require.Equal(t, "ALTER /* gh-ost */ TABLE `test`.`mytable` ADD INDEX (foo), ALGORITHM=INSTANT", stmt)
 	})
+
+	t.Run("inplaceDDLstmt", func(t *testing.T) {
+		stmt := applier.generateInplaceDDLQuery()
+		require.Equal(t, "ALTER /* gh-ost */ TABLE `test`.`mytable` ADD INDEX (foo), ALGORITHM=INPLACE, LOCK=NONE", stmt)
+	})
 }
 
 func TestRetryOnLockWaitTimeout(t *testing.T) {
@@ -424,6 +762,70 @@ func (suite *ApplierTestSuite) TestApplyDMLEventQueries() {
 	suite.Require().Equal(int64(0), migrationContext.RowsDeltaEstimate)
 }
 
+func (suite *ApplierTestSuite) TestApplyDMLEventQueriesCoalesced() {
+	ctx := context.Background()
+
+	var err error
+
+	_, err = suite.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (id INT PRIMARY KEY, item_id INT);", getTestTableName()))
+	suite.Require().NoError(err)
+
+	_, err = suite.db.ExecContext(ctx, "CREATE TABLE bbdataarchive.`~testing_gho` (id INT PRIMARY KEY, item_id INT);")
+	suite.Require().NoError(err)
+
+	connectionConfig, err := getTestConnectionConfig(ctx, suite.mysqlContainer)
+	suite.Require().NoError(err)
+
+	migrationContext := newTestMigrationContext()
+	migrationContext.ApplierConnectionConfig = connectionConfig
+	migrationContext.DatabaseName = "test"
+	migrationContext.GhostDatabaseName = "bbdataarchive"
+	migrationContext.SkipPortValidation = true
+	migrationContext.OriginalTableName = "testing"
+	migrationContext.SetConnectionConfig("innodb")
+	migrationContext.CoalesceDMLEvents = true
+
+	migrationContext.OriginalTableColumns = sql.NewColumnList([]string{"id", "item_id"})
+	migrationContext.SharedColumns = sql.NewColumnList([]string{"id", "item_id"})
+	migrationContext.MappedSharedColumns = sql.NewColumnList([]string{"id", "item_id"})
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:    "PRIMARY",
+		Columns: *sql.NewColumnList([]string{"id"}),
+	}
+
+	applier := NewApplier(migrationContext)
+	suite.Require().NoError(applier.prepareQueries())
+	defer applier.Teardown()
+
+	err = applier.InitDBConnections()
+	suite.Require().NoError(err)
+
+	_, err = suite.db.ExecContext(ctx, "INSERT INTO bbdataarchive.`~testing_gho` VALUES (1, 0);")
+	suite.Require().NoError(err)
+
+	dmlEvents := []*binlog.BinlogDMLEvent{}
+	for i := 0; i < 500; i++ {
+		dmlEvents = append(dmlEvents, &binlog.BinlogDMLEvent{
+			DatabaseName:      testMysqlDatabase,
+			TableName:         testMysqlTableName,
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: sql.ToColumnValues([]interface{}{1, i}),
+			NewColumnValues:   sql.ToColumnValues([]interface{}{1, i + 1}),
+		})
+	}
+	err = applier.ApplyDMLEventQueries(dmlEvents)
+	suite.Require().NoError(err)
+
+	var id, itemId int
+	err = suite.db.QueryRow("SELECT id, item_id FROM bbdataarchive.`~testing_gho`").Scan(&id, &itemId)
+	suite.Require().NoError(err)
+	suite.Require().Equal(1, id)
+	suite.Require().Equal(500, itemId)
+
+	suite.Require().Equal(int64(500), migrationContext.TotalDMLEventsApplied)
+	suite.Require().Equal(int64(499), migrationContext.TotalDMLEventsCoalesced)
+}
+
 func (suite *ApplierTestSuite) TestValidateOrDropExistingTables() {
 	ctx := context.Background()
 
@@ -733,6 +1135,37 @@ func (suite *ApplierTestSuite) TestPanicOnWarningsInApplyIterationInsertQueryFai
 	suite.Require().Contains(applier.migrationContext.MigrationLastInsertSQLWarnings[0], "Warning: Data truncated for column 'name' at row 1")
 }
 
+func (suite *ApplierTestSuite) TestPanicOnWarningsInApplyIterationRowsInsertQueryFailsWithTruncationWarning() {
+	ctx := context.Background()
+
+	_, err := suite.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (id INT, name varchar(10), primary key(id));", getTestGhostTableName()))
+	suite.Require().NoError(err)
+
+	connectionConfig, err := getTestConnectionConfig(ctx, suite.mysqlContainer)
+	suite.Require().NoError(err)
+
+	migrationContext := newTestMigrationContext()
+	migrationContext.ApplierConnectionConfig = connectionConfig
+	migrationContext.SetConnectionConfig("innodb")
+	migrationContext.MappedSharedColumns = sql.NewColumnList([]string{"id", "name"})
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:             "PRIMARY",
+		NameInGhostTable: "PRIMARY",
+		Columns:          *sql.NewColumnList([]string{"id"}),
+	}
+	applier := NewApplier(migrationContext)
+	suite.Require().NoError(applier.InitDBConnections())
+
+	rows := [][]interface{}{{1, "short"}, {2, "this string is long"}}
+	rowsAffected, _, err := applier.ApplyIterationRowsInsertQuery(rows)
+	suite.Require().NoError(err)
+	suite.Equal(int64(2), rowsAffected)
+
+	// Verify the warning was recorded and will cause the migrator to panic
+	suite.Require().Len(applier.migrationContext.MigrationLastInsertSQLWarnings, 1)
+	suite.Require().Contains(applier.migrationContext.MigrationLastInsertSQLWarnings[0], "Data truncated for column 'name'")
+}
+
 func (suite *ApplierTestSuite) TestWriteCheckpoint() {
 	ctx := context.Background()
 
@@ -804,6 +1237,10 @@ func (suite *ApplierTestSuite) TestWriteCheckpoint() {
 		RowsCopied:        100000,
 		DMLApplied:        200000,
 		IsCutover:         true,
+		RowsCounted:       300000,
+		RowCountRangeMax:  applier.migrationContext.MigrationRangeMinValues,
+		Heartbeat:         "2025-01-02T03:04:05.123456789Z",
+		ServerUUID:        "3e11fa47-71ca-11e1-9e33-c80aa9429562",
 	}
 	id, err := applier.WriteCheckpoint(chk)
 	suite.Require().NoError(err)
@@ -819,6 +1256,77 @@ func (suite *ApplierTestSuite) TestWriteCheckpoint() {
 	suite.Require().Equal(chk.RowsCopied, gotChk.RowsCopied)
 	suite.Require().Equal(chk.DMLApplied, gotChk.DMLApplied)
 	suite.Require().Equal(chk.IsCutover, gotChk.IsCutover)
+	suite.Require().Equal(chk.RowsCounted, gotChk.RowsCounted)
+	suite.Require().Equal(chk.RowCountRangeMax.String(), gotChk.RowCountRangeMax.String())
+	suite.Require().False(gotChk.IsCountComplete)
+	suite.Require().Equal(chk.Heartbeat, gotChk.Heartbeat)
+	suite.Require().Equal(chk.ServerUUID, gotChk.ServerUUID)
+}
+
+func (suite *ApplierTestSuite) TestUpgradeCheckpointTable() {
+	ctx := context.Background()
+
+	_, err := suite.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (id INT, id2 INT, PRIMARY KEY(id, id2))", getTestTableName()))
+	suite.Require().NoError(err)
+
+	connectionConfig, err := getTestConnectionConfig(ctx, suite.mysqlContainer)
+	suite.Require().NoError(err)
+
+	migrationContext := newTestMigrationContext()
+	migrationContext.ApplierConnectionConfig = connectionConfig
+	migrationContext.SetConnectionConfig("innodb")
+	migrationContext.OriginalTableColumns = sql.NewColumnList([]string{"id", "id2"})
+	migrationContext.SharedColumns = sql.NewColumnList([]string{"id", "id2"})
+	migrationContext.MappedSharedColumns = sql.NewColumnList([]string{"id", "id2"})
+	migrationContext.Checkpoint = true
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:             "PRIMARY",
+		NameInGhostTable: "PRIMARY",
+		Columns:          *sql.NewColumnList([]string{"id", "id2"}),
+	}
+
+	// A checkpoint table as created by an earlier version
+	checkpointTableName := fmt.Sprintf("%s.%s", testMysqlDatabase, sql.EscapeName(migrationContext.GetCheckpointTableName()))
+	_, err = suite.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE %s (
+		gh_ost_chk_id bigint auto_increment primary key,
+		gh_ost_chk_timestamp bigint,
+		gh_ost_chk_coords text charset ascii,
+		gh_ost_chk_iteration bigint,
+		gh_ost_rows_copied bigint,
+		gh_ost_dml_applied bigint,
+		gh_ost_is_cutover tinyint(1) DEFAULT '0',
+		id_min int, id2_min int, id_max int, id2_max int)`, checkpointTableName))
+	suite.Require().NoError(err)
+	_, err = suite.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s VALUES (1, 1700000000, 'mysql-bin.000003:4567', 7, 100, 200, 0, 1, 2, 3, 4)`, checkpointTableName))
+	suite.Require().NoError(err)
+
+	applier := NewApplier(migrationContext)
+	defer applier.Teardown()
+	suite.Require().NoError(applier.InitDBConnections())
+	suite.Require().NoError(applier.prepareQueries())
+
+	suite.Require().NoError(applier.UpgradeCheckpointTable())
+	// Upgrading is idempotent
+	suite.Require().NoError(applier.UpgradeCheckpointTable())
+
+	chk, err := applier.ReadLastCheckpoint()
+	suite.Require().NoError(err)
+	suite.Require().Equal("mysql-bin.000003:4567", chk.LastTrxCoords.String())
+	suite.Require().Equal(int64(7), chk.Iteration)
+	suite.Require().Equal(int64(100), chk.RowsCopied)
+	suite.Require().NotNil(chk.IterationRangeMin.AbstractValues()[0])
+	suite.Require().NotNil(chk.IterationRangeMax.AbstractValues()[1])
+	suite.Require().Nil(chk.RowCountRangeMax)
+	suite.Require().Empty(chk.Heartbeat)
+
+	chk.Iteration = 8
+	id, err := applier.WriteCheckpoint(chk)
+	suite.Require().NoError(err)
+	suite.Require().Equal(int64(2), id)
+	gotChk, err := applier.ReadLastCheckpoint()
+	suite.Require().NoError(err)
+	suite.Require().Equal(int64(8), gotChk.Iteration)
+	suite.Require().Equal(chk.IterationRangeMax.String(), gotChk.IterationRangeMax.String())
 }
 
 func (suite *ApplierTestSuite) TestDropCheckpointTableUsesOriginalDatabase() {
@@ -1396,6 +1904,61 @@ func (suite *ApplierTestSuite) TestNormalUpdateWithPanicOnWarnings() {
 	suite.Require().NoError(rows.Err())
 }
 
+// TestPanicOnWarningsWithNothingToApply tests that a batch building no queries, as with a partial row
+// image update changing no shared column, applies cleanly with PanicOnWarnings
+func (suite *ApplierTestSuite) TestPanicOnWarningsWithNothingToApply() {
+	ctx := context.Background()
+
+	var err error
+
+	_, err = suite.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (id INT PRIMARY KEY, email VARCHAR(100), notes TEXT);", getTestTableName()))
+	suite.Require().NoError(err)
+
+	_, err = suite.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (id INT PRIMARY KEY, email VARCHAR(100));", getTestGhostTableName()))
+	suite.Require().NoError(err)
+
+	connectionConfig, err := getTestConnectionConfig(ctx, suite.mysqlContainer)
+	suite.Require().NoError(err)
+
+	migrationContext := newTestMigrationContext()
+	migrationContext.ApplierConnectionConfig = connectionConfig
+	migrationContext.SetConnectionConfig("innodb")
+
+	migrationContext.PanicOnWarnings = true
+	migrationContext.OriginalBinlogRowImage = "MINIMAL"
+
+	migrationContext.OriginalTableColumns = sql.NewColumnList([]string{"id", "email", "notes"})
+	migrationContext.SharedColumns = sql.NewColumnList([]string{"id", "email"})
+	migrationContext.MappedSharedColumns = sql.NewColumnList([]string{"id", "email"})
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:             "PRIMARY",
+		NameInGhostTable: "PRIMARY",
+		Columns:          *sql.NewColumnList([]string{"id"}),
+	}
+
+	applier := NewApplier(migrationContext)
+	suite.Require().NoError(applier.prepareQueries())
+	defer applier.Teardown()
+
+	err = applier.InitDBConnections()
+	suite.Require().NoError(err)
+
+	// Only the dropped notes column changes
+	dmlEvents := []*binlog.BinlogDMLEvent{
+		{
+			DatabaseName:      testMysqlDatabase,
+			TableName:         testMysqlTableName,
+			DML:               binlog.UpdateDML,
+			WhereColumnValues: sql.ToPartialColumnValues([]interface{}{1, nil, nil}, []int{1, 2}),
+			NewColumnValues:   sql.ToPartialColumnValues([]interface{}{nil, nil, "some notes"}, []int{0, 1}),
+		},
+	}
+	suite.Require().Empty(applier.buildDMLEventQuery(dmlEvents[0]))
+
+	err = applier.ApplyDMLEventQueries(dmlEvents)
+	suite.Require().NoError(err)
+}
+
 // TestDuplicateOnMigrationKeyAllowedInBinlogReplay tests the positive case where
 // a duplicate on the migration unique key during binlog replay is expected and should be allowed
 func (suite *ApplierTestSuite) TestDuplicateOnMigrationKeyAllowedInBinlogReplay() {
@@ -6,8 +6,11 @@
 package logic
 
 import (
+	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/github/gh-ost/go/binlog"
 	"github.com/github/gh-ost/go/mysql"
 	"github.com/github/gh-ost/go/sql"
 )
@@ -19,6 +22,12 @@ type Checkpoint struct {
 	// LastTrxCoords are coordinates of a transaction
 	// that has been applied on ghost table.
 	LastTrxCoords mysql.BinlogCoordinates
+	// Heartbeat is the value of the last changelog heartbeat at or before LastTrxCoords.
+	// It locates LastTrxCoords in the binary logs of a replica other than ServerUUID.
+	Heartbeat string
+	// ServerUUID is the server_uuid of the inspected server, whose binary logs
+	// LastTrxCoords refer to.
+	ServerUUID string
 	// IterationRangeMin is the min shared key value
 	// for the chunk copier range.
 	IterationRangeMin *sql.ColumnValues
@@ -29,4 +38,131 @@ type Checkpoint struct {
 	RowsCopied        int64
 	DMLApplied        int64
 	IsCutover         bool
+	// RowsCounted is the number of rows counted so far by
+	// the exact row count, up to RowCountRangeMax.
+	RowsCounted      int64
+	RowCountRangeMax *sql.ColumnValues
+	IsCountComplete  bool
+}
+
+// binlogWrittenAt returns a time at or before which LastTrxCoords were written to the binary logs: the time
+// of the checkpoint's heartbeat on the master, or else the time of the checkpoint.
+func (this *Checkpoint) binlogWrittenAt() time.Time {
+	if heartbeatTime, err := time.Parse(time.RFC3339Nano, this.Heartbeat); err == nil {
+		return heartbeatTime
+	}
+	return this.Timestamp
+}
+
+// resumeStreamerCoordinates returns the coordinates on the inspected server from which to resume
+// streaming after the given checkpoint. File coordinates of a checkpoint taken on a different server
+// are translated by locating the checkpoint's heartbeat in the inspected server's binary logs.
+func (this *Migrator) resumeStreamerCoordinates(chk *Checkpoint) (mysql.BinlogCoordinates, error) {
+	if this.migrationContext.UseGTIDs || chk.ServerUUID == "" || chk.ServerUUID == this.migrationContext.InspectorServerUUID {
+		return chk.LastTrxCoords, nil
+	}
+	if chk.Heartbeat == "" {
+		return nil, fmt.Errorf("Checkpoint was taken on server %s and has no heartbeat to locate its coordinates on %s. Resume on the original server, or use --gtid", chk.ServerUUID, this.migrationContext.InspectorServerUUID)
+	}
+	this.migrationContext.Log.Infof("Checkpoint was taken on server %s at %+v. Locating heartbeat %s in binary logs of %+v", chk.ServerUUID, chk.LastTrxCoords, chk.Heartbeat, *this.migrationContext.InspectorConnectionConfig.ImpliedKey)
+	coords, err := this.locateHeartbeat(chk.Heartbeat)
+	if err != nil {
+		return nil, err
+	}
+	this.migrationContext.Log.Infof("Located heartbeat %s at %+v", chk.Heartbeat, coords)
+	return coords, nil
+}
+
+// locateHeartbeat returns the coordinates of the end of the transaction which wrote the given changelog
+// heartbeat, in the binary logs of the inspected server. Heartbeats increase along the binary logs: the
+// newest binary log to begin with an earlier heartbeat is the one to hold it.
+func (this *Migrator) locateHeartbeat(heartbeat string) (mysql.BinlogCoordinates, error) {
+	heartbeatTime, err := time.Parse(time.RFC3339Nano, heartbeat)
+	if err != nil {
+		return nil, err
+	}
+	binaryLogs, err := mysql.GetBinaryLogs(this.inspector.db)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(binaryLogs) - 1; i >= 0; i-- {
+		var firstHeartbeatTime time.Time
+		if _, err := this.scanHeartbeats(binaryLogs[i].Name, func(_ string, t time.Time) bool {
+			firstHeartbeatTime = t
+			return true
+		}); err != nil {
+			return nil, err
+		}
+		if firstHeartbeatTime.IsZero() || firstHeartbeatTime.After(heartbeatTime) {
+			continue
+		}
+		found := false
+		coords, err := this.scanHeartbeats(binaryLogs[i].Name, func(value string, t time.Time) bool {
+			found = (value == heartbeat)
+			return found || t.After(heartbeatTime)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return coords, nil
+		}
+		break
+	}
+	return nil, fmt.Errorf("Heartbeat %s not found in binary logs of %+v. The binary logs may have been purged, or the server may be lagging", heartbeat, *this.migrationContext.InspectorConnectionConfig.ImpliedKey)
+}
+
+// scanHeartbeats reads the changelog heartbeats in the given binary log of the inspected server, until
+// onHeartbeat returns true. It returns the coordinates of the end of that heartbeat's transaction, or
+// nil if there is no such heartbeat in the binary log.
+func (this *Migrator) scanHeartbeats(logFile string, onHeartbeat func(heartbeat string, heartbeatTime time.Time) bool) (coords mysql.BinlogCoordinates, err error) {
+	reader, err := binlog.NewGoMySQLReader(this.migrationContext, this.migrationContext.InspectorConnectionConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	reader.EmitTransactionBoundaries = true
+	if err := reader.ConnectBinlogStreamer(mysql.NewFileBinlogCoordinates(logFile, 4)); err != nil {
+		return nil, err
+	}
+
+	var stopStreaming int64
+	entries := make(chan *binlog.BinlogEntry)
+	streamErr := make(chan error, 1)
+	go func() {
+		streamErr <- reader.StreamEvents(func() bool { return atomic.LoadInt64(&stopStreaming) > 0 }, entries)
+		close(entries)
+	}()
+	defer func() {
+		// Unblock the stream, which may be awaiting events
+		atomic.StoreInt64(&stopStreaming, 1)
+		reader.Close()
+		for range entries {
+		}
+	}()
+
+	heartbeatStop := false
+	for entry := range entries {
+		if entry.Coordinates.(*mysql.FileBinlogCoordinates).LogFile != logFile {
+			return nil, nil
+		}
+		if entry.DmlEvent == nil {
+			if heartbeatStop {
+				return entry.Coordinates, nil
+			}
+			continue
+		}
+		if heartbeatStop || !this.migrationContext.TableNamesEqual(entry.DmlEvent.DatabaseName, this.migrationContext.GetGhostDatabaseName()) ||
+			!this.migrationContext.TableNamesEqual(entry.DmlEvent.TableName, this.migrationContext.GetChangelogTableName()) ||
+			changelogHint(entry.DmlEvent) != "heartbeat" {
+			continue
+		}
+		heartbeat := entry.DmlEvent.NewColumnValues.StringColumn(3)
+		heartbeatTime, err := time.Parse(time.RFC3339Nano, heartbeat)
+		if err != nil {
+			return nil, err
+		}
+		heartbeatStop = onHeartbeat(heartbeat, heartbeatTime)
+	}
+	return nil, <-streamErr
 }
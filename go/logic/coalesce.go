@@ -0,0 +1,136 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package logic
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/github/gh-ost/go/binlog"
+	"github.com/github/gh-ost/go/sql"
+)
+
+// coalescedRowEvent is the single event which has the same effect on the ghost table as all events of
+// a batch on one unique key value
+type coalescedRowEvent struct {
+	dmlEvent *binlog.BinlogDMLEvent
+	events   int
+	position int
+}
+
+// coalesceDMLEvents collapses the events of a batch which apply to the same unique key value, such that
+// a hot row updated many times in a batch is written once:
+//
+//	update, update(s)      -> update to the final image
+//	insert/update, delete  -> delete
+//	delete/insert, ...     -> delete and insert of the final image
+//
+// Inserts onto the ghost table are `insert ignore`, and a row may have been copied onto the ghost table
+// in any of the states it went through in the batch. A final image which is not the product of updates
+// alone is therefore written as a delete followed by an insert. A lone insert is kept as is.
+//
+// An update modifying the unique key is taken as a delete of its before image and an insert of its after
+// image. Events are ordered by the position of the last event they replace. Events are not modified.
+// Returns the coalesced events and the number of given events merged into others.
+func coalesceDMLEvents(dmlEvents []*binlog.BinlogDMLEvent, uniqueKeyColumns, tableColumns *sql.ColumnList) (coalescedEvents []*binlog.BinlogDMLEvent, coalescedCount int) {
+	ordinals := make([]int, 0, uniqueKeyColumns.Len())
+	for _, column := range uniqueKeyColumns.Columns() {
+		ordinals = append(ordinals, tableColumns.Ordinals[column.Name])
+	}
+	keyOf := func(columnValues *sql.ColumnValues) string {
+		values := columnValues.AbstractValues()
+		keyValues := make([]interface{}, len(ordinals))
+		for i, ordinal := range ordinals {
+			keyValues[i] = values[ordinal]
+		}
+		return fmt.Sprintf("%#v", keyValues)
+	}
+
+	rowEvents := make(map[string]*coalescedRowEvent)
+	position := 0
+	// coalesce merges given event into its key's row event, and tells whether there was one
+	coalesce := func(key string, dmlEvent *binlog.BinlogDMLEvent) (merged bool) {
+		position++
+		rowEvent, exists := rowEvents[key]
+		if !exists {
+			rowEvents[key] = &coalescedRowEvent{dmlEvent: dmlEvent, events: 1, position: position}
+			return false
+		}
+		rowEvent.events++
+		rowEvent.position = position
+		previous := rowEvent.dmlEvent
+		switch {
+		case dmlEvent.DML == binlog.DeleteDML, dmlEvent.DML == binlog.InsertDML:
+			// the row's final state, whatever came before
+			rowEvent.dmlEvent = dmlEvent
+		case previous.DML == binlog.DeleteDML:
+			// an update of a deleted row changes nothing
+		case previous.DML == binlog.InsertDML:
+			rowEvent.dmlEvent = withDML(dmlEvent, binlog.InsertDML)
+		default:
+			// update following update: the final image, keyed by the first before image
+			coalesced := withDML(dmlEvent, binlog.UpdateDML)
+			coalesced.WhereColumnValues = previous.WhereColumnValues
+			rowEvent.dmlEvent = coalesced
+		}
+		return true
+	}
+
+	for _, dmlEvent := range dmlEvents {
+		switch dmlEvent.DML {
+		case binlog.DeleteDML:
+			if coalesce(keyOf(dmlEvent.WhereColumnValues), dmlEvent) {
+				coalescedCount++
+			}
+		case binlog.InsertDML:
+			if coalesce(keyOf(dmlEvent.NewColumnValues), dmlEvent) {
+				coalescedCount++
+			}
+		case binlog.UpdateDML:
+			beforeKey, afterKey := keyOf(dmlEvent.WhereColumnValues), keyOf(dmlEvent.NewColumnValues)
+			if beforeKey == afterKey {
+				if coalesce(beforeKey, dmlEvent) {
+					coalescedCount++
+				}
+				continue
+			}
+			mergedDelete := coalesce(beforeKey, withDML(dmlEvent, binlog.DeleteDML))
+			mergedInsert := coalesce(afterKey, withDML(dmlEvent, binlog.InsertDML))
+			if mergedDelete || mergedInsert {
+				coalescedCount++
+			}
+		default:
+			// not ours to interpret; leave the batch as is
+			return dmlEvents, 0
+		}
+	}
+
+	coalescedRowEvents := make([]*coalescedRowEvent, 0, len(rowEvents))
+	for _, rowEvent := range rowEvents {
+		coalescedRowEvents = append(coalescedRowEvents, rowEvent)
+	}
+	sort.Slice(coalescedRowEvents, func(i, j int) bool {
+		return coalescedRowEvents[i].position < coalescedRowEvents[j].position
+	})
+	coalescedEvents = make([]*binlog.BinlogDMLEvent, 0, len(coalescedRowEvents))
+	for _, rowEvent := range coalescedRowEvents {
+		if rowEvent.dmlEvent.DML == binlog.InsertDML && rowEvent.events > 1 {
+			// overwrite whichever state of the row was copied
+			deleteEvent := withDML(rowEvent.dmlEvent, binlog.DeleteDML)
+			deleteEvent.WhereColumnValues = rowEvent.dmlEvent.NewColumnValues
+			coalescedEvents = append(coalescedEvents, deleteEvent)
+		}
+		coalescedEvents = append(coalescedEvents, rowEvent.dmlEvent)
+	}
+	return coalescedEvents, coalescedCount
+}
+
+// withDML returns a copy of given event with given DML
+func withDML(dmlEvent *binlog.BinlogDMLEvent, dml binlog.EventDML) *binlog.BinlogDMLEvent {
+	event := *dmlEvent
+	event.DML = dml
+	return &event
+}
@@ -0,0 +1,126 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package logic
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/github/gh-ost/go/binlog"
+	"github.com/github/gh-ost/go/sql"
+	"github.com/stretchr/testify/require"
+)
+
+func testInsertEvent(id, value int) *binlog.BinlogDMLEvent {
+	return &binlog.BinlogDMLEvent{DML: binlog.InsertDML, NewColumnValues: sql.ToColumnValues([]interface{}{id, value})}
+}
+
+func testUpdateEvent(id, value, newId, newValue int) *binlog.BinlogDMLEvent {
+	return &binlog.BinlogDMLEvent{
+		DML:               binlog.UpdateDML,
+		WhereColumnValues: sql.ToColumnValues([]interface{}{id, value}),
+		NewColumnValues:   sql.ToColumnValues([]interface{}{newId, newValue}),
+	}
+}
+
+func testDeleteEvent(id, value int) *binlog.BinlogDMLEvent {
+	return &binlog.BinlogDMLEvent{DML: binlog.DeleteDML, WhereColumnValues: sql.ToColumnValues([]interface{}{id, value})}
+}
+
+// describeEvents renders events as e.g. "Insert(1,2)", "Update(1,2->1,3)", "Delete(1,2)"
+func describeEvents(dmlEvents []*binlog.BinlogDMLEvent) []string {
+	descriptions := []string{}
+	for _, dmlEvent := range dmlEvents {
+		switch dmlEvent.DML {
+		case binlog.InsertDML:
+			descriptions = append(descriptions, fmt.Sprintf("Insert(%v,%v)", dmlEvent.NewColumnValues.AbstractValues()...))
+		case binlog.DeleteDML:
+			descriptions = append(descriptions, fmt.Sprintf("Delete(%v,%v)", dmlEvent.WhereColumnValues.AbstractValues()...))
+		case binlog.UpdateDML:
+			descriptions = append(descriptions, fmt.Sprintf("Update(%v,%v->%v,%v)", append(dmlEvent.WhereColumnValues.AbstractValues(), dmlEvent.NewColumnValues.AbstractValues()...)...))
+		}
+	}
+	return descriptions
+}
+
+func TestCoalesceDMLEvents(t *testing.T) {
+	columns := sql.NewColumnList([]string{"id", "value"})
+	uniqueKeyColumns := sql.NewColumnList([]string{"id"})
+
+	tests := []struct {
+		name      string
+		events    []*binlog.BinlogDMLEvent
+		expected  []string
+		coalesced int
+	}{
+		{
+			name:      "hot row updates",
+			events:    []*binlog.BinlogDMLEvent{testUpdateEvent(1, 1, 1, 2), testUpdateEvent(1, 2, 1, 3), testUpdateEvent(1, 3, 1, 4)},
+			expected:  []string{"Update(1,1->1,4)"},
+			coalesced: 2,
+		},
+		{
+			name:      "insert then updates",
+			events:    []*binlog.BinlogDMLEvent{testInsertEvent(1, 1), testUpdateEvent(1, 1, 1, 2), testUpdateEvent(1, 2, 1, 3)},
+			expected:  []string{"Delete(1,3)", "Insert(1,3)"},
+			coalesced: 2,
+		},
+		{
+			name:      "update then delete",
+			events:    []*binlog.BinlogDMLEvent{testUpdateEvent(1, 1, 1, 2), testDeleteEvent(1, 2)},
+			expected:  []string{"Delete(1,2)"},
+			coalesced: 1,
+		},
+		{
+			name:      "insert then delete",
+			events:    []*binlog.BinlogDMLEvent{testInsertEvent(1, 1), testDeleteEvent(1, 1)},
+			expected:  []string{"Delete(1,1)"},
+			coalesced: 1,
+		},
+		{
+			name:      "delete then insert",
+			events:    []*binlog.BinlogDMLEvent{testDeleteEvent(1, 1), testInsertEvent(1, 2), testUpdateEvent(1, 2, 1, 3)},
+			expected:  []string{"Delete(1,3)", "Insert(1,3)"},
+			coalesced: 2,
+		},
+		{
+			name:      "distinct rows keep the order of their last event",
+			events:    []*binlog.BinlogDMLEvent{testUpdateEvent(1, 1, 1, 2), testInsertEvent(2, 1), testUpdateEvent(1, 2, 1, 3), testDeleteEvent(3, 1)},
+			expected:  []string{"Insert(2,1)", "Update(1,1->1,3)", "Delete(3,1)"},
+			coalesced: 1,
+		},
+		{
+			name:     "unique key changing update",
+			events:   []*binlog.BinlogDMLEvent{testUpdateEvent(1, 1, 2, 1)},
+			expected: []string{"Delete(1,1)", "Insert(2,1)"},
+		},
+		{
+			name:      "unique key changing update of an inserted row",
+			events:    []*binlog.BinlogDMLEvent{testInsertEvent(1, 1), testUpdateEvent(1, 1, 2, 1), testUpdateEvent(2, 1, 2, 2)},
+			expected:  []string{"Delete(1,1)", "Delete(2,2)", "Insert(2,2)"},
+			coalesced: 2,
+		},
+		{
+			name:      "unique key changing update onto a deleted row",
+			events:    []*binlog.BinlogDMLEvent{testUpdateEvent(2, 5, 2, 6), testDeleteEvent(2, 6), testUpdateEvent(1, 1, 2, 1)},
+			expected:  []string{"Delete(1,1)", "Delete(2,1)", "Insert(2,1)"},
+			coalesced: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			coalesced, coalescedCount := coalesceDMLEvents(test.events, uniqueKeyColumns, columns)
+			require.Equal(t, test.expected, describeEvents(coalesced))
+			require.Equal(t, test.coalesced, coalescedCount)
+		})
+	}
+
+	t.Run("events are not modified", func(t *testing.T) {
+		events := []*binlog.BinlogDMLEvent{testInsertEvent(1, 1), testUpdateEvent(1, 1, 2, 1)}
+		coalesceDMLEvents(events, uniqueKeyColumns, columns)
+		require.Equal(t, []string{"Insert(1,1)", "Update(1,1->2,1)"}, describeEvents(events))
+	})
+}
@@ -0,0 +1,215 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package logic
+
+import (
+	gosql "database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/github/gh-ost/go/base"
+	"github.com/github/gh-ost/go/mysql"
+
+	"github.com/openark/golib/sqlutils"
+)
+
+// streamerFailoverSource is called by the events streamer when it is unable to resume streaming off
+// failedConfig, as happens when the streamed server is gone, or when the master fails over and the
+// streamed replica is re-pointed. It re-validates that the applier is still the writable master,
+// then picks a new server to stream off: a server given by the streamer-failover hook, a replica of
+// the applier, or the applier itself. Since the server must be able to serve the stream from the
+// last applied GTID coordinates, the migration never skips or replays events.
+func (this *Migrator) streamerFailoverSource(failedConfig *mysql.ConnectionConfig, coords mysql.BinlogCoordinates) (*mysql.ConnectionConfig, error) {
+	gtidCoords, ok := coords.(*mysql.GTIDBinlogCoordinates)
+	if !ok {
+		return nil, fmt.Errorf("Streamer failover requires GTID coordinates, got %+v", coords)
+	}
+	this.migrationContext.Log.Infof("Looking for a server to fail over the binlog streamer from %+v at %+v", failedConfig.Key, coords)
+	applierServerUUID, err := this.applier.validateWritableMaster()
+	if err != nil {
+		return nil, err
+	}
+	this.migrationContext.Log.Infof("%s validated as writable master", this.migrationContext.ApplierConnectionConfig.Key.String())
+	candidates, err := this.streamerFailoverCandidates(failedConfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range candidates {
+		if err := this.validateStreamerFailoverCandidate(candidate, gtidCoords, applierServerUUID); err != nil {
+			this.migrationContext.Log.Errorf("Cannot fail over the binlog streamer to %+v: %+v", candidate.Key, err)
+			continue
+		}
+		// Replication lag, heartbeats and binary log retention are read off the streamed server
+		if err := this.inspector.failOver(candidate); err != nil {
+			this.migrationContext.Log.Errorf("Cannot fail over the inspector to %+v: %+v", candidate.Key, err)
+			continue
+		}
+		return candidate, nil
+	}
+	return nil, fmt.Errorf("Found no server to fail over the binlog streamer from %+v at %+v. Bailing out", failedConfig.Key, coords)
+}
+
+// streamerFailoverCandidates lists servers the streamer may fail over to, by order of preference:
+// those given by the streamer-failover hook, replicas of the applier, then the applier itself.
+func (this *Migrator) streamerFailoverCandidates(failedConfig *mysql.ConnectionConfig) (candidates []*mysql.ConnectionConfig, err error) {
+	visitedKeys := mysql.NewInstanceKeyMap()
+	visitedKeys.AddKey(failedConfig.Key)
+	addCandidate := func(config *mysql.ConnectionConfig) {
+		if visitedKeys.HasKey(config.Key) {
+			return
+		}
+		visitedKeys.AddKey(config.Key)
+		candidates = append(candidates, config)
+	}
+
+	hostnames, err := this.hooksExecutor.onStreamerFailover(failedConfig.Key.String())
+	if err != nil {
+		return nil, err
+	}
+	for _, hostname := range hostnames {
+		key, err := mysql.ParseInstanceKey(hostname)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid host %q given by %s hook: %+v", hostname, onStreamerFailover, err)
+		}
+		addCandidate(this.migrationContext.InspectorConnectionConfig.DuplicateCredentials(*key))
+	}
+
+	query := fmt.Sprintf("show /* gh-ost */ %s", mysql.ReplicaTermFor(this.migrationContext.ApplierMySQLVersion, `slave hosts`))
+	err = sqlutils.QueryRowsMap(this.applier.db, query, func(rowMap sqlutils.RowMap) error {
+		key := mysql.InstanceKey{Hostname: rowMap.GetString("Host"), Port: rowMap.GetInt("Port")}
+		if key.IsValid() {
+			addCandidate(this.migrationContext.InspectorConnectionConfig.DuplicateCredentials(key))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	addCandidate(this.migrationContext.ApplierConnectionConfig.Duplicate())
+	return candidates, nil
+}
+
+// validateStreamerFailoverCandidate checks that the streamer may resume at given coordinates off given
+// server: the server must log the applier's changes in the same format as the original server, must
+// have executed the transactions up to the coordinates and still have those following them, and must
+// replicate from the applier.
+func (this *Migrator) validateStreamerFailoverCandidate(candidate *mysql.ConnectionConfig, coords *mysql.GTIDBinlogCoordinates, applierServerUUID string) error {
+	db, _, err := this.migrationContext.DBCache.GetDB(candidate.GetDBUri("information_schema"), candidate)
+	if err != nil {
+		return err
+	}
+	var hasBinaryLogs, logReplicaUpdates bool
+	var version, binlogFormat, binlogRowImage, gtidMode string
+	query := `select /* gh-ost */ @@global.version, @@global.log_bin, @@global.log_slave_updates, @@global.binlog_format, @@global.binlog_row_image, @@global.gtid_mode`
+	if err := db.QueryRow(query).Scan(&version, &hasBinaryLogs, &logReplicaUpdates, &binlogFormat, &binlogRowImage, &gtidMode); err != nil {
+		return err
+	}
+	isApplier := candidate.Key.Equals(&this.migrationContext.ApplierConnectionConfig.Key)
+	switch {
+	case !hasBinaryLogs:
+		return fmt.Errorf("%s must have binary logs enabled", candidate.Key.String())
+	case !isApplier && !logReplicaUpdates:
+		return fmt.Errorf("%s must have log_slave_updates enabled", candidate.Key.String())
+	case binlogFormat != "ROW":
+		return fmt.Errorf("%s has %s binlog_format, expected ROW", candidate.Key.String(), binlogFormat)
+	case !strings.EqualFold(binlogRowImage, this.migrationContext.OriginalBinlogRowImage):
+		return fmt.Errorf("%s has %s binlog_row_image, expected %s", candidate.Key.String(), binlogRowImage, this.migrationContext.OriginalBinlogRowImage)
+	case gtidMode != "ON":
+		return fmt.Errorf("%s has %s gtid_mode, expected ON", candidate.Key.String(), gtidMode)
+	}
+
+	gtidExecuted, err := mysql.GetSelfBinlogCoordinates(version, db, true)
+	if err != nil {
+		return err
+	}
+	if gtidExecuted.SmallerThan(coords) {
+		return fmt.Errorf("%s has not executed all transactions up to %+v", candidate.Key.String(), coords)
+	}
+	gtidPurged, err := mysql.GetPurgedGTIDSet(db)
+	if err != nil {
+		return err
+	}
+	if coords.IsPurged(gtidPurged) {
+		return fmt.Errorf("%s has purged transactions following %+v", candidate.Key.String(), coords)
+	}
+
+	if !isApplier {
+		masterConfig, masterServerUUID, err := this.getTopologyMaster(candidate)
+		if err != nil {
+			return err
+		}
+		if masterServerUUID != applierServerUUID {
+			return fmt.Errorf("%s replicates from %s, which is not the applier %s", candidate.Key.String(), masterConfig.Key.String(), this.migrationContext.ApplierConnectionConfig.Key.String())
+		}
+	}
+	return nil
+}
+
+// getTopologyMaster returns the master given server replicates from, directly or via intermediate
+// replicas, and its server_uuid. The server replicates on --replication-channel, if given.
+func (this *Migrator) getTopologyMaster(connectionConfig *mysql.ConnectionConfig) (masterConfig *mysql.ConnectionConfig, serverUUID string, err error) {
+	masterConfig, err = mysql.GetMasterConnectionConfigSafe(this.migrationContext.ApplierMySQLVersion, connectionConfig, mysql.NewInstanceKeyMap(), this.migrationContext.AllowedMasterMaster, this.migrationContext.ReplicationChannel)
+	if err != nil {
+		return nil, "", err
+	}
+	serverUUID, err = getServerUUID(this.migrationContext.DBCache, masterConfig)
+	return masterConfig, serverUUID, err
+}
+
+// streamerTopologyLoop periodically checks the topology the binlog streamer depends on, see checkStreamerTopology.
+func (this *Migrator) streamerTopologyLoop() {
+	ticker := time.NewTicker(streamerTopologyCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if atomic.LoadInt64(&this.finishedMigrating) > 0 || atomic.LoadInt64(&this.migrationContext.CutOverCompleteFlag) > 0 {
+			return
+		}
+		if err := this.checkStreamerTopology(); err != nil {
+			if errors.Is(err, ErrNotWritableMaster) {
+				_ = base.SendWithContext(this.migrationContext.GetContext(), this.migrationContext.PanicAbort, err)
+				return
+			}
+			this.migrationContext.Log.Errore(err)
+		}
+	}
+}
+
+// checkStreamerTopology detects a master failover which leaves the streamer connected: it re-validates
+// that the applier is still the writable master, and that the streamed server still replicates from it.
+// A streamed server re-pointed to another master makes the streamer fail over, see streamerFailoverSource.
+func (this *Migrator) checkStreamerTopology() error {
+	applierServerUUID, err := this.applier.validateWritableMaster()
+	if err != nil {
+		return err
+	}
+	streamedConfig := this.eventsStreamer.getConnectionConfig()
+	if streamedConfig.Key.Equals(&this.migrationContext.ApplierConnectionConfig.Key) {
+		return nil
+	}
+	masterConfig, masterServerUUID, err := this.getTopologyMaster(streamedConfig)
+	if err != nil {
+		return err
+	}
+	if masterServerUUID != applierServerUUID {
+		this.migrationContext.Log.Errorf("%s now replicates from %s, which is not the applier %s. Failing over the binlog streamer", streamedConfig.Key.String(), masterConfig.Key.String(), this.migrationContext.ApplierConnectionConfig.Key.String())
+		this.eventsStreamer.requestFailover()
+	}
+	return nil
+}
+
+// getServerUUID returns the server_uuid of given server
+func getServerUUID(dbCache *mysql.DBCache, connectionConfig *mysql.ConnectionConfig) (serverUUID string, err error) {
+	var db *gosql.DB
+	if db, _, err = dbCache.GetDB(connectionConfig.GetDBUri("information_schema"), connectionConfig); err != nil {
+		return "", err
+	}
+	err = db.QueryRow(`select /* gh-ost */ @@global.server_uuid`).Scan(&serverUUID)
+	return serverUUID, err
+}
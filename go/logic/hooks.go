@@ -11,27 +11,30 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync/atomic"
 
 	"github.com/github/gh-ost/go/base"
-	"github.com/openark/golib/log"
 )
 
 const (
-	onStartup            = "gh-ost-on-startup"
-	onValidated          = "gh-ost-on-validated"
-	onRowCountComplete   = "gh-ost-on-rowcount-complete"
-	onBeforeRowCopy      = "gh-ost-on-before-row-copy"
-	onRowCopyComplete    = "gh-ost-on-row-copy-complete"
-	onBeginPostponed     = "gh-ost-on-begin-postponed"
-	onBeforeCutOver      = "gh-ost-on-before-cut-over"
-	onInteractiveCommand = "gh-ost-on-interactive-command"
-	onSuccess            = "gh-ost-on-success"
-	onFailure            = "gh-ost-on-failure"
-	onBatchCopyRetry     = "gh-ost-on-batch-copy-retry"
-	onStatus             = "gh-ost-on-status"
-	onStopReplication    = "gh-ost-on-stop-replication"
-	onStartReplication   = "gh-ost-on-start-replication"
+	onStartup                = "gh-ost-on-startup"
+	onValidated              = "gh-ost-on-validated"
+	onRowCountComplete       = "gh-ost-on-rowcount-complete"
+	onBeforeRowCopy          = "gh-ost-on-before-row-copy"
+	onRowCopyComplete        = "gh-ost-on-row-copy-complete"
+	onBeginPostponed         = "gh-ost-on-begin-postponed"
+	onBeforeCutOver          = "gh-ost-on-before-cut-over"
+	onInteractiveCommand     = "gh-ost-on-interactive-command"
+	onSuccess                = "gh-ost-on-success"
+	onFailure                = "gh-ost-on-failure"
+	onBatchCopyRetry         = "gh-ost-on-batch-copy-retry"
+	onStatus                 = "gh-ost-on-status"
+	onStopReplication        = "gh-ost-on-stop-replication"
+	onStartReplication       = "gh-ost-on-start-replication"
+	onBinlogRetentionWarning = "gh-ost-on-binlog-retention-warning"
+	onStreamerFailover       = "gh-ost-on-streamer-failover"
+	onDetach                 = "gh-ost-on-detach"
 )
 
 type HooksExecutor struct {
@@ -66,6 +69,9 @@ func (this *HooksExecutor) applyEnvironmentVariables(extraVariables ...string) [
 	env = append(env, fmt.Sprintf("GH_OST_HEARTBEAT_LAG=%f", this.migrationContext.TimeSinceLastHeartbeatOnChangelog().Seconds()))
 	env = append(env, fmt.Sprintf("GH_OST_PROGRESS=%f", this.migrationContext.GetProgressPct()))
 	env = append(env, fmt.Sprintf("GH_OST_ETA_SECONDS=%d", this.migrationContext.GetETASeconds()))
+	etaMinSeconds, etaMaxSeconds := this.migrationContext.GetETARangeSeconds()
+	env = append(env, fmt.Sprintf("GH_OST_ETA_MIN_SECONDS=%d", etaMinSeconds))
+	env = append(env, fmt.Sprintf("GH_OST_ETA_MAX_SECONDS=%d", etaMaxSeconds))
 	env = append(env, fmt.Sprintf("GH_OST_HOOKS_HINT=%s", this.migrationContext.HooksHintMessage))
 	env = append(env, fmt.Sprintf("GH_OST_HOOKS_HINT_OWNER=%s", this.migrationContext.HooksHintOwner))
 	env = append(env, fmt.Sprintf("GH_OST_HOOKS_HINT_TOKEN=%s", this.migrationContext.HooksHintToken))
@@ -85,7 +91,10 @@ func (this *HooksExecutor) executeHook(hook string, extraVariables ...string) er
 
 	combinedOutput, err := cmd.CombinedOutput()
 	fmt.Fprintln(this.writer, string(combinedOutput))
-	return log.Errore(err)
+	if err != nil {
+		return this.migrationContext.Log.Errore(err)
+	}
+	return nil
 }
 
 func (this *HooksExecutor) detectHooks(baseName string) (hooks []string, err error) {
@@ -103,7 +112,7 @@ func (this *HooksExecutor) executeHooks(baseName string, extraVariables ...strin
 		return err
 	}
 	for _, hook := range hooks {
-		log.Infof("executing %+v hook: %+v", baseName, hook)
+		this.migrationContext.Log.Infof("executing %+v hook: %+v", baseName, hook)
 		if err := this.executeHook(hook, extraVariables...); err != nil {
 			return err
 		}
@@ -111,61 +120,121 @@ func (this *HooksExecutor) executeHooks(baseName string, extraVariables ...strin
 	return nil
 }
 
+// notifyEventListener passes a hook event on to the library event listener, if any
+func (this *HooksExecutor) notifyEventListener(event, message string) {
+	if this.migrationContext.EventListener != nil {
+		this.migrationContext.EventListener(event, message)
+	}
+}
+
 func (this *HooksExecutor) onStartup() error {
+	this.notifyEventListener(onStartup, "")
 	return this.executeHooks(onStartup)
 }
 
 func (this *HooksExecutor) onValidated() error {
+	this.notifyEventListener(onValidated, "")
 	return this.executeHooks(onValidated)
 }
 
 func (this *HooksExecutor) onRowCountComplete() error {
+	this.notifyEventListener(onRowCountComplete, "")
 	return this.executeHooks(onRowCountComplete)
 }
 func (this *HooksExecutor) onBeforeRowCopy() error {
+	this.notifyEventListener(onBeforeRowCopy, "")
 	return this.executeHooks(onBeforeRowCopy)
 }
 
 func (this *HooksExecutor) onBatchCopyRetry(errorMessage string) error {
 	v := fmt.Sprintf("GH_OST_LAST_BATCH_COPY_ERROR=%s", errorMessage)
+	this.notifyEventListener(onBatchCopyRetry, errorMessage)
 	return this.executeHooks(onBatchCopyRetry, v)
 }
 
 func (this *HooksExecutor) onRowCopyComplete() error {
+	this.notifyEventListener(onRowCopyComplete, "")
 	return this.executeHooks(onRowCopyComplete)
 }
 
 func (this *HooksExecutor) onBeginPostponed() error {
+	this.notifyEventListener(onBeginPostponed, "")
 	return this.executeHooks(onBeginPostponed)
 }
 
 func (this *HooksExecutor) onBeforeCutOver() error {
+	this.notifyEventListener(onBeforeCutOver, "")
 	return this.executeHooks(onBeforeCutOver)
 }
 
 func (this *HooksExecutor) onInteractiveCommand(command string) error {
 	v := fmt.Sprintf("GH_OST_COMMAND='%s'", command)
+	this.notifyEventListener(onInteractiveCommand, command)
 	return this.executeHooks(onInteractiveCommand, v)
 }
 
 func (this *HooksExecutor) onSuccess(instantDDL bool) error {
 	v := fmt.Sprintf("GH_OST_INSTANT_DDL=%t", instantDDL)
+	this.notifyEventListener(onSuccess, fmt.Sprintf("instant DDL: %t", instantDDL))
 	return this.executeHooks(onSuccess, v)
 }
 
 func (this *HooksExecutor) onFailure() error {
+	this.notifyEventListener(onFailure, "")
 	return this.executeHooks(onFailure)
 }
 
 func (this *HooksExecutor) onStatus(statusMessage string) error {
 	v := fmt.Sprintf("GH_OST_STATUS='%s'", statusMessage)
+	this.notifyEventListener(onStatus, statusMessage)
 	return this.executeHooks(onStatus, v)
 }
 
+func (this *HooksExecutor) onBinlogRetentionWarning(remainingSeconds int64) error {
+	v := fmt.Sprintf("GH_OST_BINLOG_RETENTION_REMAINING_SECONDS=%d", remainingSeconds)
+	this.notifyEventListener(onBinlogRetentionWarning, fmt.Sprintf("binlog retention remaining seconds: %d", remainingSeconds))
+	return this.executeHooks(onBinlogRetentionWarning, v)
+}
+
+// onStreamerFailover runs the streamer failover hooks. Hooks may name servers to fail over to, one
+// host[:port] per line of their standard output; their standard error is printed to the configured writer.
+func (this *HooksExecutor) onStreamerFailover(failedHost string) (hostnames []string, err error) {
+	v := fmt.Sprintf("GH_OST_FAILED_STREAMER_HOST=%s", failedHost)
+	this.notifyEventListener(onStreamerFailover, failedHost)
+	hooks, err := this.detectHooks(onStreamerFailover)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		this.migrationContext.Log.Infof("executing %+v hook: %+v", onStreamerFailover, hook)
+		cmd := exec.Command(hook)
+		cmd.Env = this.applyEnvironmentVariables(v)
+		cmd.Stderr = this.writer
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, this.migrationContext.Log.Errore(err)
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if hostname := strings.TrimSpace(line); hostname != "" {
+				hostnames = append(hostnames, hostname)
+			}
+		}
+	}
+	return hostnames, nil
+}
+
+func (this *HooksExecutor) onDetach(coordinates string) error {
+	v := fmt.Sprintf("GH_OST_DETACHED_COORDINATES=%s", coordinates)
+	this.notifyEventListener(onDetach, coordinates)
+	return this.executeHooks(onDetach, v)
+}
+
 func (this *HooksExecutor) onStopReplication() error {
+	this.notifyEventListener(onStopReplication, "")
 	return this.executeHooks(onStopReplication)
 }
 
 func (this *HooksExecutor) onStartReplication() error {
+	this.notifyEventListener(onStartReplication, "")
 	return this.executeHooks(onStartReplication)
 }
@@ -31,6 +31,7 @@ func TestHooksExecutorExecuteHooks(t *testing.T) {
 	migrationContext.RowsEstimate = 122
 	migrationContext.TotalRowsCopied = 123456
 	migrationContext.SetETADuration(time.Minute)
+	migrationContext.SetETARange(45*time.Second, time.Duration(base.ETAUnknown))
 	migrationContext.SetProgressPct(50)
 	hooksExecutor := NewHooksExecutor(migrationContext)
 
@@ -94,6 +95,12 @@ func TestHooksExecutorExecuteHooks(t *testing.T) {
 			case "GH_OST_ETA_SECONDS":
 				etaSeconds, _ := strconv.ParseInt(split[1], 10, 64)
 				require.Equal(t, int64(60), etaSeconds)
+			case "GH_OST_ETA_MIN_SECONDS":
+				etaMinSeconds, _ := strconv.ParseInt(split[1], 10, 64)
+				require.Equal(t, int64(45), etaMinSeconds)
+			case "GH_OST_ETA_MAX_SECONDS":
+				etaMaxSeconds, _ := strconv.ParseInt(split[1], 10, 64)
+				require.Equal(t, int64(base.ETAUnknown), etaMaxSeconds)
 			case "GH_OST_EXECUTING_HOST":
 				require.Equal(t, migrationContext.Hostname, split[1])
 			case "GH_OST_GHOST_TABLE_NAME":
@@ -112,4 +119,19 @@ func TestHooksExecutorExecuteHooks(t *testing.T) {
 			}
 		}
 	})
+	t.Run("streamer-failover", func(t *testing.T) {
+		var err error
+		if migrationContext.HooksPath, err = writeTmpHookFunc(
+			"TestHooksExecutorExecuteHooks-streamer-failover",
+			onStreamerFailover,
+			"#!/bin/sh\necho \"replica-$GH_OST_FAILED_STREAMER_HOST\"\necho\necho other-replica:3307",
+		); err != nil {
+			panic(err)
+		}
+		defer os.RemoveAll(migrationContext.HooksPath)
+
+		hostnames, err := hooksExecutor.onStreamerFailover("failed:3306")
+		require.NoError(t, err)
+		require.Equal(t, []string{"replica-failed:3306", "other-replica:3307"}, hostnames)
+	})
 }
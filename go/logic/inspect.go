@@ -12,7 +12,6 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"sync/atomic"
 	"time"
 
 	"github.com/github/gh-ost/go/base"
@@ -46,12 +45,12 @@ func NewInspector(migrationContext *base.MigrationContext) *Inspector {
 
 func (this *Inspector) InitDBConnections() (err error) {
 	inspectorUri := this.connectionConfig.GetDBUri(this.migrationContext.DatabaseName)
-	if this.db, _, err = mysql.GetDB(this.migrationContext.Uuid, inspectorUri); err != nil {
+	if this.db, _, err = this.migrationContext.DBCache.GetDB(inspectorUri, this.connectionConfig); err != nil {
 		return err
 	}
 
 	informationSchemaUri := this.connectionConfig.GetDBUri("information_schema")
-	if this.informationSchemaDb, _, err = mysql.GetDB(this.migrationContext.Uuid, informationSchemaUri); err != nil {
+	if this.informationSchemaDb, _, err = this.migrationContext.DBCache.GetDB(informationSchemaUri, this.connectionConfig); err != nil {
 		return err
 	}
 
@@ -59,6 +58,12 @@ func (this *Inspector) InitDBConnections() (err error) {
 		return err
 	}
 	this.dbVersion = this.migrationContext.InspectorMySQLVersion
+	if err := this.readTableNamesCaseSensitivity(); err != nil {
+		return err
+	}
+	if err := this.db.QueryRow(`select /* gh-ost */ @@global.server_uuid`).Scan(&this.migrationContext.InspectorServerUUID); err != nil {
+		return err
+	}
 
 	if !this.migrationContext.AliyunRDS && !this.migrationContext.GoogleCloudPlatform && !this.migrationContext.AzureMySQL {
 		if impliedKey, err := mysql.GetInstanceKey(this.db); err != nil {
@@ -70,6 +75,9 @@ func (this *Inspector) InitDBConnections() (err error) {
 	if err := this.validateGrants(); err != nil {
 		return err
 	}
+	if err := this.resolveReplicationChannel(); err != nil {
+		return err
+	}
 	if err := this.validateBinlogs(); err != nil {
 		return err
 	}
@@ -85,6 +93,35 @@ func (this *Inspector) InitDBConnections() (err error) {
 	return nil
 }
 
+// failOver re-points the inspector to given server, which the binlog streamer fails over to, so that
+// replication lag, heartbeats and binary log retention are read off the streamed server.
+func (this *Inspector) failOver(connectionConfig *mysql.ConnectionConfig) error {
+	db, _, err := this.migrationContext.DBCache.GetDB(connectionConfig.GetDBUri(this.migrationContext.DatabaseName), connectionConfig)
+	if err != nil {
+		return err
+	}
+	informationSchemaDb, _, err := this.migrationContext.DBCache.GetDB(connectionConfig.GetDBUri("information_schema"), connectionConfig)
+	if err != nil {
+		return err
+	}
+	version, err := base.ValidateConnection(db, connectionConfig, this.migrationContext, this.name)
+	if err != nil {
+		return err
+	}
+	if !this.migrationContext.AliyunRDS && !this.migrationContext.GoogleCloudPlatform && !this.migrationContext.AzureMySQL {
+		if connectionConfig.ImpliedKey, err = mysql.GetInstanceKey(db); err != nil {
+			return err
+		}
+	}
+	this.migrationContext.Log.Infof("Inspector failing over from %+v to %+v", this.connectionConfig.Key, connectionConfig.Key)
+	this.connectionConfig = connectionConfig
+	this.db = db
+	this.informationSchemaDb = informationSchemaDb
+	this.dbVersion = version
+	this.migrationContext.InspectorConnectionConfig = connectionConfig
+	return nil
+}
+
 func (this *Inspector) ValidateOriginalTable() (err error) {
 	if err := this.validateTable(); err != nil {
 		return err
@@ -106,6 +143,15 @@ func (this *Inspector) InspectTableColumnsAndUniqueKeys(databaseName, tableName
 	if err != nil {
 		return columns, virtualColumns, uniqueKeys, err
 	}
+	if len(uniqueKeys) == 0 && this.migrationContext.NonUniqueKeyAllowed {
+		if uniqueKeys, err = this.getCandidateNonUniqueKeys(databaseName, tableName); err != nil {
+			return columns, virtualColumns, uniqueKeys, err
+		}
+		if len(uniqueKeys) == 0 {
+			return columns, virtualColumns, uniqueKeys, fmt.Errorf("No PRIMARY, UNIQUE nor non-unique key found in table! Bailing out")
+		}
+		this.migrationContext.Log.Warningf("No PRIMARY nor UNIQUE key found in %s. As instructed by --allow-non-unique-key, will consider non-unique keys", sql.EscapeName(tableName))
+	}
 	if len(uniqueKeys) == 0 {
 		return columns, virtualColumns, uniqueKeys, fmt.Errorf("No PRIMARY nor UNIQUE key found in table! Bailing out")
 	}
@@ -142,10 +188,36 @@ func (this *Inspector) inspectOriginalAndGhostTables() (err error) {
 	if err != nil {
 		return err
 	}
-	sharedUniqueKeys := this.getSharedUniqueKeys(this.migrationContext.OriginalTableUniqueKeys, this.migrationContext.GhostTableUniqueKeys)
+	ghostTableKeys := this.migrationContext.GhostTableUniqueKeys
+	if this.originalTableHasNonUniqueKeys() && len(ghostTableKeys) > 0 && !ghostTableKeys[0].IsNonUnique {
+		// The ALTER adds a unique key to a table that has none. We still migrate by a non-unique key.
+		ghostTableNonUniqueKeys, err := this.getCandidateNonUniqueKeys(this.migrationContext.GetGhostDatabaseName(), this.migrationContext.GetGhostTableName())
+		if err != nil {
+			return err
+		}
+		ghostTableKeys = append(ghostTableKeys, ghostTableNonUniqueKeys...)
+	}
+	sharedUniqueKeys := this.getSharedUniqueKeys(this.migrationContext.OriginalTableUniqueKeys, ghostTableKeys)
+	candidateKeys := sharedUniqueKeys
+	if this.migrationContext.MigrationKey != "" {
+		migrationKey, err := this.getMigrationKey(sharedUniqueKeys)
+		if err != nil {
+			if this.migrationContext.Noop {
+				this.reportMigrationKeyCandidates(candidateKeys)
+			}
+			return err
+		}
+		sharedUniqueKeys = []*sql.UniqueKey{migrationKey}
+	}
 	for i, sharedUniqueKey := range sharedUniqueKeys {
 		this.applyColumnTypes(this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName, &sharedUniqueKey.Columns)
 		uniqueKeyIsValid := true
+		if sharedUniqueKey.IsNonUnique {
+			if err := this.validateNonUniqueKey(sharedUniqueKey); err != nil {
+				this.migrationContext.Log.Warningf("Will not use %+v as shared key: %+v", sharedUniqueKey.Name, err)
+				continue
+			}
+		}
 		for _, column := range sharedUniqueKey.Columns.Columns() {
 			switch column.Type {
 			case sql.FloatColumnType:
@@ -171,6 +243,9 @@ func (this *Inspector) inspectOriginalAndGhostTables() (err error) {
 		return fmt.Errorf("No shared unique key can be found after ALTER! Bailing out")
 	}
 	this.migrationContext.Log.Infof("Chosen shared unique key is %s", this.migrationContext.UniqueKey.Name)
+	if this.migrationContext.Noop {
+		this.reportMigrationKeyCandidates(candidateKeys)
+	}
 	if this.migrationContext.UniqueKey.HasNullable {
 		if this.migrationContext.NullableUniqueKeyAllowed {
 			this.migrationContext.Log.Warningf("Chosen key (%s) has nullable columns. You have supplied with --allow-nullable-unique-key and so this migration proceeds. As long as there aren't NULL values in this key's column, migration should be fine. NULL values will corrupt migration's data", this.migrationContext.UniqueKey)
@@ -181,6 +256,9 @@ func (this *Inspector) inspectOriginalAndGhostTables() (err error) {
 
 	this.migrationContext.SharedColumns, this.migrationContext.MappedSharedColumns = this.getSharedColumns(this.migrationContext.OriginalTableColumns, this.migrationContext.GhostTableColumns, this.migrationContext.OriginalTableVirtualColumns, this.migrationContext.GhostTableVirtualColumns, this.migrationContext.ColumnRenameMap)
 	this.migrationContext.Log.Infof("Shared columns are %s", this.migrationContext.SharedColumns)
+	if this.migrationContext.MigrationKeyUpdatedInPlace = this.ghostPrimaryKeyHasNewColumns(); this.migrationContext.MigrationKeyUpdatedInPlace {
+		this.migrationContext.Log.Infof("Ghost table's primary key has columns not in the original table; updates of %s will be applied in place", this.migrationContext.UniqueKey.Name)
+	}
 	// By fact that a non-empty unique key exists we also know the shared columns are non-empty
 
 	// This additional step looks at which columns are unsigned. We could have merged this within
@@ -204,6 +282,15 @@ func (this *Inspector) inspectOriginalAndGhostTables() (err error) {
 		}
 	}
 
+	if this.migrationContext.UniqueKey.IsNonUnique {
+		this.migrationContext.Log.Warningf("Chosen key (%s) is non-unique. DML events will be applied by full row image. Please read the caveats of --allow-non-unique-key", this.migrationContext.UniqueKey)
+		for _, column := range this.migrationContext.SharedColumns.Columns() {
+			if column.Type == sql.FloatColumnType || column.Type == sql.JSONColumnType {
+				return fmt.Errorf("Column %s is of type %s, which cannot be matched by full row image. Migrating by non-unique key is not supported for this table. Bailing out", sql.EscapeName(column.Name), column.MySQLType)
+			}
+		}
+	}
+
 	for _, column := range this.migrationContext.UniqueKey.Columns.Columns() {
 		if this.migrationContext.GhostTableVirtualColumns.GetColumn(column.Name) != nil {
 			// this is a virtual column
@@ -214,6 +301,195 @@ func (this *Inspector) inspectOriginalAndGhostTables() (err error) {
 		}
 	}
 
+	if err := this.validateBinlogRowImage(); err != nil {
+		return err
+	}
+	if this.migrationContext.CoalesceDMLEvents {
+		if err := this.validateCoalesceDMLEvents(); err != nil {
+			this.migrationContext.Log.Warningf("Disabling --coalesce-dml-events: %+v", err)
+			this.migrationContext.CoalesceDMLEvents = false
+		}
+	}
+	return nil
+}
+
+// getMigrationKey returns the key given by --migration-key among the shared unique keys. The key must be a
+// unique key of the original table, with no nullable columns, which remains unique and non-nullable on
+// the ghost table.
+func (this *Inspector) getMigrationKey(sharedUniqueKeys []*sql.UniqueKey) (*sql.UniqueKey, error) {
+	keyName := this.migrationContext.MigrationKey
+	var migrationKey *sql.UniqueKey
+	for _, uniqueKey := range this.migrationContext.OriginalTableUniqueKeys {
+		if strings.EqualFold(uniqueKey.Name, keyName) && !uniqueKey.IsNonUnique {
+			migrationKey = uniqueKey
+			break
+		}
+	}
+	if migrationKey == nil {
+		return nil, fmt.Errorf("--migration-key %s is not a unique key of %s. Bailing out", keyName, sql.EscapeName(this.migrationContext.OriginalTableName))
+	}
+	if migrationKey.HasNullable {
+		return nil, fmt.Errorf("--migration-key %s has nullable columns on %s. Bailing out", migrationKey.Name, sql.EscapeName(this.migrationContext.OriginalTableName))
+	}
+	isShared := false
+	for _, sharedUniqueKey := range sharedUniqueKeys {
+		if sharedUniqueKey == migrationKey {
+			isShared = true
+			break
+		}
+	}
+	if !isShared {
+		return nil, fmt.Errorf("--migration-key %s is not unique on the ghost table after ALTER. Bailing out", migrationKey.Name)
+	}
+	if err := this.applyColumnTypes(this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName, &migrationKey.Columns); err != nil {
+		return nil, err
+	}
+	for _, column := range migrationKey.Columns.Columns() {
+		if column.Type == sql.FloatColumnType || column.Type == sql.JSONColumnType {
+			return nil, fmt.Errorf("--migration-key %s has column %s of type %s, which cannot serve as migration key. Bailing out", migrationKey.Name, sql.EscapeName(column.Name), column.MySQLType)
+		}
+	}
+	ghostKeyColumns := sql.NewColumnList(migrationKey.Columns.Names())
+	if err := this.applyColumnTypes(this.migrationContext.GetGhostDatabaseName(), this.migrationContext.GetGhostTableName(), ghostKeyColumns); err != nil {
+		return nil, err
+	}
+	for _, column := range ghostKeyColumns.Columns() {
+		if column.Nullable {
+			return nil, fmt.Errorf("--migration-key %s has column %s, which is nullable on the ghost table after ALTER. Bailing out", migrationKey.Name, sql.EscapeName(column.Name))
+		}
+	}
+	return migrationKey, nil
+}
+
+// reportMigrationKeyCandidates lists the shared keys by which the migration could iterate the table, with
+// their estimated cardinality, as a hint for --migration-key
+func (this *Inspector) reportMigrationKeyCandidates(candidateKeys []*sql.UniqueKey) {
+	cardinalities, err := this.getIndexCardinalities(this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName)
+	if err != nil {
+		this.migrationContext.Log.Warningf("Cannot read index cardinalities of %s: %+v", sql.EscapeName(this.migrationContext.OriginalTableName), err)
+	}
+	this.migrationContext.Log.Infof("Noop: %d candidate migration key(s); pick one with --migration-key", len(candidateKeys))
+	for _, candidateKey := range candidateKeys {
+		this.applyColumnTypes(this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName, &candidateKey.Columns)
+		cardinality, ok := cardinalities[candidateKey.Name]
+		if !ok {
+			cardinality = -1
+		}
+		this.migrationContext.Log.Infof("Noop: %s", describeMigrationKeyCandidate(candidateKey, cardinality, candidateKey == this.migrationContext.UniqueKey))
+	}
+}
+
+// describeMigrationKeyCandidate describes a candidate migration key, its estimated cardinality (negative when
+// unknown), and what would disqualify it
+func describeMigrationKeyCandidate(candidateKey *sql.UniqueKey, cardinality int64, isChosen bool) string {
+	columns := make([]string, 0, candidateKey.Len())
+	for _, column := range candidateKey.Columns.Columns() {
+		columns = append(columns, strings.TrimSpace(fmt.Sprintf("%s %s", sql.EscapeName(column.Name), column.MySQLType)))
+	}
+	description := fmt.Sprintf("%s (%s)", sql.EscapeName(candidateKey.Name), strings.Join(columns, ", "))
+	if cardinality >= 0 {
+		description = fmt.Sprintf("%s; estimated cardinality: %d", description, cardinality)
+	} else {
+		description = fmt.Sprintf("%s; estimated cardinality: unknown", description)
+	}
+	notes := []string{}
+	if isChosen {
+		notes = append(notes, "chosen")
+	}
+	if candidateKey.IsNonUnique {
+		notes = append(notes, "non-unique")
+	}
+	if candidateKey.HasNullable {
+		notes = append(notes, "has nullable columns")
+	}
+	for _, column := range candidateKey.Columns.Columns() {
+		if column.Type == sql.FloatColumnType || column.Type == sql.JSONColumnType {
+			notes = append(notes, fmt.Sprintf("%s cannot serve as key", sql.EscapeName(column.Name)))
+		}
+	}
+	if len(notes) > 0 {
+		description = fmt.Sprintf("%s; %s", description, strings.Join(notes, ", "))
+	}
+	return description
+}
+
+// getIndexCardinalities returns the estimated cardinality of each of a table's indexes, as reported by
+// the server's index statistics
+func (this *Inspector) getIndexCardinalities(databaseName, tableName string) (cardinalities map[string]int64, err error) {
+	cardinalities = make(map[string]int64)
+	query := fmt.Sprintf(`
+		SELECT /* gh-ost */
+			INDEX_NAME,
+			MAX(CARDINALITY) AS CARDINALITY
+		FROM
+			INFORMATION_SCHEMA.STATISTICS
+		WHERE
+			TABLE_SCHEMA = ?
+			AND %s
+		GROUP BY
+			INDEX_NAME`, this.tableNameIs("TABLE_NAME"))
+	err = sqlutils.QueryRowsMap(this.db, query, func(m sqlutils.RowMap) error {
+		if cardinality := m.GetNullInt64("CARDINALITY"); cardinality.Valid {
+			cardinalities[m.GetString("INDEX_NAME")] = cardinality.Int64
+		}
+		return nil
+	}, databaseName, tableName)
+	return cardinalities, err
+}
+
+// ghostPrimaryKeyHasNewColumns checks whether the ghost table's primary key has columns which are not
+// populated from the original table, e.g. a surrogate AUTO_INCREMENT key replacing a composite one.
+func (this *Inspector) ghostPrimaryKeyHasNewColumns() bool {
+	for _, uniqueKey := range this.migrationContext.GhostTableUniqueKeys {
+		if !uniqueKey.IsPrimary() {
+			continue
+		}
+		for _, column := range uniqueKey.Columns.Names() {
+			if this.migrationContext.MappedSharedColumns.GetColumn(column) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateBinlogRowImage checks that binlog events will have what applying them requires, when the
+// binlog_row_image is not FULL. Events on a non-unique key are matched by full row image. With MINIMAL,
+// before images only have the PRIMARY KEY's columns, which must then be the migration key.
+func (this *Inspector) validateBinlogRowImage() error {
+	rowImage := this.migrationContext.OriginalBinlogRowImage
+	if rowImage == "" || rowImage == "FULL" {
+		return nil
+	}
+	if this.migrationContext.UniqueKey.IsNonUnique {
+		return fmt.Errorf("Migrating by non-unique key %s requires binlog_row_image=FULL, but it is '%s'. Bailing out", this.migrationContext.UniqueKey.Name, rowImage)
+	}
+	if rowImage == "MINIMAL" && !this.migrationContext.UniqueKey.IsPrimary() {
+		for _, uniqueKey := range this.migrationContext.OriginalTableUniqueKeys {
+			if uniqueKey.IsPrimary() {
+				return fmt.Errorf("With binlog_row_image=MINIMAL the migration key must be the PRIMARY KEY, as the before images of updates and deletes have no other columns, but chosen key is %s. Bailing out", this.migrationContext.UniqueKey.Name)
+			}
+		}
+	}
+	if this.migrationContext.CoalesceDMLEvents {
+		this.migrationContext.Log.Warningf("Disabling --coalesce-dml-events: binlog_row_image is '%s', and partial row images cannot be coalesced", rowImage)
+		this.migrationContext.CoalesceDMLEvents = false
+	}
+	return nil
+}
+
+// validateCoalesceDMLEvents checks that DML events on different rows of a batch may be reordered, as
+// coalescing does. This holds when the ghost table has no unique key other than the migration key.
+func (this *Inspector) validateCoalesceDMLEvents() error {
+	if this.migrationContext.UniqueKey.IsNonUnique {
+		return fmt.Errorf("chosen key %s is non-unique", this.migrationContext.UniqueKey.Name)
+	}
+	for _, uniqueKey := range this.migrationContext.GhostTableUniqueKeys {
+		if uniqueKey.IsNonUnique || uniqueKey.Name == this.migrationContext.UniqueKey.NameInGhostTable {
+			continue
+		}
+		return fmt.Errorf("ghost table has unique key %s other than the migration key %s", uniqueKey.Name, this.migrationContext.UniqueKey.Name)
+	}
 	return nil
 }
 
@@ -224,6 +500,28 @@ func (this *Inspector) validateConnection() error {
 	return err
 }
 
+// readTableNamesCaseSensitivity reads whether the server's table names are case sensitive. With
+// lower_case_table_names=0, tables named MyTable and MYtable may coexist in the same schema.
+func (this *Inspector) readTableNamesCaseSensitivity() error {
+	var lowerCaseTableNames int
+	if err := this.db.QueryRow(`select /* gh-ost */ @@global.lower_case_table_names`).Scan(&lowerCaseTableNames); err != nil {
+		return err
+	}
+	this.migrationContext.TableNamesCaseSensitive = (lowerCaseTableNames == 0)
+	this.migrationContext.Log.Debugf("lower_case_table_names=%d; table names are case sensitive: %t", lowerCaseTableNames, this.migrationContext.TableNamesCaseSensitive)
+	return nil
+}
+
+// tableNameIs returns a condition comparing given information_schema column with a table name
+// argument. information_schema may compare names case insensitively (e.g. on MySQL 5.7), so with
+// case sensitive table names the comparison is made case-exact.
+func (this *Inspector) tableNameIs(column string) string {
+	if this.migrationContext.TableNamesCaseSensitive {
+		return fmt.Sprintf("%s = cast(? as binary)", column)
+	}
+	return fmt.Sprintf("%s = ?", column)
+}
+
 // validateGrants verifies the user by which we're executing has necessary grants
 // to do its thing.
 func (this *Inspector) validateGrants() error {
@@ -340,7 +638,7 @@ func (this *Inspector) formatRequiredDatabases(databaseNames []string) string {
 func (this *Inspector) restartReplication() error {
 	this.migrationContext.Log.Infof("Restarting replication on %s to make sure binlog settings apply to replication thread", this.connectionConfig.Key.String())
 
-	masterKey, _ := mysql.GetMasterKeyFromSlaveStatus(this.dbVersion, this.connectionConfig)
+	masterKey, _ := mysql.GetMasterKeyFromSlaveStatus(this.dbVersion, this.connectionConfig, this.migrationContext.ReplicationChannel)
 	if masterKey == nil {
 		// This is not a replica
 		return nil
@@ -348,8 +646,9 @@ func (this *Inspector) restartReplication() error {
 
 	var stopError, startError error
 	replicaTerm := mysql.ReplicaTermFor(this.dbVersion, `slave`)
-	_, stopError = sqlutils.ExecNoPrepare(this.db, fmt.Sprintf("stop %s", replicaTerm))
-	_, startError = sqlutils.ExecNoPrepare(this.db, fmt.Sprintf("start %s", replicaTerm))
+	channelClause := mysql.ReplicationChannelClause(this.migrationContext.ReplicationChannel)
+	_, stopError = sqlutils.ExecNoPrepare(this.db, fmt.Sprintf("stop %s%s", replicaTerm, channelClause))
+	_, startError = sqlutils.ExecNoPrepare(this.db, fmt.Sprintf("start %s%s", replicaTerm, channelClause))
 	if stopError != nil {
 		return stopError
 	}
@@ -382,7 +681,7 @@ func (this *Inspector) restartReplication() error {
 // returns true if both are 'Yes', false otherwise
 func (this *Inspector) validateReplicationRestarted() (bool, error) {
 	errNotRunning := fmt.Errorf("Replication not running on %s", this.connectionConfig.Key.String())
-	query := fmt.Sprintf("show /* gh-ost */ %s", mysql.ReplicaTermFor(this.dbVersion, "slave status"))
+	query := mysql.ShowReplicaStatusQuery(this.dbVersion, this.migrationContext.ReplicationChannel)
 	err := sqlutils.QueryRowsMap(this.db, query, func(rowMap sqlutils.RowMap) error {
 		ioRunningTerm := mysql.ReplicaTermFor(this.dbVersion, "Slave_IO_Running")
 		sqlRunningTerm := mysql.ReplicaTermFor(this.dbVersion, "Slave_SQL_Running")
@@ -463,8 +762,24 @@ func (this *Inspector) validateBinlogs() error {
 		return err
 	}
 	this.migrationContext.OriginalBinlogRowImage = strings.ToUpper(this.migrationContext.OriginalBinlogRowImage)
-	if this.migrationContext.OriginalBinlogRowImage != "FULL" {
-		return fmt.Errorf("%s has '%s' binlog_row_image, and only 'FULL' is supported. This operation cannot proceed. You may `set global binlog_row_image='full'` and try again", this.connectionConfig.Key.String(), this.migrationContext.OriginalBinlogRowImage)
+	switch this.migrationContext.OriginalBinlogRowImage {
+	case "FULL":
+	case "MINIMAL", "NOBLOB":
+		this.migrationContext.Log.Infof("%s has '%s' binlog_row_image. Updates will be applied by the columns they log, and inserts must log all columns", this.connectionConfig.Key.String(), this.migrationContext.OriginalBinlogRowImage)
+	default:
+		return fmt.Errorf("%s has '%s' binlog_row_image, and only 'FULL', 'MINIMAL' and 'NOBLOB' are supported. This operation cannot proceed. You may `set global binlog_row_image='full'` and try again", this.connectionConfig.Key.String(), this.migrationContext.OriginalBinlogRowImage)
+	}
+	// binlog_encryption only exists as of MySQL 8.0.14. The server decrypts binary logs
+	// before sending them to replicas, and so to gh-ost.
+	query = `show /* gh-ost */ global variables like 'binlog_encryption'`
+	err := sqlutils.QueryRowsMap(this.db, query, func(rowMap sqlutils.RowMap) error {
+		if strings.EqualFold(rowMap.GetString("Value"), "ON") {
+			this.migrationContext.Log.Infof("%s has binlog_encryption enabled. Binary logs are decrypted by the server as they are streamed", this.connectionConfig.Key.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	this.migrationContext.Log.Infof("binary logs validated on %s", this.connectionConfig.Key.String())
@@ -509,6 +824,10 @@ func (this *Inspector) validateLogSlaveUpdates() error {
 		return fmt.Errorf("%s must have log_slave_updates enabled for testing/migrating on replica", this.connectionConfig.Key.String())
 	}
 
+	if this.migrationContext.ActiveActivePeerHostname != "" {
+		return fmt.Errorf("%s must have log_slave_updates enabled with --active-active-peer, so that changes made on the peer are streamed", this.connectionConfig.Key.String())
+	}
+
 	if this.migrationContext.InspectorIsAlsoApplier() {
 		this.migrationContext.Log.Warningf("log_slave_updates not found on %s, but executing directly on master, so I'm proceeding", this.connectionConfig.Key.String())
 		return nil
@@ -523,6 +842,10 @@ func (this *Inspector) validateTable() error {
 
 	tableFound := false
 	err := sqlutils.QueryRowsMap(this.db, query, func(rowMap sqlutils.RowMap) error {
+		// LIKE may also match tables whose name differs by case, or by the '_' wildcard
+		if !this.migrationContext.TableNamesEqual(rowMap.GetString("Name"), this.migrationContext.OriginalTableName) {
+			return nil
+		}
 		this.migrationContext.TableEngine = rowMap.GetString("Engine")
 		this.migrationContext.RowsEstimate = rowMap.GetInt64("Rows")
 		this.migrationContext.UsedRowsEstimateMethod = base.TableStatusRowsEstimate
@@ -544,25 +867,55 @@ func (this *Inspector) validateTable() error {
 	return nil
 }
 
+// readDDLTableInfo reads the properties of the original table which limit the algorithms by which it can be altered
+func (this *Inspector) readDDLTableInfo() (*sql.DDLTableInfo, error) {
+	tableInfo := &sql.DDLTableInfo{}
+	query := fmt.Sprintf(`select /* gh-ost */ ifnull(row_format, '') from information_schema.tables where table_schema = ? and %s`, this.tableNameIs("table_name"))
+	if err := this.db.QueryRow(query, this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName).Scan(&tableInfo.RowFormat); err != nil {
+		return nil, err
+	}
+	query = fmt.Sprintf(`select /* gh-ost */ count(*) > 0 from information_schema.statistics where table_schema = ? and %s and index_type = 'FULLTEXT'`, this.tableNameIs("table_name"))
+	if err := this.db.QueryRow(query, this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName).Scan(&tableInfo.HasFulltextIndex); err != nil {
+		return nil, err
+	}
+	// total_row_versions is only found as of MySQL 8.0.29, which is where it matters
+	query = `select /* gh-ost */ total_row_versions from information_schema.innodb_tables where name = ?`
+	innodbTableName := fmt.Sprintf("%s/%s", this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName)
+	if err := this.db.QueryRow(query, innodbTableName).Scan(&tableInfo.TotalRowVersions); err != nil {
+		this.migrationContext.Log.Debugf("Cannot read total row versions of %s: %+v", innodbTableName, err)
+	}
+	return tableInfo, nil
+}
+
+// predictDDLAlgorithm predicts the best algorithm by which the server can apply the alter statement onto
+// the original table, by its parsed operations, the server version and the table's properties
+func (this *Inspector) predictDDLAlgorithm(alterTokens []string) (*sql.DDLAlgorithmPrediction, error) {
+	tableInfo, err := this.readDDLTableInfo()
+	if err != nil {
+		return nil, err
+	}
+	return sql.PredictDDLAlgorithm(alterTokens, this.migrationContext.InspectorMySQLVersion, tableInfo), nil
+}
+
 // validateTableForeignKeys makes sure no foreign keys exist on the migrated table
 func (this *Inspector) validateTableForeignKeys(allowChildForeignKeys bool) error {
 	if this.migrationContext.SkipForeignKeyChecks {
 		this.migrationContext.Log.Warning("--skip-foreign-key-checks provided: will not check for foreign keys")
 		return nil
 	}
-	query := `
+	query := fmt.Sprintf(`
 		SELECT /* gh-ost */
-			SUM(REFERENCED_TABLE_NAME IS NOT NULL AND TABLE_SCHEMA=? AND TABLE_NAME=?) as num_child_side_fk,
-			SUM(REFERENCED_TABLE_NAME IS NOT NULL AND REFERENCED_TABLE_SCHEMA=? AND REFERENCED_TABLE_NAME=?) as num_parent_side_fk
+			SUM(REFERENCED_TABLE_NAME IS NOT NULL AND TABLE_SCHEMA=? AND %s) as num_child_side_fk,
+			SUM(REFERENCED_TABLE_NAME IS NOT NULL AND REFERENCED_TABLE_SCHEMA=? AND %s) as num_parent_side_fk
 		FROM
 			INFORMATION_SCHEMA.KEY_COLUMN_USAGE
 		WHERE
 			REFERENCED_TABLE_NAME IS NOT NULL
 			AND (
-				(TABLE_SCHEMA=? AND TABLE_NAME=?)
+				(TABLE_SCHEMA=? AND %s)
 				OR
-				(REFERENCED_TABLE_SCHEMA=? AND REFERENCED_TABLE_NAME=?)
-			)`
+				(REFERENCED_TABLE_SCHEMA=? AND %s)
+			)`, this.tableNameIs("TABLE_NAME"), this.tableNameIs("REFERENCED_TABLE_NAME"), this.tableNameIs("TABLE_NAME"), this.tableNameIs("REFERENCED_TABLE_NAME"))
 	numParentForeignKeys := 0
 	numChildForeignKeys := 0
 	err := sqlutils.QueryRowsMap(this.db, query, func(m sqlutils.RowMap) error {
@@ -598,13 +951,13 @@ func (this *Inspector) validateTableForeignKeys(allowChildForeignKeys bool) erro
 
 // validateTableTriggers makes sure no triggers exist on the migrated table. if --include_triggers is used then it fetches the triggers
 func (this *Inspector) validateTableTriggers() error {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT /* gh-ost */ COUNT(*) AS num_triggers
 		FROM
 			INFORMATION_SCHEMA.TRIGGERS
 		WHERE
 			TRIGGER_SCHEMA=?
-			AND EVENT_OBJECT_TABLE=?`
+			AND %s`, this.tableNameIs("EVENT_OBJECT_TABLE"))
 	numTriggers := 0
 	err := sqlutils.QueryRowsMap(this.db, query, func(rowMap sqlutils.RowMap) error {
 		numTriggers = rowMap.GetInt("num_triggers")
@@ -628,10 +981,13 @@ func (this *Inspector) validateTableTriggers() error {
 					sql.EscapeName(this.migrationContext.DatabaseName),
 				)
 			}
-			this.migrationContext.Triggers, err = mysql.GetTriggers(this.db, this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName)
+			this.migrationContext.Triggers, err = mysql.GetTriggers(this.db, this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName, this.migrationContext.TableNamesCaseSensitive)
 			if err != nil {
 				return err
 			}
+			if err := this.validateTriggerWrites(); err != nil {
+				return err
+			}
 			if err := this.validateGhostTriggersDontExist(); err != nil {
 				return err
 			}
@@ -646,6 +1002,60 @@ func (this *Inspector) validateTableTriggers() error {
 	return nil
 }
 
+// validateTriggerWrites analyzes the tables the original table's triggers write to. A trigger may not
+// write to the table it is defined on, whether directly or through another table's trigger: MySQL
+// fails such statements, and gh-ost cannot mirror those writes onto the ghost table. Writes to other
+// tables are side effects of the original table's writes: during the migration they are made by the
+// original triggers, and the ghost table's triggers only take over at cut-over.
+func (this *Inspector) validateTriggerWrites() error {
+	isMigratedTable := func(databaseName string, target sql.TriggerWriteTarget) bool {
+		if target.DatabaseName != "" {
+			databaseName = target.DatabaseName
+		}
+		return this.migrationContext.TableNamesEqual(databaseName, this.migrationContext.DatabaseName) &&
+			this.migrationContext.TableNamesEqual(target.TableName, this.migrationContext.OriginalTableName)
+	}
+	for _, trigger := range this.migrationContext.Triggers {
+		for _, target := range sql.ParseTriggerWriteTargets(trigger.Statement) {
+			if isMigratedTable(this.migrationContext.DatabaseName, target) {
+				return this.migrationContext.Log.Errorf("Trigger %s writes to %s.%s, the table it is defined on. Such triggers are unsupported. Bailing out",
+					sql.EscapeName(trigger.Name),
+					sql.EscapeName(this.migrationContext.DatabaseName),
+					sql.EscapeName(this.migrationContext.OriginalTableName),
+				)
+			}
+			targetDatabaseName := target.DatabaseName
+			if targetDatabaseName == "" {
+				targetDatabaseName = this.migrationContext.DatabaseName
+			}
+			targetTriggers, err := mysql.GetTriggers(this.db, targetDatabaseName, target.TableName, this.migrationContext.TableNamesCaseSensitive)
+			if err != nil {
+				return err
+			}
+			for _, targetTrigger := range targetTriggers {
+				for _, targetTriggerTarget := range sql.ParseTriggerWriteTargets(targetTrigger.Statement) {
+					if isMigratedTable(targetDatabaseName, targetTriggerTarget) {
+						return this.migrationContext.Log.Errorf("Trigger %s writes to %s.%s, whose trigger %s writes back to %s.%s. Such triggers are unsupported. Bailing out",
+							sql.EscapeName(trigger.Name),
+							sql.EscapeName(targetDatabaseName),
+							sql.EscapeName(target.TableName),
+							sql.EscapeName(targetTrigger.Name),
+							sql.EscapeName(this.migrationContext.DatabaseName),
+							sql.EscapeName(this.migrationContext.OriginalTableName),
+						)
+					}
+				}
+			}
+			this.migrationContext.Log.Warningf("Trigger %s writes to %s.%s. Until cut-over these writes are made by the original table's trigger only: row copy and applied binlog events do not fire triggers",
+				sql.EscapeName(trigger.Name),
+				sql.EscapeName(targetDatabaseName),
+				sql.EscapeName(target.TableName),
+			)
+		}
+	}
+	return nil
+}
+
 // verifyTriggersDontExist verifies before createing new triggers we want to make sure these triggers dont exist already in the DB
 func (this *Inspector) validateGhostTriggersDontExist() error {
 	if len(this.migrationContext.Triggers) > 0 {
@@ -713,55 +1123,159 @@ func (this *Inspector) estimateTableRowsViaExplain() error {
 	return nil
 }
 
-// CountTableRows counts exact number of rows on the original table
-func (this *Inspector) CountTableRows(ctx context.Context) error {
-	atomic.StoreInt64(&this.migrationContext.CountingRowsFlag, 1)
-	defer atomic.StoreInt64(&this.migrationContext.CountingRowsFlag, 0)
+// CountTableRowsChunk counts the rows of the original table within a single chunk along the unique key,
+// starting at given values and bounded by the migration range. It returns the number of rows counted
+// and the values at which the chunk ends, from which the next chunk is to be counted.
+func (this *Inspector) CountTableRowsChunk(ctx context.Context, rangeStartValues *sql.ColumnValues, includeRangeStartValues bool, chunkSize int64) (rowsCount int64, rangeEndValues *sql.ColumnValues, hasFurtherRange bool, err error) {
+	query, explodedArgs, err := sql.BuildUniqueKeyRangeEndPreparedQueryViaOffset(
+		this.migrationContext.DatabaseName,
+		this.migrationContext.OriginalTableName,
+		&this.migrationContext.UniqueKey.Columns,
+		rangeStartValues.AbstractValues(),
+		this.migrationContext.MigrationRangeMaxValues.AbstractValues(),
+		chunkSize,
+		includeRangeStartValues,
+		"rowcount",
+	)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	rows, err := this.db.QueryContext(ctx, query, explodedArgs...)
+	if err != nil {
+		return 0, nil, false, err
+	}
+	defer rows.Close()
 
-	this.migrationContext.Log.Infof("As instructed, I'm issuing a SELECT COUNT(*) on the table. This may take a while")
+	rangeEndValues = sql.NewColumnValues(this.migrationContext.UniqueKey.Len())
+	for rows.Next() {
+		if err = rows.Scan(rangeEndValues.ValuesPointers...); err != nil {
+			return 0, nil, false, err
+		}
+		hasFurtherRange = true
+	}
+	if err = rows.Err(); err != nil {
+		return 0, nil, false, err
+	}
+	if !hasFurtherRange {
+		// last chunk: count up to the end of the migration range
+		rangeEndValues = this.migrationContext.MigrationRangeMaxValues
+	}
 
-	conn, err := this.db.Conn(ctx)
+	query, explodedArgs, err = sql.BuildUniqueKeyRangeCountPreparedQuery(
+		this.migrationContext.DatabaseName,
+		this.migrationContext.OriginalTableName,
+		this.migrationContext.UniqueKey.Name,
+		&this.migrationContext.UniqueKey.Columns,
+		rangeStartValues.AbstractValues(),
+		rangeEndValues.AbstractValues(),
+		includeRangeStartValues,
+	)
 	if err != nil {
-		return err
+		return 0, nil, false, err
+	}
+	if err := this.db.QueryRowContext(ctx, query, explodedArgs...).Scan(&rowsCount); err != nil {
+		return 0, nil, false, err
 	}
-	defer conn.Close()
+	return rowsCount, rangeEndValues, hasFurtherRange, nil
+}
 
-	var connectionID string
-	if err := conn.QueryRowContext(ctx, `SELECT /* gh-ost */ CONNECTION_ID()`).Scan(&connectionID); err != nil {
-		return err
+// ReadIterationRangeRows reads the rows of the current iteration range off the inspected server,
+// as used by --copy-from-replica. It returns the rows along with the inspected server's own binlog
+// coordinates, to which the rows are consistent: the table is briefly read-locked on one
+// connection while a consistent snapshot is opened on another, and the coordinates are read
+// while the lock is held. Thus the rows reflect exactly those changes to the table that precede
+// the returned coordinates in the binary log we stream.
+func (this *Inspector) ReadIterationRangeRows(ctx context.Context) (rows [][]interface{}, coords mysql.BinlogCoordinates, err error) {
+	query, explodedArgs, err := sql.BuildRangeSelectPreparedQuery(
+		this.migrationContext.DatabaseName,
+		this.migrationContext.OriginalTableName,
+		this.migrationContext.SharedColumns.Names(),
+		this.migrationContext.UniqueKey.Name,
+		&this.migrationContext.UniqueKey.Columns,
+		this.migrationContext.MigrationIterationRangeMinValues.AbstractValues(),
+		this.migrationContext.MigrationIterationRangeMaxValues.AbstractValues(),
+		this.migrationContext.GetIteration() == 0,
+	)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	query := fmt.Sprintf(`select /* gh-ost */ count(*) as count_rows from %s.%s`, sql.EscapeName(this.migrationContext.DatabaseName), sql.EscapeName(this.migrationContext.OriginalTableName))
-	var rowsEstimate int64
-	if err := conn.QueryRowContext(ctx, query).Scan(&rowsEstimate); err != nil {
-		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
-			this.migrationContext.Log.Infof("exact row count cancelled (%s), likely because I'm about to cut over. I'm going to kill that query.", ctx.Err())
-			return mysql.Kill(this.db, connectionID)
-		}
-		return err
+	lockConn, err := this.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer lockConn.Close()
+	snapshotConn, err := this.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
 	}
+	defer snapshotConn.Close()
 
-	// row count query finished. nil out the cancel func, so the main migration thread
-	// doesn't bother calling it after row copy is done.
-	this.migrationContext.SetCountTableRowsCancelFunc(nil)
+	sessionQuery := fmt.Sprintf(`SET SESSION time_zone = '%s', SESSION transaction_isolation = 'REPEATABLE-READ'`, this.migrationContext.ApplierTimeZone)
+	if _, err := snapshotConn.ExecContext(ctx, sessionQuery); err != nil {
+		return nil, nil, err
+	}
 
-	atomic.StoreInt64(&this.migrationContext.RowsEstimate, rowsEstimate)
-	this.migrationContext.UsedRowsEstimateMethod = base.CountRowsEstimate
+	lockQuery := fmt.Sprintf(`lock /* gh-ost */ tables %s.%s read`,
+		sql.EscapeName(this.migrationContext.DatabaseName),
+		sql.EscapeName(this.migrationContext.OriginalTableName),
+	)
+	if _, err := lockConn.ExecContext(ctx, lockQuery); err != nil {
+		return nil, nil, err
+	}
+	err = func() error {
+		defer lockConn.ExecContext(ctx, `unlock /* gh-ost */ tables`)
+		if _, err := snapshotConn.ExecContext(ctx, `start /* gh-ost */ transaction with consistent snapshot`); err != nil {
+			return err
+		}
+		coords, err = mysql.GetSelfBinlogCoordinates(this.dbVersion, this.db, this.migrationContext.UseGTIDs)
+		return err
+	}()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer snapshotConn.ExecContext(ctx, `rollback`)
 
-	this.migrationContext.Log.Infof("Exact number of rows via COUNT: %d", rowsEstimate)
+	sqlRows, err := snapshotConn.QueryContext(ctx, query, explodedArgs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer sqlRows.Close()
 
-	return nil
+	columns := this.migrationContext.SharedColumns.Columns()
+	for sqlRows.Next() {
+		values := make([]interface{}, len(columns))
+		scanArgs := make([]interface{}, len(columns))
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+		if err := sqlRows.Scan(scanArgs...); err != nil {
+			return nil, nil, err
+		}
+		for i, column := range columns {
+			// Textual values are passed on as strings, so that the applier converts them
+			// from the connection character set onto the (possibly altered) column character set.
+			if b, ok := values[i].([]byte); ok && (column.Charset != "" || column.Type == sql.JSONColumnType) {
+				values[i] = string(b)
+			}
+		}
+		rows = append(rows, values)
+	}
+	if err := sqlRows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return rows, coords, nil
 }
 
 // applyColumnTypes
 func (this *Inspector) applyColumnTypes(databaseName, tableName string, columnsLists ...*sql.ColumnList) error {
-	query := `
+	query := fmt.Sprintf(`
 		select /* gh-ost */ *
 		from
 			information_schema.columns
 		where
 			table_schema=?
-			and table_name=?`
+			and %s`, this.tableNameIs("table_name"))
 	err := sqlutils.QueryRowsMap(this.db, query, func(m sqlutils.RowMap) error {
 		columnName := m.GetString("COLUMN_NAME")
 		columnType := m.GetString("COLUMN_TYPE")
@@ -818,14 +1332,14 @@ func (this *Inspector) applyColumnTypes(databaseName, tableName string, columnsL
 
 // getAutoIncrementValue get's the original table's AUTO_INCREMENT value, if exists (0 value if not exists)
 func (this *Inspector) getAutoIncrementValue(tableName string) (autoIncrement uint64, err error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT /* gh-ost */ AUTO_INCREMENT
 		FROM
 			INFORMATION_SCHEMA.TABLES
 		WHERE
 			TABLES.TABLE_SCHEMA = ?
-			AND TABLES.TABLE_NAME = ?
-			AND AUTO_INCREMENT IS NOT NULL`
+			AND %s
+			AND AUTO_INCREMENT IS NOT NULL`, this.tableNameIs("TABLES.TABLE_NAME"))
 	err = sqlutils.QueryRowsMap(this.db, query, func(m sqlutils.RowMap) error {
 		autoIncrement = m.GetUint64("AUTO_INCREMENT")
 		return nil
@@ -836,7 +1350,17 @@ func (this *Inspector) getAutoIncrementValue(tableName string) (autoIncrement ui
 // getCandidateUniqueKeys investigates a table and returns the list of unique keys
 // candidate for chunking
 func (this *Inspector) getCandidateUniqueKeys(databaseName, tableName string) (uniqueKeys [](*sql.UniqueKey), err error) {
-	query := `
+	return this.getCandidateKeys(databaseName, tableName, false)
+}
+
+// getCandidateNonUniqueKeys returns the table's non-unique indexes, ordered by preference in the
+// same way as getCandidateUniqueKeys. These are only used with --allow-non-unique-key.
+func (this *Inspector) getCandidateNonUniqueKeys(databaseName, tableName string) (keys [](*sql.UniqueKey), err error) {
+	return this.getCandidateKeys(databaseName, tableName, true)
+}
+
+func (this *Inspector) getCandidateKeys(databaseName, tableName string, nonUnique bool) (uniqueKeys [](*sql.UniqueKey), err error) {
+	query := fmt.Sprintf(`
 		SELECT /* gh-ost */
 			COLUMNS.TABLE_SCHEMA,
 			COLUMNS.TABLE_NAME,
@@ -862,9 +1386,9 @@ func (this *Inspector) getCandidateUniqueKeys(databaseName, tableName string) (u
 			FROM
 				INFORMATION_SCHEMA.STATISTICS
 			WHERE
-				NON_UNIQUE=0
+				NON_UNIQUE = ?
 				AND TABLE_SCHEMA = ?
-				AND TABLE_NAME = ?
+				AND %s
 			GROUP BY
 				TABLE_SCHEMA,
 				TABLE_NAME,
@@ -875,7 +1399,7 @@ func (this *Inspector) getCandidateUniqueKeys(databaseName, tableName string) (u
 		)
 		WHERE
 			COLUMNS.TABLE_SCHEMA = ?
-			AND COLUMNS.TABLE_NAME = ?
+			AND %s
 		ORDER BY
 			COLUMNS.TABLE_SCHEMA, COLUMNS.TABLE_NAME,
 			CASE UNIQUES.INDEX_NAME
@@ -897,17 +1421,18 @@ func (this *Inspector) getCandidateUniqueKeys(databaseName, tableName string) (u
 				WHEN 'bigint' THEN 3
 				ELSE 100
 			END,
-			COUNT_COLUMN_IN_INDEX`
+			COUNT_COLUMN_IN_INDEX`, this.tableNameIs("TABLE_NAME"), this.tableNameIs("COLUMNS.TABLE_NAME"))
 	err = sqlutils.QueryRowsMap(this.db, query, func(m sqlutils.RowMap) error {
 		uniqueKey := &sql.UniqueKey{
 			Name:            m.GetString("INDEX_NAME"),
 			Columns:         *sql.ParseColumnList(m.GetString("COLUMN_NAMES")),
 			HasNullable:     m.GetBool("has_nullable"),
 			IsAutoIncrement: m.GetBool("is_auto_increment"),
+			IsNonUnique:     nonUnique,
 		}
 		uniqueKeys = append(uniqueKeys, uniqueKey)
 		return nil
-	}, databaseName, tableName, databaseName, tableName)
+	}, nonUnique, databaseName, tableName, databaseName, tableName)
 	if err != nil {
 		return uniqueKeys, err
 	}
@@ -915,6 +1440,42 @@ func (this *Inspector) getCandidateUniqueKeys(databaseName, tableName string) (u
 	return uniqueKeys, nil
 }
 
+// originalTableHasNonUniqueKeys checks whether the original table's candidate keys are non-unique,
+// which is the case when it has no unique keys and --allow-non-unique-key is given.
+func (this *Inspector) originalTableHasNonUniqueKeys() bool {
+	originalTableUniqueKeys := this.migrationContext.OriginalTableUniqueKeys
+	return len(originalTableUniqueKeys) > 0 && originalTableUniqueKeys[0].IsNonUnique
+}
+
+// validateNonUniqueKey checks whether a non-unique key can serve as migration key. The key must be
+// non-nullable, and its columns must be of types which compare correctly as literals: integers,
+// decimals, and temporal types other than TIMESTAMP.
+func (this *Inspector) validateNonUniqueKey(key *sql.UniqueKey) error {
+	if key.HasNullable {
+		return fmt.Errorf("non-unique key %s has nullable columns", key.Name)
+	}
+	for _, column := range key.Columns.Columns() {
+		mysqlType := strings.ToLower(column.MySQLType)
+		switch {
+		case strings.HasPrefix(mysqlType, "timestamp"):
+			return fmt.Errorf("column %s of non-unique key %s is of unsupported type %s", column.Name, key.Name, column.MySQLType)
+		case strings.HasPrefix(mysqlType, "decimal"),
+			strings.HasPrefix(mysqlType, "date"),
+			strings.HasPrefix(mysqlType, "time"),
+			strings.HasPrefix(mysqlType, "year"),
+			strings.HasPrefix(mysqlType, "tinyint"),
+			strings.HasPrefix(mysqlType, "smallint"),
+			strings.HasPrefix(mysqlType, "mediumint"),
+			strings.HasPrefix(mysqlType, "int"),
+			strings.HasPrefix(mysqlType, "bigint"):
+			continue
+		default:
+			return fmt.Errorf("column %s of non-unique key %s is of unsupported type %s", column.Name, key.Name, column.MySQLType)
+		}
+	}
+	return nil
+}
+
 // getSharedUniqueKeys returns the intersection of two given unique keys,
 // testing by list of columns
 func (this *Inspector) getSharedUniqueKeys(originalUniqueKeys, ghostUniqueKeys []*sql.UniqueKey) (uniqueKeys []*sql.UniqueKey) {
@@ -1010,13 +1571,70 @@ func (this *Inspector) readChangelogState(hint string) (string, error) {
 func (this *Inspector) getMasterConnectionConfig() (applierConfig *mysql.ConnectionConfig, err error) {
 	this.migrationContext.Log.Infof("Recursively searching for replication master")
 	visitedKeys := mysql.NewInstanceKeyMap()
-	return mysql.GetMasterConnectionConfigSafe(this.dbVersion, this.connectionConfig, visitedKeys, this.migrationContext.AllowedMasterMaster)
+	return mysql.GetMasterConnectionConfigSafe(this.dbVersion, this.connectionConfig, visitedKeys, this.migrationContext.AllowedMasterMaster, this.migrationContext.ReplicationChannel)
+}
+
+// resolveReplicationChannel validates the replication channel given by --replication-channel or,
+// on a multi-source replica, picks the channel whose source has the migrated table.
+func (this *Inspector) resolveReplicationChannel() error {
+	channels, err := mysql.GetReplicationChannels(this.dbVersion, this.db)
+	if err != nil {
+		return err
+	}
+	if this.migrationContext.ReplicationChannel != "" {
+		for _, channel := range channels {
+			if channel.Name == this.migrationContext.ReplicationChannel {
+				return nil
+			}
+		}
+		return fmt.Errorf("Replication channel '%s' not found on %s", this.migrationContext.ReplicationChannel, this.connectionConfig.Key.String())
+	}
+	if len(channels) <= 1 {
+		return nil
+	}
+	var owningChannels []string
+	for _, channel := range channels {
+		owns, err := this.sourceHasOriginalTable(channel.MasterKey)
+		if err != nil {
+			return fmt.Errorf("Cannot inspect source %s of replication channel '%s': %w. Please specify --replication-channel", channel.MasterKey.String(), channel.Name, err)
+		}
+		if owns {
+			owningChannels = append(owningChannels, channel.Name)
+		}
+	}
+	if len(owningChannels) != 1 {
+		return fmt.Errorf("%s is a multi-source replica, and %d of its replication channels have sources with %s.%s. Please specify --replication-channel",
+			this.connectionConfig.Key.String(),
+			len(owningChannels),
+			sql.EscapeName(this.migrationContext.DatabaseName),
+			sql.EscapeName(this.migrationContext.OriginalTableName),
+		)
+	}
+	this.migrationContext.ReplicationChannel = owningChannels[0]
+	this.migrationContext.Log.Infof("Multi-source replica: using replication channel '%s', whose source has the migrated table", this.migrationContext.ReplicationChannel)
+	return nil
+}
+
+// sourceHasOriginalTable checks whether given replication source has the migrated table
+func (this *Inspector) sourceHasOriginalTable(sourceKey mysql.InstanceKey) (bool, error) {
+	sourceConfig := this.connectionConfig.DuplicateCredentials(sourceKey)
+	db, _, err := this.migrationContext.DBCache.GetDB(sourceConfig.GetDBUri("information_schema"), sourceConfig)
+	if err != nil {
+		return false, err
+	}
+	var count int64
+	query := `select /* gh-ost */ count(*) from information_schema.tables where table_schema=? and table_name=?`
+	if err := db.QueryRow(query, this.migrationContext.DatabaseName, this.migrationContext.OriginalTableName).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
 }
 
 func (this *Inspector) getReplicationLag() (replicationLag time.Duration, err error) {
 	replicationLag, err = mysql.GetReplicationLagFromSlaveStatus(
 		this.dbVersion,
 		this.informationSchemaDb,
+		this.migrationContext.ReplicationChannel,
 	)
 	return replicationLag, err
 }
@@ -30,3 +30,20 @@ func TestInspectGetSharedUniqueKeys(t *testing.T) {
 	require.Equal(t, "id,org_id", sharedUniqKeys[1].Columns.String())
 	require.Equal(t, "id", sharedUniqKeys[2].Columns.String())
 }
+
+func TestDescribeMigrationKeyCandidate(t *testing.T) {
+	primaryKey := &sql.UniqueKey{Name: "PRIMARY", Columns: *sql.NewColumnList([]string{"uuid"})}
+	primaryKey.Columns.GetColumn("uuid").MySQLType = "char(36)"
+	require.Equal(t, "`PRIMARY` (`uuid` char(36)); estimated cardinality: 1000",
+		describeMigrationKeyCandidate(primaryKey, 1000, false))
+
+	uniqueKey := &sql.UniqueKey{Name: "ts_uidx", Columns: *sql.NewColumnList([]string{"ts", "seq"}), HasNullable: true}
+	require.Equal(t, "`ts_uidx` (`ts`, `seq`); estimated cardinality: unknown; chosen, has nullable columns",
+		describeMigrationKeyCandidate(uniqueKey, -1, true))
+
+	floatKey := &sql.UniqueKey{Name: "f_uidx", Columns: *sql.NewColumnList([]string{"f"})}
+	floatKey.Columns.GetColumn("f").Type = sql.FloatColumnType
+	floatKey.Columns.GetColumn("f").MySQLType = "float"
+	require.Equal(t, "`f_uidx` (`f` float); estimated cardinality: 0; `f` cannot serve as key",
+		describeMigrationKeyCandidate(floatKey, 0, false))
+}
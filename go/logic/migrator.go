@@ -13,6 +13,7 @@ import (
 	"math"
 	"os"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -25,8 +26,12 @@ import (
 var (
 	ErrMigratorUnsupportedRenameAlter = errors.New("ALTER statement seems to RENAME the table. This is not supported, and you should run your RENAME outside gh-ost.")
 	ErrMigrationNotAllowedOnMaster    = errors.New("It seems like this migration attempt to run directly on master. Preferably it would be executed on a replica (this reduces load from the master). To proceed please provide --allow-on-master.")
+	ErrMigrationDetached              = errors.New("Migration detached. Re-attach it with --resume")
 	RetrySleepFn                      = time.Sleep
 	checkpointTimeout                 = 2 * time.Second
+	detachCheckpointTimeout           = time.Minute
+	binlogRetentionCheckInterval      = 10 * time.Second
+	streamerTopologyCheckInterval     = 10 * time.Second
 )
 
 type ChangelogState string
@@ -45,8 +50,9 @@ func ReadChangelogState(s string) ChangelogState {
 type tableWriteFunc func() error
 
 type lockProcessedStruct struct {
-	state  string
-	coords mysql.BinlogCoordinates
+	state     string
+	coords    mysql.BinlogCoordinates
+	heartbeat string
 }
 
 type applyEventStruct struct {
@@ -81,12 +87,15 @@ type Migrator struct {
 	parser           *sql.AlterTableParser
 	inspector        *Inspector
 	applier          *Applier
+	peer             *Peer
 	eventsStreamer   *EventsStreamer
 	server           *Server
 	throttler        *Throttler
 	hooksExecutor    *HooksExecutor
 	migrationContext *base.MigrationContext
 
+	throughputEstimator *base.ThroughputEstimator
+
 	firstThrottlingCollected   chan bool
 	ghostTableMigrated         chan bool
 	rowCopyComplete            chan error
@@ -99,9 +108,32 @@ type Migrator struct {
 	copyRowsQueue    chan tableWriteFunc
 	applyEventsQueue chan *applyEventStruct
 
+	// dispatchedCoordinates are the coordinates of the most recent binlog event handed over
+	// to the write funcs, be it via applyEventsQueue or a changelog heartbeat.
+	dispatchedCoordinates      mysql.BinlogCoordinates
+	dispatchedCoordinatesMutex sync.Mutex
+	// lastHeartbeat is the value of the most recent changelog heartbeat read by the streamer
+	lastHeartbeat      string
+	lastHeartbeatMutex sync.Mutex
+	// lastCheckpoint is the most recent checkpoint written, or resumed from
+	lastCheckpoint      *Checkpoint
+	lastCheckpointMutex sync.Mutex
+	// heldEventStruct is an event taken off applyEventsQueue while applying events up to a
+	// snapshot's coordinates, and which lies beyond them. It is applied after the snapshot's rows.
+	heldEventStruct *applyEventStruct
+
+	// cutOverOrDetach is claimed by either the cut-over or a detach, which exclude each other
+	cutOverOrDetach int64
+
 	finishedMigrating int64
 }
 
+const (
+	noCutOverOrDetach int64 = iota
+	detaching
+	cuttingOver
+)
+
 func NewMigrator(context *base.MigrationContext, appVersion string) *Migrator {
 	migrator := &Migrator{
 		appVersion:                 appVersion,
@@ -112,6 +144,7 @@ func NewMigrator(context *base.MigrationContext, appVersion string) *Migrator {
 		firstThrottlingCollected:   make(chan bool, 3),
 		rowCopyComplete:            make(chan error),
 		allEventsUpToLockProcessed: make(chan *lockProcessedStruct),
+		throughputEstimator:        base.NewThroughputEstimator(base.ThroughputWindowDuration),
 
 		copyRowsQueue:     make(chan tableWriteFunc),
 		applyEventsQueue:  make(chan *applyEventStruct, base.MaxEventsBatchSize),
@@ -253,7 +286,7 @@ func (this *Migrator) canStopStreaming() bool {
 // onChangelogEvent is called when a binlog event operation on the changelog table is intercepted.
 func (this *Migrator) onChangelogEvent(dmlEntry *binlog.BinlogEntry) (err error) {
 	// Hey, I created the changelog table, I know the type of columns it has!
-	switch hint := dmlEntry.DmlEvent.NewColumnValues.StringColumn(2); hint {
+	switch hint := changelogHint(dmlEntry.DmlEvent); hint {
 	case "state":
 		return this.onChangelogStateEvent(dmlEntry)
 	case "heartbeat":
@@ -263,6 +296,24 @@ func (this *Migrator) onChangelogEvent(dmlEntry *binlog.BinlogEntry) (err error)
 	}
 }
 
+// changelogHint returns the hint of a changelog table event. With binlog_row_image MINIMAL, the after image
+// of an update of an existing hint row lacks the unchanged hint, which is told by the row's explicit id
+// instead (see Applier.WriteChangelog).
+func changelogHint(dmlEvent *binlog.BinlogDMLEvent) string {
+	if dmlEvent.NewColumnValues.IsPresent(2) {
+		return dmlEvent.NewColumnValues.StringColumn(2)
+	}
+	if dmlEvent.WhereColumnValues != nil && dmlEvent.WhereColumnValues.IsPresent(0) {
+		switch dmlEvent.WhereColumnValues.StringColumn(0) {
+		case "1":
+			return "heartbeat"
+		case "2":
+			return "state"
+		}
+	}
+	return ""
+}
+
 func (this *Migrator) onChangelogStateEvent(dmlEntry *binlog.BinlogEntry) (err error) {
 	changelogStateString := dmlEntry.DmlEvent.NewColumnValues.StringColumn(3)
 	changelogState := ReadChangelogState(changelogStateString)
@@ -274,10 +325,12 @@ func (this *Migrator) onChangelogStateEvent(dmlEntry *binlog.BinlogEntry) (err e
 		// Use helper to prevent deadlock if migration aborts before receiver is ready
 		_ = base.SendWithContext(this.migrationContext.GetContext(), this.ghostTableMigrated, true)
 	case AllEventsUpToLockProcessed:
+		heartbeat := this.getLastHeartbeat()
 		var applyEventFunc tableWriteFunc = func() error {
 			return base.SendWithContext(this.migrationContext.GetContext(), this.allEventsUpToLockProcessed, &lockProcessedStruct{
-				state:  changelogStateString,
-				coords: dmlEntry.Coordinates.Clone(),
+				state:     changelogStateString,
+				coords:    dmlEntry.Coordinates.Clone(),
+				heartbeat: heartbeat,
 			})
 		}
 		// at this point we know all events up to lock have been read from the streamer,
@@ -304,9 +357,11 @@ func (this *Migrator) onChangelogHeartbeatEvent(dmlEntry *binlog.BinlogEntry) (e
 		return this.migrationContext.Log.Errore(err)
 	} else {
 		this.migrationContext.SetLastHeartbeatOnChangelogTime(heartbeatTime)
+		this.setLastHeartbeat(changelogHeartbeatString)
 		this.applier.CurrentCoordinatesMutex.Lock()
 		this.applier.CurrentCoordinates = dmlEntry.Coordinates
 		this.applier.CurrentCoordinatesMutex.Unlock()
+		this.setDispatchedCoordinates(dmlEntry.Coordinates)
 		return nil
 	}
 }
@@ -322,6 +377,10 @@ func (this *Migrator) abort(err error) {
 	this.migrationContext.CancelContext()
 
 	// Log the error (but don't panic or exit)
+	if errors.Is(err, ErrMigrationDetached) {
+		this.migrationContext.Log.Infof("%v", err)
+		return
+	}
 	this.migrationContext.Log.Errorf("Migration aborted: %v", err)
 }
 
@@ -361,7 +420,7 @@ func (this *Migrator) countTableRows() (err error) {
 	}
 
 	countRowsFunc := func(ctx context.Context) error {
-		if err := this.inspector.CountTableRows(ctx); err != nil {
+		if err := this.countTableRowsInChunks(ctx); err != nil {
 			return err
 		}
 		if err := this.hooksExecutor.onRowCountComplete(); err != nil {
@@ -384,6 +443,71 @@ func (this *Migrator) countTableRows() (err error) {
 	return countRowsFunc(context.Background())
 }
 
+// countTableRowsInChunks computes the exact number of rows in the original table, chunk by chunk along
+// the unique key on the inspected server. Counting is throttled along with the row copy, its progress is
+// persisted in checkpoints so that it resumes where it left off, and the rows estimate is refreshed as
+// rows are counted.
+func (this *Migrator) countTableRowsInChunks(ctx context.Context) error {
+	atomic.StoreInt64(&this.migrationContext.CountingRowsFlag, 1)
+	defer atomic.StoreInt64(&this.migrationContext.CountingRowsFlag, 0)
+
+	rowsCounted, rangeStartValues, isComplete := this.migrationContext.GetRowCountProgress()
+	if this.migrationContext.MigrationRangeMinValues == nil {
+		// No rows found in table
+		isComplete = true
+	}
+	if !isComplete {
+		includeRangeStartValues := false
+		if rangeStartValues == nil {
+			rangeStartValues = this.migrationContext.MigrationRangeMinValues
+			includeRangeStartValues = true
+			this.migrationContext.Log.Infof("As instructed, I'm counting the table rows in chunks. This may take a while")
+		} else {
+			this.migrationContext.Log.Infof("As instructed, I'm resuming counting the table rows at %s, having counted %d rows", rangeStartValues, rowsCounted)
+		}
+		initialRowsEstimate := atomic.LoadInt64(&this.migrationContext.RowsEstimate)
+		for !isComplete {
+			if err := ctx.Err(); err != nil {
+				this.migrationContext.Log.Infof("exact row count cancelled (%s), likely because I'm about to cut over", err)
+				return err
+			}
+			this.throttler.throttle(nil)
+
+			var chunkRowsCount int64
+			var rangeEndValues *sql.ColumnValues
+			var hasFurtherRange bool
+			countChunkFunc := func() (err error) {
+				chunkRowsCount, rangeEndValues, hasFurtherRange, err = this.inspector.CountTableRowsChunk(ctx, rangeStartValues, includeRangeStartValues, atomic.LoadInt64(&this.migrationContext.ChunkSize))
+				return err
+			}
+			if err := this.retryOperation(countChunkFunc, true); err != nil {
+				return err
+			}
+			rowsCounted += chunkRowsCount
+			rangeStartValues = rangeEndValues
+			includeRangeStartValues = false
+			isComplete = !hasFurtherRange
+			this.migrationContext.SetRowCountProgress(rowsCounted, rangeEndValues.Clone(), isComplete)
+
+			if rowsCounted > initialRowsEstimate {
+				// The table holds at least as many rows as counted so far
+				atomic.StoreInt64(&this.migrationContext.RowsEstimate, rowsCounted)
+			}
+		}
+	} else {
+		this.migrationContext.SetRowCountProgress(rowsCounted, rangeStartValues, isComplete)
+	}
+
+	// row count finished. nil out the cancel func, so the main migration thread
+	// doesn't bother calling it after row copy is done.
+	this.migrationContext.SetCountTableRowsCancelFunc(nil)
+
+	this.migrationContext.SetExactRowsEstimate(rowsCounted)
+
+	this.migrationContext.Log.Infof("Exact number of rows via COUNT: %d", rowsCounted)
+	return nil
+}
+
 func (this *Migrator) createFlagFiles() (err error) {
 	if this.migrationContext.PostponeCutOverFlagFile != "" {
 		if !base.FileExists(this.migrationContext.PostponeCutOverFlagFile) {
@@ -452,6 +576,9 @@ func (this *Migrator) Migrate() (err error) {
 	if err := this.checkAbort(); err != nil {
 		return err
 	}
+	if err := this.predictDDLAlgorithm(); err != nil {
+		return err
+	}
 	// If we are resuming, we will initiateStreaming later when we know
 	// the binlog coordinates to resume streaming from.
 	// If not resuming, the streamer must be initiated before the applier,
@@ -474,25 +601,16 @@ func (this *Migrator) Migrate() (err error) {
 		return err
 	}
 	// In MySQL 8.0 (and possibly earlier) some DDL statements can be applied instantly.
-	// Attempt to do this if AttemptInstantDDL is set.
-	if this.migrationContext.AttemptInstantDDL {
-		if this.migrationContext.Noop {
-			this.migrationContext.Log.Debugf("Noop operation; not really attempting instant DDL")
-		} else {
-			this.migrationContext.Log.Infof("Attempting to execute alter with ALGORITHM=INSTANT")
-			if err := this.applier.AttemptInstantDDL(); err == nil {
-				if err := this.finalCleanup(); err != nil {
-					return nil
-				}
-				if err := this.hooksExecutor.onSuccess(true); err != nil {
-					return err
-				}
-				this.migrationContext.Log.Infof("Success! table %s.%s migrated instantly", sql.EscapeName(this.migrationContext.DatabaseName), sql.EscapeName(this.migrationContext.OriginalTableName))
-				return nil
-			} else {
-				this.migrationContext.Log.Infof("ALGORITHM=INSTANT not supported for this operation, proceeding with original algorithm: %s", err)
-			}
+	// Attempt to do this if AttemptInstantDDL or AttemptInplaceDDL is set.
+	if this.attemptDirectDDL() {
+		if err := this.finalCleanup(); err != nil {
+			return nil
+		}
+		if err := this.hooksExecutor.onSuccess(true); err != nil {
+			return err
 		}
+		this.migrationContext.Log.Infof("Success! table %s.%s migrated instantly", sql.EscapeName(this.migrationContext.DatabaseName), sql.EscapeName(this.migrationContext.OriginalTableName))
+		return nil
 	}
 
 	initialLag, _ := this.inspector.getReplicationLag()
@@ -522,6 +640,9 @@ func (this *Migrator) Migrate() (err error) {
 	}
 
 	if this.migrationContext.Resume {
+		if err := this.applier.UpgradeCheckpointTable(); err != nil {
+			return err
+		}
 		lastCheckpoint, err := this.applier.ReadLastCheckpoint()
 		if err != nil {
 			return this.migrationContext.Log.Errorf("No checkpoint found, unable to resume: %+v", err)
@@ -534,7 +655,11 @@ func (this *Migrator) Migrate() (err error) {
 		this.migrationContext.Iteration = lastCheckpoint.Iteration
 		this.migrationContext.TotalRowsCopied = lastCheckpoint.RowsCopied
 		this.migrationContext.TotalDMLEventsApplied = lastCheckpoint.DMLApplied
-		this.migrationContext.InitialStreamerCoords = lastCheckpoint.LastTrxCoords
+		this.migrationContext.SetRowCountProgress(lastCheckpoint.RowsCounted, lastCheckpoint.RowCountRangeMax, lastCheckpoint.IsCountComplete)
+		if this.migrationContext.InitialStreamerCoords, err = this.resumeStreamerCoordinates(lastCheckpoint); err != nil {
+			return err
+		}
+		this.setLastCheckpoint(lastCheckpoint)
 		if err := this.initiateStreaming(); err != nil {
 			return err
 		}
@@ -550,9 +675,6 @@ func (this *Migrator) Migrate() (err error) {
 	}
 	defer this.server.RemoveSocketFile()
 
-	if err := this.countTableRows(); err != nil {
-		return err
-	}
 	if err := this.addDMLEventsListener(); err != nil {
 		return err
 	}
@@ -562,6 +684,11 @@ func (this *Migrator) Migrate() (err error) {
 
 	this.initiateThrottler()
 
+	// Counting rows is throttled, and iterates the migration range; hence only now
+	if err := this.countTableRows(); err != nil {
+		return err
+	}
+
 	if err := this.hooksExecutor.onBeforeRowCopy(); err != nil {
 		return err
 	}
@@ -577,6 +704,12 @@ func (this *Migrator) Migrate() (err error) {
 	if this.migrationContext.Checkpoint {
 		go this.checkpointLoop()
 	}
+	if this.migrationContext.BinlogRetentionMarginSeconds > 0 {
+		go this.binlogRetentionLoop()
+	}
+	if this.migrationContext.StreamerFailover {
+		go this.streamerTopologyLoop()
+	}
 
 	this.migrationContext.Log.Debugf("Operating until row copy is complete")
 	this.consumeRowCopyComplete()
@@ -631,6 +764,63 @@ func (this *Migrator) Migrate() (err error) {
 	return nil
 }
 
+// predictDDLAlgorithm predicts the best algorithm by which the server could apply the alter directly,
+// and reports in noop mode when the migration could do without copying the table
+func (this *Migrator) predictDDLAlgorithm() error {
+	prediction, err := this.inspector.predictDDLAlgorithm(this.parser.AlterTokens())
+	if err != nil {
+		return err
+	}
+	this.migrationContext.DDLAlgorithmPrediction = prediction
+	this.migrationContext.Log.Infof("Predicted DDL algorithm: %s", prediction)
+	if this.migrationContext.Noop {
+		if prediction.IsInstant() {
+			this.migrationContext.Log.Infof("Noop: the alter is predicted to apply with ALGORITHM=INSTANT; --attempt-instant-ddl would migrate the table without copying it")
+		}
+		if prediction.IsInplaceMetadataOnly() {
+			this.migrationContext.Log.Infof("Noop: the alter is predicted to only change metadata with ALGORITHM=INPLACE; --attempt-inplace-ddl would migrate the table without copying it")
+		}
+	}
+	return nil
+}
+
+// attemptDirectDDL applies the alter directly onto the original table when requested: with ALGORITHM=INSTANT
+// given --attempt-instant-ddl, or, unless predicted to write data, with ALGORITHM=INPLACE for metadata only
+// changes given --attempt-inplace-ddl. Returns true when the table was migrated.
+func (this *Migrator) attemptDirectDDL() bool {
+	prediction := this.migrationContext.DDLAlgorithmPrediction
+	if this.migrationContext.AttemptInstantDDL {
+		if this.migrationContext.Noop {
+			this.migrationContext.Log.Debugf("Noop operation; not really attempting instant DDL")
+		} else {
+			if prediction != nil && prediction.WritesData() {
+				this.migrationContext.Log.Infof("ALGORITHM=INSTANT predicted not supported for this operation (%s), attempting anyway", prediction)
+			}
+			this.migrationContext.Log.Infof("Attempting to execute alter with ALGORITHM=INSTANT")
+			if err := this.applier.AttemptInstantDDL(); err == nil {
+				return true
+			} else {
+				this.migrationContext.Log.Infof("ALGORITHM=INSTANT not supported for this operation, proceeding with original algorithm: %s", err)
+			}
+		}
+	}
+	if this.migrationContext.AttemptInplaceDDL {
+		if this.migrationContext.Noop {
+			this.migrationContext.Log.Debugf("Noop operation; not really attempting inplace DDL")
+		} else if prediction == nil || !prediction.IsInplaceMetadataOnly() {
+			this.migrationContext.Log.Infof("ALGORITHM=INPLACE not predicted to only change metadata for this operation (%s), proceeding with original algorithm", prediction)
+		} else {
+			this.migrationContext.Log.Infof("Attempting to execute alter with ALGORITHM=INPLACE, LOCK=NONE")
+			if err := this.applier.AttemptInplaceDDL(); err == nil {
+				return true
+			} else {
+				this.migrationContext.Log.Infof("ALGORITHM=INPLACE, LOCK=NONE not supported for this operation, proceeding with original algorithm: %s", err)
+			}
+		}
+	}
+	return false
+}
+
 // Revert reverts a migration that previously completed by applying all DML events that happened
 // after the original cutover, then doing another cutover to swap the tables back.
 // The steps are similar to Migrate(), but without row copying.
@@ -680,6 +870,9 @@ func (this *Migrator) Revert() error {
 		return err
 	}
 
+	if err := this.applier.UpgradeCheckpointTable(); err != nil {
+		return err
+	}
 	lastCheckpoint, err := this.applier.ReadLastCheckpoint()
 	if err != nil {
 		return this.migrationContext.Log.Errorf("No checkpoint found, unable to revert: %+v", err)
@@ -687,7 +880,9 @@ func (this *Migrator) Revert() error {
 	if !lastCheckpoint.IsCutover {
 		return this.migrationContext.Log.Errorf("Last checkpoint is not after cutover, unable to revert: coords=%+v time=%+v", lastCheckpoint.LastTrxCoords, lastCheckpoint.Timestamp)
 	}
-	this.migrationContext.InitialStreamerCoords = lastCheckpoint.LastTrxCoords
+	if this.migrationContext.InitialStreamerCoords, err = this.resumeStreamerCoordinates(lastCheckpoint); err != nil {
+		return err
+	}
 	this.migrationContext.TotalRowsCopied = lastCheckpoint.RowsCopied
 	this.migrationContext.MigrationIterationRangeMinValues = lastCheckpoint.IterationRangeMin
 	this.migrationContext.MigrationIterationRangeMaxValues = lastCheckpoint.IterationRangeMax
@@ -825,6 +1020,15 @@ func (this *Migrator) cutOver() (err error) {
 	atomic.StoreInt64(&this.migrationContext.IsPostponingCutOver, 0)
 	this.migrationContext.MarkPointOfInterest()
 	this.migrationContext.Log.Debugf("checking for cut-over postpone: complete")
+	// A detach in progress either fails, and the cut-over proceeds, or aborts the migration
+	if err := this.sleepWhileTrue(
+		func() (bool, error) {
+			claimed := atomic.CompareAndSwapInt64(&this.cutOverOrDetach, noCutOverOrDetach, cuttingOver) || atomic.LoadInt64(&this.cutOverOrDetach) == cuttingOver
+			return !claimed, nil
+		},
+	); err != nil {
+		return err
+	}
 
 	if this.migrationContext.TestOnReplica {
 		// With `--test-on-replica` we stop replication thread, and then proceed to use
@@ -844,6 +1048,28 @@ func (this *Migrator) cutOver() (err error) {
 		}
 	}
 
+	peerReadOnly := false
+	if this.peer != nil {
+		defer func() {
+			if peerReadOnly {
+				if restoreErr := this.peer.RestoreReadOnly(); restoreErr != nil {
+					this.migrationContext.Log.Errore(restoreErr)
+				}
+			}
+		}()
+		if err := this.peer.SetReadOnly(); err != nil {
+			return err
+		}
+		peerReadOnly = true
+		if err := this.waitForPeerChangesUpToReadOnly(); err != nil {
+			return err
+		}
+		// The migrated master's changes replicate onto the peer in time for the cut-over's RENAME to follow
+		if err := this.waitForChangesOntoPeer(); err != nil {
+			return err
+		}
+	}
+
 	switch this.migrationContext.CutOverType {
 	case base.CutOverAtomic:
 		// Atomic solution: we use low timeout and multiple attempts. But for
@@ -852,12 +1078,74 @@ func (this *Migrator) cutOver() (err error) {
 	case base.CutOverTwoStep:
 		err = this.cutOverTwoStep()
 	default:
-		return this.migrationContext.Log.Fatalf("Unknown cut-over type: %d; should never get here!", this.migrationContext.CutOverType)
+		return fmt.Errorf("Unknown cut-over type: %d; should never get here!", this.migrationContext.CutOverType)
+	}
+	if err == nil && this.peer != nil {
+		// The tables are swapped. Keep the peer read_only until the RENAME replicates onto it, so that
+		// no write on the peer lands on the old table there.
+		if renameErr := this.retryOperation(this.waitForChangesOntoPeer, true); renameErr != nil {
+			peerReadOnly = false
+			err = fmt.Errorf("Tables are swapped, but the RENAME did not replicate onto peer %s, which is left read_only. Unset read_only on the peer once it replicates: %+v", this.migrationContext.PeerConnectionConfig.Key.String(), renameErr)
+			// The cut-over must not be retried
+			_ = base.SendWithContext(this.migrationContext.GetContext(), this.migrationContext.PanicAbort, err)
+		} else {
+			this.migrationContext.Log.Infof("RENAME replicated onto peer")
+		}
 	}
 	this.handleCutOverResult(err)
 	return err
 }
 
+// dropGhostTriggersOfFailedCutOver drops the triggers a failed cut-over created on the ghost table.
+// Triggers are created on the ghost table within the cut-over's lock, so that they go live along
+// with the RENAME. Left behind by a failed cut-over, they would fire as binlog events are applied
+// onto the ghost table, and duplicate the original triggers' side effects. They are thus dropped
+// before the cut-over releases its lock, while no writes on the original table are streamed.
+func (this *Migrator) dropGhostTriggersOfFailedCutOver() {
+	if !this.migrationContext.IncludeTriggers || len(this.migrationContext.Triggers) == 0 {
+		return
+	}
+	if !this.applier.tableExists(this.migrationContext.GetGhostDatabaseName(), this.migrationContext.GetGhostTableName()) {
+		// The ghost table was renamed into place after all; its triggers are now the migrated table's
+		return
+	}
+	this.migrationContext.Log.Infof("Dropping triggers from ghost table after failed cut-over")
+	if err := this.retryOperation(this.applier.DropTriggersFromGhost); err != nil {
+		this.migrationContext.Log.Errore(err)
+	}
+}
+
+// waitForPeerChangesUpToReadOnly waits for the changes made on the peer up to its read_only to replicate
+// onto the migrated master. The migrated master logs them along with its own changes, so that the
+// cut-over then applies them onto the ghost table as it waits for events up to its own lock.
+func (this *Migrator) waitForPeerChangesUpToReadOnly() error {
+	readOnlyCoords, err := this.peer.ReadExecutedGTIDs()
+	if err != nil {
+		return err
+	}
+	this.migrationContext.Log.Infof("Waiting for peer changes up to read_only to replicate: %+v", readOnlyCoords.DisplayString())
+	if err := this.applier.WaitForExecutedGTIDs(readOnlyCoords, this.migrationContext.CutOverLockTimeoutSeconds); err != nil {
+		return this.migrationContext.Log.Errorf("Peer changes up to read_only did not replicate: %+v", err)
+	}
+	this.migrationContext.Log.Infof("Done waiting for peer changes up to read_only")
+	return nil
+}
+
+// waitForChangesOntoPeer waits for the changes made so far on the migrated master, the cut-over's RENAME
+// included once the tables are swapped, to replicate onto the peer.
+func (this *Migrator) waitForChangesOntoPeer() error {
+	coords, err := mysql.GetSelfBinlogCoordinates(this.migrationContext.ApplierMySQLVersion, this.applier.db, true)
+	if err != nil {
+		return err
+	}
+	this.migrationContext.Log.Infof("Waiting for changes to replicate onto peer: %+v", coords.DisplayString())
+	if err := this.peer.WaitForExecutedGTIDs(coords, this.migrationContext.CutOverLockTimeoutSeconds); err != nil {
+		return this.migrationContext.Log.Errorf("Changes did not replicate onto peer: %+v", err)
+	}
+	this.migrationContext.Log.Infof("Done waiting for changes to replicate onto peer")
+	return nil
+}
+
 // Inject the "AllEventsUpToLockProcessed" state hint, wait for it to appear in the binary logs,
 // make sure the queue is drained.
 func (this *Migrator) waitForEventsUpToLock() error {
@@ -910,8 +1198,12 @@ func (this *Migrator) cutOverTwoStep() (err error) {
 	atomic.StoreInt64(&this.migrationContext.AllEventsUpToLockProcessedInjectedFlag, 0)
 
 	tableLocked := false
+	ghostTriggersCreated := false
 	defer func() {
 		if tableLocked && err != nil {
+			if ghostTriggersCreated {
+				this.dropGhostTriggersOfFailedCutOver()
+			}
 			if unlockErr := this.applier.UnlockTables(); unlockErr != nil {
 				this.migrationContext.Log.Errore(unlockErr)
 			}
@@ -928,6 +1220,7 @@ func (this *Migrator) cutOverTwoStep() (err error) {
 	}
 	// If we need to create triggers we need to do it here (only create part)
 	if this.migrationContext.IncludeTriggers && len(this.migrationContext.Triggers) > 0 {
+		ghostTriggersCreated = true
 		if err := this.retryOperation(this.applier.CreateTriggersOnGhost); err != nil {
 			return err
 		}
@@ -958,6 +1251,19 @@ func (this *Migrator) atomicCutOver() (err error) {
 
 	atomic.StoreInt64(&this.migrationContext.AllEventsUpToLockProcessedInjectedFlag, 0)
 
+	var ghostTriggersCreated, ghostTriggersDropped int64
+	dropGhostTriggers := func() {
+		if atomic.LoadInt64(&ghostTriggersCreated) == 1 && atomic.CompareAndSwapInt64(&ghostTriggersDropped, 0, 1) {
+			this.dropGhostTriggersOfFailedCutOver()
+		}
+	}
+	defer func() {
+		// Deferred after, and so runs before, the unlock above
+		if err != nil {
+			dropGhostTriggers()
+		}
+	}()
+
 	lockOriginalSessionIdChan := make(chan int64, 2)
 	tableLocked := make(chan error, 2)
 	tableUnlocked := make(chan error, 2)
@@ -980,6 +1286,7 @@ func (this *Migrator) atomicCutOver() (err error) {
 
 	// If we need to create triggers we need to do it here (only create part)
 	if this.migrationContext.IncludeTriggers && len(this.migrationContext.Triggers) > 0 {
+		atomic.StoreInt64(&ghostTriggersCreated, 1)
 		if err := this.applier.CreateTriggersOnGhost(); err != nil {
 			return this.migrationContext.Log.Errore(err)
 		}
@@ -996,6 +1303,7 @@ func (this *Migrator) atomicCutOver() (err error) {
 		if err := this.applier.AtomicCutoverRename(renameSessionIdChan, tablesRenamed); err != nil {
 			// Abort! Release the lock
 			atomic.StoreInt64(&tableRenameKnownToHaveFailed, 1)
+			dropGhostTriggers()
 			okToUnlockTable <- true
 		}
 	}()
@@ -1013,6 +1321,7 @@ func (this *Migrator) atomicCutOver() (err error) {
 	// Wait for the RENAME to appear in PROCESSLIST
 	if err := this.retryOperation(waitForRename, true); err != nil {
 		// Abort! Release the lock
+		dropGhostTriggers()
 		okToUnlockTable <- true
 		return err
 	}
@@ -1051,7 +1360,7 @@ func (this *Migrator) initiateServer() (err error) {
 	var f printStatusFunc = func(rule PrintStatusRule, writer io.Writer) {
 		this.printStatus(rule, writer)
 	}
-	this.server = NewServer(this.migrationContext, this.hooksExecutor, f)
+	this.server = NewServer(this.migrationContext, this.hooksExecutor, f, this.Detach)
 	if err := this.server.BindSocketFile(); err != nil {
 		return err
 	}
@@ -1101,9 +1410,8 @@ func (this *Migrator) initiateInspector() (err error) {
 		}
 		if this.migrationContext.CliMasterPassword != "" {
 			this.migrationContext.ApplierConnectionConfig.Password = this.migrationContext.CliMasterPassword
-		}
-		if err := this.migrationContext.ApplierConnectionConfig.RegisterTLSConfig(); err != nil {
-			return err
+			// explicit master credentials are static
+			this.migrationContext.ApplierConnectionConfig.CredentialsProvider = nil
 		}
 		this.migrationContext.Log.Infof("Master forced to be %+v", *this.migrationContext.ApplierConnectionConfig.ImpliedKey)
 	}
@@ -1122,13 +1430,35 @@ func (this *Migrator) initiateInspector() (err error) {
 	} else if this.migrationContext.InspectorIsAlsoApplier() && !this.migrationContext.AllowedRunningOnMaster {
 		return ErrMigrationNotAllowedOnMaster
 	}
+	if this.migrationContext.CopyFromReplica && this.migrationContext.InspectorIsAlsoApplier() {
+		return fmt.Errorf("Instructed to --copy-from-replica, but the server we connect to doesn't seem to be a replica")
+	}
 	if err := this.inspector.validateLogSlaveUpdates(); err != nil {
 		return err
 	}
+	if this.migrationContext.ActiveActivePeerHostname != "" {
+		if err := this.initiatePeer(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// initiatePeer connects and validates the active-active peer, with the master's credentials
+func (this *Migrator) initiatePeer() error {
+	key, err := mysql.ParseInstanceKey(this.migrationContext.ActiveActivePeerHostname)
+	if err != nil {
+		return err
+	}
+	if key.Equals(&this.migrationContext.ApplierConnectionConfig.Key) {
+		return fmt.Errorf("--active-active-peer %s is the migrated master itself", key.String())
+	}
+	this.migrationContext.PeerConnectionConfig = this.migrationContext.ApplierConnectionConfig.DuplicateCredentials(*key)
+	this.peer = NewPeer(this.migrationContext)
+	return this.peer.InitDBConnections()
+}
+
 // initiateStatus sets and activates the printStatus() ticker
 func (this *Migrator) initiateStatus() {
 	this.printStatus(ForcePrintStatusAndHintRule)
@@ -1139,13 +1469,20 @@ func (this *Migrator) initiateStatus() {
 		if atomic.LoadInt64(&this.finishedMigrating) > 0 {
 			return
 		}
-		go this.printStatus(HeuristicPrintStatusRule)
 		totalCopied := atomic.LoadInt64(&this.migrationContext.TotalRowsCopied)
-		if previousCount > 0 {
+		isThrottled, _, _ := this.migrationContext.IsThrottled()
+		this.throughputEstimator.AddSample(time.Now(), totalCopied, atomic.LoadInt64(&this.migrationContext.TotalDMLEventsApplied), isThrottled)
+		this.migrationContext.SetApplyEventsQueued(int64(len(this.applyEventsQueue)))
+		if rowsPerSecond, _, dmlPerSecond, ok := this.throughputEstimator.Rates(); ok {
+			// Recent throughput, excluding time spent throttled
+			atomic.StoreInt64(&this.migrationContext.EtaRowsPerSecond, int64(rowsPerSecond))
+			this.migrationContext.SetDMLApplyRate(dmlPerSecond)
+		} else if previousCount > 0 && !isThrottled {
 			copiedThisLoop := totalCopied - previousCount
 			atomic.StoreInt64(&this.migrationContext.EtaRowsPerSecond, copiedThisLoop)
 		}
 		previousCount = totalCopied
+		go this.printStatus(HeuristicPrintStatusRule)
 	}
 }
 
@@ -1179,6 +1516,24 @@ func (this *Migrator) printMigrationStatusHint(writers ...io.Writer) {
 		criticalLoad.String(),
 		this.migrationContext.GetNiceRatio(),
 	)
+	if this.migrationContext.IsDynamicDMLBatchSize() {
+		fmt.Fprintf(w, "# max-dml-batch-size: %+v; max-dml-batch-latency-millis: %+vms\n",
+			atomic.LoadInt64(&this.migrationContext.MaxDMLBatchSize),
+			atomic.LoadInt64(&this.migrationContext.MaxDMLBatchLatencyMillis),
+		)
+	}
+	if maxBinlogBacklog := atomic.LoadInt64(&this.migrationContext.MaxBinlogBacklog); maxBinlogBacklog > 0 {
+		fmt.Fprintf(w, "# max-binlog-backlog: %+v\n", maxBinlogBacklog)
+	}
+	if binlogRetentionMarginSeconds := atomic.LoadInt64(&this.migrationContext.BinlogRetentionMarginSeconds); binlogRetentionMarginSeconds > 0 {
+		fmt.Fprintf(w, "# binlog-retention-margin-seconds: %+v; binlog retention: %s\n", binlogRetentionMarginSeconds, this.migrationContext.GetBinlogRetentionRemainingDescription())
+	}
+	if this.migrationContext.CoalesceDMLEvents {
+		fmt.Fprintf(w, "# coalesce-dml-events: %+v of %+v DML events coalesced\n",
+			atomic.LoadInt64(&this.migrationContext.TotalDMLEventsCoalesced),
+			atomic.LoadInt64(&this.migrationContext.TotalDMLEventsApplied),
+		)
+	}
 	if this.migrationContext.ThrottleFlagFile != "" {
 		setIndicator := ""
 		if base.FileExists(this.migrationContext.ThrottleFlagFile) {
@@ -1255,7 +1610,7 @@ func (this *Migrator) getMigrationETA(rowsEstimate int64) (eta string, duration
 		// will tend to slow down.
 		if etaRowsPerSecond > 0 {
 			remainingRows := float64(rowsEstimate) - float64(totalRowsCopied)
-			etaSeconds = remainingRows / float64(etaRowsPerSecond)
+			etaSeconds = remainingRows/float64(etaRowsPerSecond) + this.getBacklogApplySeconds()
 		} else {
 			elapsedRowCopySeconds := this.migrationContext.ElapsedRowCopyTime().Seconds()
 			totalExpectedSeconds := elapsedRowCopySeconds * float64(rowsEstimate) / float64(totalRowsCopied)
@@ -1280,6 +1635,39 @@ func (this *Migrator) getMigrationETA(rowsEstimate int64) (eta string, duration
 	return eta, duration
 }
 
+// getBacklogApplySeconds estimates the time it takes to apply the binlog events pending in
+// the queue, based on the recent DML apply rate.
+func (this *Migrator) getBacklogApplySeconds() float64 {
+	backlog := len(this.applyEventsQueue)
+	if backlog == 0 {
+		return 0
+	}
+	if _, _, dmlPerSecond, ok := this.throughputEstimator.Rates(); ok && dmlPerSecond > 0 {
+		return float64(backlog) / dmlPerSecond
+	}
+	return 0
+}
+
+// getMigrationETARange returns a confidence range for the ETA, based on the variability of
+// recent row copy throughput. Either end may be unknown.
+func (this *Migrator) getMigrationETARange(rowsEstimate int64) (etaMin, etaMax time.Duration) {
+	etaMin, etaMax = time.Duration(base.ETAUnknown), time.Duration(base.ETAUnknown)
+	rowsPerSecond, rowsPerSecondStdDev, _, ok := this.throughputEstimator.Rates()
+	if !ok || rowsPerSecond <= 0 {
+		return etaMin, etaMax
+	}
+	remainingRows := float64(rowsEstimate) - float64(this.migrationContext.GetTotalRowsCopied())
+	if remainingRows <= 0 {
+		return 0, 0
+	}
+	backlogSeconds := this.getBacklogApplySeconds()
+	etaMin = time.Duration(remainingRows/(rowsPerSecond+rowsPerSecondStdDev)+backlogSeconds) * time.Second
+	if rowsPerSecond > rowsPerSecondStdDev {
+		etaMax = time.Duration(remainingRows/(rowsPerSecond-rowsPerSecondStdDev)+backlogSeconds) * time.Second
+	}
+	return etaMin, etaMax
+}
+
 // getMigrationStateAndETA returns the state and eta of the migration.
 func (this *Migrator) getMigrationStateAndETA(rowsEstimate int64) (state, eta string, etaDuration time.Duration) {
 	eta, etaDuration = this.getMigrationETA(rowsEstimate)
@@ -1291,6 +1679,10 @@ func (this *Migrator) getMigrationStateAndETA(rowsEstimate int64) (state, eta st
 		state = "postponing cut-over"
 	} else if isThrottled, throttleReason, _ := this.migrationContext.IsThrottled(); isThrottled {
 		state = fmt.Sprintf("throttled, %s", throttleReason)
+	} else if atomic.LoadInt64(&this.rowCopyCompleteFlag) == 0 && this.migrationContext.IsBinlogBacklogExceeded() {
+		state = "catching up on binlog backlog"
+	} else if atomic.LoadInt64(&this.rowCopyCompleteFlag) == 0 && this.migrationContext.IsBinlogRetentionPausingRowCopy() {
+		state = "catching up within binlog retention"
 	}
 	return state, eta, etaDuration
 }
@@ -1362,6 +1754,18 @@ func (this *Migrator) printStatus(rule PrintStatusRule, writers ...io.Writer) {
 	// Get state + ETA
 	state, eta, etaDuration := this.getMigrationStateAndETA(rowsEstimate)
 	this.migrationContext.SetETADuration(etaDuration)
+	etaMin, etaMax := time.Duration(base.ETAUnknown), time.Duration(base.ETAUnknown)
+	if etaDuration > 0 {
+		etaMin, etaMax = this.getMigrationETARange(rowsEstimate)
+	}
+	this.migrationContext.SetETARange(etaMin, etaMax)
+	if etaMin != time.Duration(base.ETAUnknown) {
+		etaMaxString := "N/A"
+		if etaMax != time.Duration(base.ETAUnknown) {
+			etaMaxString = base.PrettifyDurationOutput(etaMax)
+		}
+		eta = fmt.Sprintf("%s (%s-%s)", eta, base.PrettifyDurationOutput(etaMin), etaMaxString)
+	}
 
 	if !this.shouldPrintStatus(rule, elapsedSeconds, etaDuration) {
 		return
@@ -1369,10 +1773,15 @@ func (this *Migrator) printStatus(rule PrintStatusRule, writers ...io.Writer) {
 
 	currentBinlogCoordinates := this.eventsStreamer.GetCurrentBinlogCoordinates()
 
-	status := fmt.Sprintf("Copy: %d/%d %.1f%%; Applied: %d; Backlog: %d/%d; Time: %+v(total), %+v(copy); streamer: %+v; Lag: %.2fs, HeartbeatLag: %.2fs, State: %s; ETA: %s",
+	applyRate := fmt.Sprintf("%.1f/s", this.migrationContext.GetDMLApplyRate())
+	if this.migrationContext.IsDynamicDMLBatchSize() {
+		applyRate = fmt.Sprintf("%s; DML batch: %d", applyRate, this.migrationContext.GetEffectiveDMLBatchSize())
+	}
+	status := fmt.Sprintf("Copy: %d/%d %.1f%%; Applied: %d; Backlog: %d/%d; Binlog behind: %s; Apply rate: %s; Time: %+v(total), %+v(copy); streamer: %+v; Lag: %.2fs, HeartbeatLag: %.2fs, State: %s; ETA: %s",
 		totalRowsCopied, rowsEstimate, progressPct,
 		atomic.LoadInt64(&this.migrationContext.TotalDMLEventsApplied),
 		len(this.applyEventsQueue), cap(this.applyEventsQueue),
+		this.migrationContext.GetBinlogBacklogDescription(), applyRate,
 		base.PrettifyDurationOutput(elapsedTime), base.PrettifyDurationOutput(this.migrationContext.ElapsedRowCopyTime()),
 		currentBinlogCoordinates.DisplayString(),
 		this.migrationContext.GetCurrentLagDuration().Seconds(),
@@ -1380,6 +1789,9 @@ func (this *Migrator) printStatus(rule PrintStatusRule, writers ...io.Writer) {
 		state,
 		eta,
 	)
+	if this.migrationContext.IsBinlogRetentionAtRisk() {
+		status = fmt.Sprintf("%s; Binlog retention: %s", status, this.migrationContext.GetBinlogRetentionRemainingDescription())
+	}
 	this.applier.WriteChangelog(
 		fmt.Sprintf("copy iteration %d at %d", this.migrationContext.GetIteration(), time.Now().Unix()),
 		state,
@@ -1404,6 +1816,9 @@ func (this *Migrator) printStatus(rule PrintStatusRule, writers ...io.Writer) {
 // initiateStreaming begins streaming of binary log events and registers listeners for such events
 func (this *Migrator) initiateStreaming() error {
 	this.eventsStreamer = NewEventsStreamer(this.migrationContext)
+	if this.migrationContext.StreamerFailover {
+		this.eventsStreamer.failoverSource = this.streamerFailoverSource
+	}
 	if err := this.eventsStreamer.InitDBConnections(); err != nil {
 		return err
 	}
@@ -1450,12 +1865,40 @@ func (this *Migrator) addDMLEventsListener() error {
 		func(dmlEntry *binlog.BinlogEntry) error {
 			// Use helper to prevent deadlock if buffer fills and executeWriteFuncs exits
 			// This is critical because this callback blocks the event streamer
-			return base.SendWithContext(this.migrationContext.GetContext(), this.applyEventsQueue, newApplyEventStructByDML(dmlEntry))
+			if err := base.SendWithContext(this.migrationContext.GetContext(), this.applyEventsQueue, newApplyEventStructByDML(dmlEntry)); err != nil {
+				return err
+			}
+			this.setDispatchedCoordinates(dmlEntry.Coordinates)
+			return nil
 		},
 	)
 	return err
 }
 
+func (this *Migrator) setDispatchedCoordinates(coords mysql.BinlogCoordinates) {
+	this.dispatchedCoordinatesMutex.Lock()
+	defer this.dispatchedCoordinatesMutex.Unlock()
+	this.dispatchedCoordinates = coords
+}
+
+func (this *Migrator) getDispatchedCoordinates() mysql.BinlogCoordinates {
+	this.dispatchedCoordinatesMutex.Lock()
+	defer this.dispatchedCoordinatesMutex.Unlock()
+	return this.dispatchedCoordinates
+}
+
+func (this *Migrator) setLastHeartbeat(heartbeat string) {
+	this.lastHeartbeatMutex.Lock()
+	defer this.lastHeartbeatMutex.Unlock()
+	this.lastHeartbeat = heartbeat
+}
+
+func (this *Migrator) getLastHeartbeat() string {
+	this.lastHeartbeatMutex.Lock()
+	defer this.lastHeartbeatMutex.Unlock()
+	return this.lastHeartbeat
+}
+
 // initiateThrottler kicks in the throttling collection and the throttling checks.
 func (this *Migrator) initiateThrottler() {
 	this.throttler = NewThrottler(this.migrationContext, this.applier, this.inspector, this.appVersion)
@@ -1568,6 +2011,8 @@ func (this *Migrator) iterateChunks() error {
 				}
 				if !hasFurtherRange {
 					atomic.StoreInt64(&hasNoFurtherRangeFlag, 1)
+					// Row copy covered the entire range; events need no longer be matched against it
+					atomic.StoreInt64(&this.applier.RowCopyRangeExhaustedFlag, 1)
 					return terminateRowIteration(nil)
 				}
 				if atomic.LoadInt64(&this.rowCopyCompleteFlag) == 1 {
@@ -1581,7 +2026,14 @@ func (this *Migrator) iterateChunks() error {
 					// _ghost_ table, which no longer exists. So, bothering error messages and all, but no damage.
 					return nil
 				}
-				_, rowsAffected, _, err := this.applier.ApplyIterationInsertQuery()
+				var rowsAffected int64
+				if this.migrationContext.UniqueKey.IsNonUnique {
+					rowsAffected, err = this.copyIterationRowsAtSnapshot()
+				} else if this.migrationContext.CopyFromReplica {
+					rowsAffected, err = this.copyIterationRowsFromReplica()
+				} else {
+					_, rowsAffected, _, err = this.applier.ApplyIterationInsertQuery()
+				}
 				if err != nil {
 					return err // wrapping call will retry
 				}
@@ -1626,6 +2078,94 @@ func (this *Migrator) iterateChunks() error {
 	}
 }
 
+// copyIterationRowsFromReplica reads the current iteration range off the inspected replica and
+// writes it onto the ghost table on the master. It runs within executeWriteFuncs(), serialized
+// with the application of binlog events. The rows are consistent with the replica's binlog
+// coordinates at time of read; events up to those coordinates may safely be (re)applied after
+// the rows are written, but if events beyond them were already applied then the rows may be
+// stale (e.g. resurrect a deleted row) and are discarded, to be read again.
+func (this *Migrator) copyIterationRowsFromReplica() (rowsAffected int64, err error) {
+	rows, coords, err := this.inspector.ReadIterationRangeRows(this.migrationContext.GetContext())
+	if err != nil {
+		return 0, err
+	}
+	this.applier.CurrentCoordinatesMutex.Lock()
+	appliedCoords := this.applier.CurrentCoordinates
+	this.applier.CurrentCoordinatesMutex.Unlock()
+	if appliedCoords != nil && !appliedCoords.IsEmpty() && coords.SmallerThan(appliedCoords) {
+		this.migrationContext.Log.Debugf("Replica chunk read at %+v, applied coordinates are %+v", coords, appliedCoords)
+		return 0, ErrStaleReplicaChunk
+	}
+	rowsAffected, _, err = this.applier.ApplyIterationRowsInsertQuery(rows)
+	return rowsAffected, err
+}
+
+// copyIterationRowsAtSnapshot reads the current iteration range within a consistent snapshot, and
+// writes it onto the ghost table at exactly the snapshot's binlog coordinates: events up to these
+// coordinates are applied before the rows are written, and events beyond them only after.
+// This is required when iterating a non-unique key, where events are matched by full row image and
+// are not idempotent: an event must be reflected exactly once, either by the row copy or by the event.
+func (this *Migrator) copyIterationRowsAtSnapshot() (rowsAffected int64, err error) {
+	rows, coords, err := this.inspector.ReadIterationRangeRows(this.migrationContext.GetContext())
+	if err != nil {
+		return 0, err
+	}
+	if err := this.applyEventsUpToCoordinates(coords); err != nil {
+		return 0, err
+	}
+	rowsAffected, _, err = this.applier.ApplyIterationRowsInsertQuery(rows)
+	return rowsAffected, err
+}
+
+// applyEventsUpToCoordinates applies events one by one as the streamer dispatches them, until all
+// events up to given coordinates are applied. Events are applied while waiting, so that the streamer
+// never blocks on a full applyEventsQueue. The first event found beyond the coordinates is held, to be
+// applied by executeWriteFuncs() once the current row copy is complete.
+func (this *Migrator) applyEventsUpToCoordinates(coords mysql.BinlogCoordinates) error {
+	for {
+		if err := this.checkAbort(); err != nil {
+			return err
+		}
+		// Events are queued ahead of their coordinates being dispatched: once the coordinates are
+		// dispatched, all events up to them are found on the queue
+		dispatchedCoords := this.getDispatchedCoordinates()
+		allDispatched := dispatchedCoords != nil && coords.SmallerThanOrEquals(dispatchedCoords)
+		eventStruct := this.heldEventStruct
+		this.heldEventStruct = nil
+		if eventStruct == nil {
+			select {
+			case eventStruct = <-this.applyEventsQueue:
+			default:
+				if allDispatched {
+					return nil
+				}
+				// Changelog heartbeats guarantee the streamer eventually gets there
+				time.Sleep(10 * time.Millisecond)
+				continue
+			}
+		}
+		if eventStruct.dmlEvent == nil {
+			if eventStruct.writeFunc != nil {
+				if err := this.retryOperation(*eventStruct.writeFunc); err != nil {
+					return this.migrationContext.Log.Errore(err)
+				}
+			}
+			continue
+		}
+		if !eventStruct.coords.SmallerThanOrEquals(coords) {
+			this.heldEventStruct = eventStruct
+			return nil
+		}
+		dmlEvents := []*binlog.BinlogDMLEvent{eventStruct.dmlEvent}
+		if err := this.retryOperation(func() error { return this.applier.ApplyDMLEventQueries(dmlEvents) }); err != nil {
+			return this.migrationContext.Log.Errore(err)
+		}
+		this.applier.CurrentCoordinatesMutex.Lock()
+		this.applier.CurrentCoordinates = eventStruct.coords
+		this.applier.CurrentCoordinatesMutex.Unlock()
+	}
+}
+
 func (this *Migrator) onApplyEventStruct(eventStruct *applyEventStruct) error {
 	handleNonDMLEventStruct := func(eventStruct *applyEventStruct) error {
 		if eventStruct.writeFunc != nil {
@@ -1644,7 +2184,7 @@ func (this *Migrator) onApplyEventStruct(eventStruct *applyEventStruct) error {
 		var nonDmlStructToApply *applyEventStruct
 
 		availableEvents := len(this.applyEventsQueue)
-		batchSize := int(atomic.LoadInt64(&this.migrationContext.DMLBatchSize))
+		batchSize := int(this.migrationContext.GetEffectiveDMLBatchSize())
 		if availableEvents > batchSize-1 {
 			// The "- 1" is because we already consumed one event: the original event that led to this function getting called.
 			// So, if DMLBatchSize==1 we wish to not process any further events
@@ -1660,12 +2200,16 @@ func (this *Migrator) onApplyEventStruct(eventStruct *applyEventStruct) error {
 			dmlEvents = append(dmlEvents, additionalStruct.dmlEvent)
 		}
 		// Create a task to apply the DML event; this will be execute by executeWriteFuncs()
+		var applyLatency time.Duration
 		var applyEventFunc tableWriteFunc = func() error {
+			applyStartTime := time.Now()
+			defer func() { applyLatency = time.Since(applyStartTime) }()
 			return this.applier.ApplyDMLEventQueries(dmlEvents)
 		}
 		if err := this.retryOperation(applyEventFunc); err != nil {
 			return this.migrationContext.Log.Errore(err)
 		}
+		this.migrationContext.AdjustDMLBatchSize(int64(len(this.applyEventsQueue)), applyLatency)
 		// update applier coordinates
 		this.applier.CurrentCoordinatesMutex.Lock()
 		this.applier.CurrentCoordinates = eventStruct.coords
@@ -1686,6 +2230,8 @@ func (this *Migrator) onApplyEventStruct(eventStruct *applyEventStruct) error {
 // It gets the binlog coordinates of the last received trx and waits until the
 // applier reaches that trx. At that point it's safe to resume from these coordinates.
 func (this *Migrator) Checkpoint(ctx context.Context) (*Checkpoint, error) {
+	// The heartbeat is read ahead of the coordinates, so as to be at or before them
+	heartbeat := this.getLastHeartbeat()
 	coords := this.eventsStreamer.GetCurrentBinlogCoordinates()
 	this.applier.LastIterationRangeMutex.Lock()
 	if this.applier.LastIterationRangeMaxValues == nil || this.applier.LastIterationRangeMinValues == nil {
@@ -1697,10 +2243,13 @@ func (this *Migrator) Checkpoint(ctx context.Context) (*Checkpoint, error) {
 		IterationRangeMin: this.applier.LastIterationRangeMinValues.Clone(),
 		IterationRangeMax: this.applier.LastIterationRangeMaxValues.Clone(),
 		LastTrxCoords:     coords,
+		Heartbeat:         heartbeat,
+		ServerUUID:        this.migrationContext.InspectorServerUUID,
 		RowsCopied:        atomic.LoadInt64(&this.migrationContext.TotalRowsCopied),
 		DMLApplied:        atomic.LoadInt64(&this.migrationContext.TotalDMLEventsApplied),
 	}
 	this.applier.LastIterationRangeMutex.Unlock()
+	chk.RowsCounted, chk.RowCountRangeMax, chk.IsCountComplete = this.migrationContext.GetRowCountProgress()
 
 	for {
 		if err := ctx.Err(); err != nil {
@@ -1727,6 +2276,8 @@ func (this *Migrator) CheckpointAfterCutOver() (*Checkpoint, error) {
 	chk := &Checkpoint{
 		IsCutover:         true,
 		LastTrxCoords:     this.lastLockProcessed.coords,
+		Heartbeat:         this.lastLockProcessed.heartbeat,
+		ServerUUID:        this.migrationContext.InspectorServerUUID,
 		IterationRangeMin: sql.NewColumnValues(this.migrationContext.UniqueKey.Len()),
 		IterationRangeMax: sql.NewColumnValues(this.migrationContext.UniqueKey.Len()),
 		Iteration:         this.migrationContext.GetIteration(),
@@ -1741,6 +2292,7 @@ func (this *Migrator) CheckpointAfterCutOver() (*Checkpoint, error) {
 		chk.IterationRangeMax = this.applier.LastIterationRangeMaxValues.Clone()
 	}
 	this.applier.LastIterationRangeMutex.Unlock()
+	chk.RowsCounted, chk.RowCountRangeMax, chk.IsCountComplete = this.migrationContext.GetRowCountProgress()
 
 	id, err := this.applier.WriteCheckpoint(chk)
 	chk.Id = id
@@ -1773,11 +2325,172 @@ func (this *Migrator) checkpointLoop() {
 		} else {
 			this.migrationContext.Log.Infof("checkpoint success at coords=%+v range_min=%+v range_max=%+v iteration=%d",
 				chk.LastTrxCoords.DisplayString(), chk.IterationRangeMin.String(), chk.IterationRangeMax.String(), chk.Iteration)
+			chk.Timestamp = t
+			this.setLastCheckpoint(chk)
 		}
 		cancel()
 	}
 }
 
+func (this *Migrator) setLastCheckpoint(chk *Checkpoint) {
+	this.lastCheckpointMutex.Lock()
+	defer this.lastCheckpointMutex.Unlock()
+	this.lastCheckpoint = chk
+}
+
+func (this *Migrator) getLastCheckpoint() *Checkpoint {
+	this.lastCheckpointMutex.Lock()
+	defer this.lastCheckpointMutex.Unlock()
+	return this.lastCheckpoint
+}
+
+// Detach writes a checkpoint, then aborts the migration without cleanup with ErrMigrationDetached: the
+// migration stops streaming binary logs and exits, keeping the ghost and changelog tables. It is later
+// re-attached with --resume, which resumes from the checkpoint. A migration cannot detach before row
+// copy begins, nor once the cut-over begins, nor with a non-unique migration key, which --resume refuses.
+func (this *Migrator) Detach() (*Checkpoint, error) {
+	if !this.migrationContext.Checkpoint {
+		return nil, fmt.Errorf("Detaching requires --checkpoint")
+	}
+	if this.migrationContext.Noop {
+		return nil, fmt.Errorf("Noop operation; cannot detach")
+	}
+	if this.migrationContext.NonUniqueKeyAllowed || (this.migrationContext.UniqueKey != nil && this.migrationContext.UniqueKey.IsNonUnique) {
+		return nil, fmt.Errorf("Migration key is non-unique, and a migration with --allow-non-unique-key cannot be resumed; cannot detach")
+	}
+	if this.migrationContext.ElapsedRowCopyTime() == 0 {
+		return nil, fmt.Errorf("Row copy has not begun; cannot detach yet")
+	}
+	if !atomic.CompareAndSwapInt64(&this.cutOverOrDetach, noCutOverOrDetach, detaching) {
+		if atomic.LoadInt64(&this.cutOverOrDetach) == detaching {
+			return nil, fmt.Errorf("Already detaching")
+		}
+		return nil, fmt.Errorf("Cut-over has begun; cannot detach")
+	}
+	this.migrationContext.Log.Infof("Detaching: writing checkpoint")
+	ctx, cancel := context.WithTimeout(context.Background(), detachCheckpointTimeout)
+	defer cancel()
+	chk, err := this.Checkpoint(ctx)
+	if err != nil {
+		atomic.StoreInt64(&this.cutOverOrDetach, noCutOverOrDetach)
+		return nil, this.migrationContext.Log.Errorf("Detach failed on checkpoint: %+v", err)
+	}
+	chk.Timestamp = time.Now()
+	this.setLastCheckpoint(chk)
+	this.migrationContext.Log.Infof("Detached at checkpoint coords=%+v range_min=%+v range_max=%+v iteration=%d",
+		chk.LastTrxCoords.DisplayString(), chk.IterationRangeMin.String(), chk.IterationRangeMax.String(), chk.Iteration)
+	if err := this.hooksExecutor.onDetach(chk.LastTrxCoords.DisplayString()); err != nil {
+		this.migrationContext.Log.Errorf("%s hook failed: %+v", onDetach, err)
+	}
+	_ = base.SendWithContext(this.migrationContext.GetContext(), this.migrationContext.PanicAbort, ErrMigrationDetached)
+	return chk, nil
+}
+
+// binlogRetentionLoop periodically checks how long the binary logs holding the applied events and the
+// last checkpoint are to be retained on the inspected server, see checkBinlogRetention.
+func (this *Migrator) binlogRetentionLoop() {
+	ticker := time.NewTicker(binlogRetentionCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if atomic.LoadInt64(&this.finishedMigrating) > 0 || atomic.LoadInt64(&this.migrationContext.CutOverCompleteFlag) > 0 {
+			this.migrationContext.SetBinlogRetentionPausingRowCopy(false)
+			return
+		}
+		if err := this.checkBinlogRetention(); err != nil {
+			this.migrationContext.SetBinlogRetentionRemaining(base.BinlogRetentionUnknown)
+			this.migrationContext.SetBinlogRetentionPausingRowCopy(false)
+			this.migrationContext.Log.Errore(err)
+		}
+	}
+}
+
+// checkBinlogRetention compares the binary logs of the inspected server with the oldest coordinates the migration
+// needs: those of the applied events, which the streamer must not fall behind, and those of the last checkpoint,
+// which a resume starts from. The time left until their binary logs are purged is estimated from
+// binlog_expire_logs_seconds and the time the coordinates were written, as told by changelog heartbeats.
+// It warns when that time drops within --binlog-retention-margin-seconds, and with --binlog-retention-pause-row-copy
+// pauses row copy so that the applied events catch up.
+func (this *Migrator) checkBinlogRetention() error {
+	this.applier.CurrentCoordinatesMutex.Lock()
+	appliedCoords := this.applier.CurrentCoordinates
+	this.applier.CurrentCoordinatesMutex.Unlock()
+	if appliedCoords == nil || appliedCoords.IsEmpty() {
+		return nil
+	}
+	retentionSeconds, err := mysql.GetBinlogRetentionSeconds(this.inspector.db)
+	if err != nil {
+		return err
+	}
+	var isPurged func(coords mysql.BinlogCoordinates) bool
+	if this.migrationContext.UseGTIDs {
+		gtidPurged, err := mysql.GetPurgedGTIDSet(this.inspector.db)
+		if err != nil {
+			return err
+		}
+		isPurged = func(coords mysql.BinlogCoordinates) bool {
+			gtidCoords, ok := coords.(*mysql.GTIDBinlogCoordinates)
+			return ok && gtidCoords.IsPurged(gtidPurged)
+		}
+	} else {
+		binaryLogs, err := mysql.GetBinaryLogs(this.inspector.db)
+		if err != nil {
+			return err
+		}
+		isPurged = func(coords mysql.BinlogCoordinates) bool {
+			fileCoords, ok := coords.(*mysql.FileBinlogCoordinates)
+			return ok && fileCoords.IsPurged(binaryLogs)
+		}
+	}
+	// retentionRemaining returns the seconds until the binary log holding given coordinates, written no later
+	// than given time, is due to be purged
+	retentionRemaining := func(coords mysql.BinlogCoordinates, writtenAt time.Time) int64 {
+		if isPurged(coords) {
+			return 0
+		}
+		if retentionSeconds == 0 || writtenAt.IsZero() {
+			return base.BinlogRetentionUnknown
+		}
+		// The binary log may be purged once it is older than the retention, yet is only purged upon rotation
+		return int64(math.Max(float64(retentionSeconds)-time.Since(writtenAt).Seconds(), 1))
+	}
+
+	appliedRemaining := retentionRemaining(appliedCoords, this.migrationContext.GetLastHeartbeatOnChangelogTime())
+	remaining := appliedRemaining
+	if chk := this.getLastCheckpoint(); chk != nil {
+		checkpointRemaining := retentionRemaining(chk.LastTrxCoords, chk.binlogWrittenAt())
+		if checkpointRemaining == 0 && this.migrationContext.GetBinlogRetentionRemaining() != 0 {
+			this.migrationContext.Log.Errorf("Binary logs at the last checkpoint's coordinates %+v have been purged. The migration cannot resume from it until the next checkpoint", chk.LastTrxCoords.DisplayString())
+		}
+		if checkpointRemaining >= 0 && (remaining < 0 || checkpointRemaining < remaining) {
+			remaining = checkpointRemaining
+		}
+	}
+
+	wasAtRisk := this.migrationContext.IsBinlogRetentionAtRisk()
+	this.migrationContext.SetBinlogRetentionRemaining(remaining)
+	if this.migrationContext.IsBinlogRetentionAtRisk() && !wasAtRisk {
+		this.migrationContext.Log.Warningf("Binary logs needed by the migration are due to be purged within %s (binlog retention: %ds; margin: %ds)",
+			this.migrationContext.GetBinlogRetentionRemainingDescription(), retentionSeconds, atomic.LoadInt64(&this.migrationContext.BinlogRetentionMarginSeconds))
+		if err := this.hooksExecutor.onBinlogRetentionWarning(remaining); err != nil {
+			this.migrationContext.Log.Errore(err)
+		}
+	}
+
+	marginSeconds := atomic.LoadInt64(&this.migrationContext.BinlogRetentionMarginSeconds)
+	// Pausing row copy is of no avail once the binary logs are purged, or when the retention is within the margin anyway
+	pausing := this.migrationContext.BinlogRetentionPauseRowCopy && retentionSeconds > marginSeconds &&
+		appliedRemaining > 0 && appliedRemaining < marginSeconds
+	if pausing != this.migrationContext.IsBinlogRetentionPausingRowCopy() {
+		if pausing {
+			this.migrationContext.Log.Infof("Pausing row copy: applied events are %s away from binlog retention", base.PrettifyDurationOutput(time.Duration(appliedRemaining)*time.Second))
+		} else {
+			this.migrationContext.Log.Infof("Resuming row copy paused by binlog retention")
+		}
+	}
+	this.migrationContext.SetBinlogRetentionPausingRowCopy(pausing)
+	return nil
+}
+
 // executeWriteFuncs writes data via applier: both the rowcopy and the events backlog.
 // This is where the ghost table gets the data. The function fills the data single-threaded.
 // Both event backlog and rowcopy events are polled; the backlog events have precedence.
@@ -1807,6 +2520,18 @@ func (this *Migrator) executeWriteFuncs() error {
 			}
 		default:
 			{
+				if this.migrationContext.IsBinlogBacklogExceeded() || this.migrationContext.IsBinlogRetentionPausingRowCopy() {
+					// Binlog backlog is above --max-binlog-backlog, or the applied events near the binlog
+					// retention: prioritize DML catch-up over row copy
+					select {
+					case eventStruct := <-this.applyEventsQueue:
+						if err := this.onApplyEventStruct(eventStruct); err != nil {
+							return err
+						}
+					case <-time.After(time.Second):
+					}
+					continue
+				}
 				select {
 				case copyRowsFunc := <-this.copyRowsQueue:
 					{
@@ -1815,6 +2540,12 @@ func (this *Migrator) executeWriteFuncs() error {
 						if err := copyRowsFunc(); err != nil {
 							return this.migrationContext.Log.Errore(err)
 						}
+						if eventStruct := this.heldEventStruct; eventStruct != nil {
+							this.heldEventStruct = nil
+							if err := this.onApplyEventStruct(eventStruct); err != nil {
+								return err
+							}
+						}
 						if niceRatio := this.migrationContext.GetNiceRatio(); niceRatio > 0 {
 							copyRowsDuration := time.Since(copyRowsStartTime)
 							sleepTimeNanosecondFloat64 := niceRatio * float64(copyRowsDuration.Nanoseconds())
@@ -1926,8 +2657,17 @@ func (this *Migrator) teardown() {
 		this.eventsStreamer.Teardown()
 	}
 
+	if this.peer != nil {
+		this.migrationContext.Log.Infof("Tearing down peer")
+		this.peer.Teardown()
+	}
+
 	if this.throttler != nil {
 		this.migrationContext.Log.Infof("Tearing down throttler")
 		this.throttler.Teardown()
 	}
+
+	if err := this.migrationContext.DBCache.Close(); err != nil {
+		this.migrationContext.Log.Errore(err)
+	}
 }
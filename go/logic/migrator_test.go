@@ -31,6 +31,7 @@ import (
 	"github.com/github/gh-ost/go/binlog"
 	"github.com/github/gh-ost/go/mysql"
 	"github.com/github/gh-ost/go/sql"
+	"github.com/openark/golib/log"
 	"github.com/testcontainers/testcontainers-go"
 )
 
@@ -55,6 +56,18 @@ func TestMigratorOnChangelogEvent(t *testing.T) {
 		}))
 	})
 
+	t.Run("heartbeat-minimal-row-image", func(t *testing.T) {
+		require.Nil(t, migrator.onChangelogEvent(&binlog.BinlogEntry{
+			DmlEvent: &binlog.BinlogDMLEvent{
+				DatabaseName:      "test",
+				DML:               binlog.UpdateDML,
+				WhereColumnValues: sql.ToPartialColumnValues([]interface{}{int64(1), nil, nil, nil}, []int{1, 2, 3}),
+				NewColumnValues:   sql.ToPartialColumnValues([]interface{}{nil, time.Now().Unix(), nil, "2022-08-16T00:45:11.52Z"}, []int{0, 2})},
+			Coordinates: mysql.NewFileBinlogCoordinates("mysql-bin.000004", int64(5)),
+		}))
+		require.Equal(t, "2022-08-16T00:45:11.52Z", migrator.migrationContext.GetLastHeartbeatOnChangelogTime().Format(time.RFC3339Nano))
+	})
+
 	t.Run("state-AllEventsUpToLockProcessed", func(t *testing.T) {
 		var wg sync.WaitGroup
 		wg.Add(1)
@@ -270,6 +283,45 @@ func TestMigratorGetMigrationStateAndETA(t *testing.T) {
 		require.Equal(t, "due", eta)
 		require.Equal(t, "0s", etaDuration.String())
 	}
+	{
+		atomic.StoreInt64(&migrationContext.IsPostponingCutOver, 0)
+		migrationContext.SetBinlogRetentionPausingRowCopy(true)
+		state, _, _ := migrator.getMigrationStateAndETA(123456)
+		require.Equal(t, "catching up within binlog retention", state)
+		migrationContext.SetBinlogRetentionPausingRowCopy(false)
+	}
+}
+
+func TestMigratorGetMigrationETARange(t *testing.T) {
+	migrationContext := base.NewMigrationContext()
+	migrator := NewMigrator(migrationContext, "1.2.3")
+
+	etaMin, etaMax := migrator.getMigrationETARange(123456)
+	require.Equal(t, time.Duration(base.ETAUnknown), etaMin)
+	require.Equal(t, time.Duration(base.ETAUnknown), etaMax)
+
+	start := time.Now()
+	var rowsCopied int64
+	for i := 0; i <= 10; i++ {
+		if i%2 == 1 {
+			rowsCopied += 150
+		} else if i > 0 {
+			rowsCopied += 50
+		}
+		migrator.throughputEstimator.AddSample(start.Add(time.Duration(i)*time.Second), rowsCopied, int64(i*10), false)
+	}
+	migrationContext.TotalRowsCopied = rowsCopied
+	etaMin, etaMax = migrator.getMigrationETARange(rowsCopied + 15000)
+	require.Equal(t, "1m40s", etaMin.String())
+	require.Equal(t, "5m0s", etaMax.String())
+
+	// backlog of 100 events, applied at 10 per second
+	for i := 0; i < 100; i++ {
+		migrator.applyEventsQueue <- &applyEventStruct{}
+	}
+	etaMin, etaMax = migrator.getMigrationETARange(rowsCopied + 15000)
+	require.Equal(t, "1m50s", etaMin.String())
+	require.Equal(t, "5m10s", etaMax.String())
 }
 
 func TestMigratorShouldPrintStatus(t *testing.T) {
@@ -396,6 +448,71 @@ func (suite *MigratorTestSuite) TestMigrateEmpty() {
 	suite.Require().Equal("~testing_del", tableName)
 }
 
+func (suite *MigratorTestSuite) TestMigrateConcurrently() {
+	ctx := context.Background()
+
+	tableNames := []string{"testing_a", "testing_b"}
+	for _, tableName := range tableNames {
+		_, err := suite.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s.%s (id INT PRIMARY KEY, name VARCHAR(64))", testMysqlDatabase, tableName))
+		suite.Require().NoError(err)
+		_, err = suite.db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s.%s (id, name) VALUES (1, 'one'), (2, 'two'), (3, 'three')", testMysqlDatabase, tableName))
+		suite.Require().NoError(err)
+		defer func(tableName string) {
+			_, _ = suite.db.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s.%s, %s.`~%s_del`", testMysqlDatabase, tableName, testMysqlDatabase, tableName))
+		}(tableName)
+	}
+
+	migrationContexts := make([]*base.MigrationContext, len(tableNames))
+	logs := make([]*bytes.Buffer, len(tableNames))
+	for i, tableName := range tableNames {
+		connectionConfig, err := getTestConnectionConfig(ctx, suite.mysqlContainer)
+		suite.Require().NoError(err)
+
+		migrationContext := newTestMigrationContext()
+		migrationContext.ApplierConnectionConfig = connectionConfig
+		migrationContext.InspectorConnectionConfig = connectionConfig
+		migrationContext.OriginalTableName = tableName
+		migrationContext.ReplicaServerId = uint(99990 + i)
+		migrationContext.ServeSocketFile = filepath.Join(suite.T().TempDir(), "gh-ost.sock")
+		migrationContext.SetConnectionConfig("innodb")
+		migrationContext.AlterStatementOptions = fmt.Sprintf("ADD COLUMN %s_col INT, ENGINE=InnoDB", tableName)
+		logs[i] = &bytes.Buffer{}
+		migrationContext.Log = base.NewWriterLogger(logs[i])
+		migrationContexts[i] = migrationContext
+	}
+	// a migration's log level does not affect the other's
+	migrationContexts[1].Log.SetLevel(log.ERROR)
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(tableNames))
+	for i := range tableNames {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = NewMigrator(migrationContexts[i], "0.0.0").Migrate()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, tableName := range tableNames {
+		suite.Require().NoError(errs[i])
+		suite.Require().Equal(int64(3), migrationContexts[i].GetTotalRowsCopied())
+
+		// each table has its own column, and only that
+		var createTableName, createTableSQL string
+		//nolint:execinquery
+		err := suite.db.QueryRow(fmt.Sprintf("SHOW CREATE TABLE %s.%s", testMysqlDatabase, tableName)).Scan(&createTableName, &createTableSQL)
+		suite.Require().NoError(err)
+		for _, otherTableName := range tableNames {
+			suite.Require().Equal(otherTableName == tableName, strings.Contains(createTableSQL, otherTableName+"_col"))
+		}
+	}
+	suite.Require().NotSame(migrationContexts[0].DBCache, migrationContexts[1].DBCache)
+	suite.Require().Contains(logs[0].String(), "testing_a")
+	suite.Require().NotContains(logs[0].String(), "testing_b")
+	suite.Require().Empty(logs[1].String())
+}
+
 func (suite *MigratorTestSuite) TestRetryBatchCopyWithHooks() {
 	ctx := context.Background()
 
@@ -836,6 +953,75 @@ func TestMigratorRetryWithExponentialBackoffSkipsRetriesForWarnings(t *testing.T
 	assert.Contains(t, result.Error(), "warnings detected")
 }
 
+func (suite *MigratorTestSuite) TestApplyEventsUpToCoordinatesBeyondQueueCapacity() {
+	ctx := context.Background()
+
+	_, err := suite.db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (id INT, name VARCHAR(64))", getTestGhostTableName()))
+	suite.Require().NoError(err)
+
+	connectionConfig, err := getTestConnectionConfig(ctx, suite.mysqlContainer)
+	suite.Require().NoError(err)
+
+	migrationContext := newTestMigrationContext()
+	migrationContext.ApplierConnectionConfig = connectionConfig
+	migrationContext.SetConnectionConfig("innodb")
+	migrationContext.OriginalTableColumns = sql.NewColumnList([]string{"id", "name"})
+	migrationContext.SharedColumns = sql.NewColumnList([]string{"id", "name"})
+	migrationContext.MappedSharedColumns = sql.NewColumnList([]string{"id", "name"})
+	migrationContext.UniqueKey = &sql.UniqueKey{
+		Name:        "idx_name",
+		Columns:     *sql.NewColumnList([]string{"name"}),
+		IsNonUnique: true,
+	}
+
+	migrator := NewMigrator(migrationContext, "0.0.0")
+	migrator.applier = NewApplier(migrationContext)
+	suite.Require().NoError(migrator.applier.InitDBConnections())
+	suite.Require().NoError(migrator.applier.prepareQueries())
+	defer migrator.applier.Teardown()
+
+	// The streamer dispatches more events ahead of the snapshot coordinates than the queue holds
+	numEvents := cap(migrator.applyEventsQueue) + 100
+	eventCoords := func(i int) mysql.BinlogCoordinates {
+		return mysql.NewFileBinlogCoordinates("mysql-bin.000001", int64(1000+i))
+	}
+	go func() {
+		for i := 1; i <= numEvents+1; i++ {
+			entry := &binlog.BinlogEntry{
+				Coordinates: eventCoords(i),
+				DmlEvent: &binlog.BinlogDMLEvent{
+					DatabaseName:    testMysqlDatabase,
+					TableName:       testMysqlTableName,
+					DML:             binlog.InsertDML,
+					NewColumnValues: sql.ToColumnValues([]interface{}{i, "name"}),
+				},
+			}
+			if err := base.SendWithContext(migrationContext.GetContext(), migrator.applyEventsQueue, newApplyEventStructByDML(entry)); err != nil {
+				return
+			}
+			migrator.setDispatchedCoordinates(entry.Coordinates)
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- migrator.applyEventsUpToCoordinates(eventCoords(numEvents))
+	}()
+	select {
+	case err := <-done:
+		suite.Require().NoError(err)
+	case <-time.After(time.Minute):
+		migrationContext.CancelContext()
+		suite.FailNow("applyEventsUpToCoordinates did not return with a full events queue")
+	}
+
+	var count int
+	suite.Require().NoError(suite.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", getTestGhostTableName())).Scan(&count))
+	suite.Require().Equal(numEvents, count)
+	suite.Require().NotNil(migrator.heldEventStruct)
+	suite.Require().Equal(eventCoords(numEvents+1).DisplayString(), migrator.heldEventStruct.coords.DisplayString())
+}
+
 func (suite *MigratorTestSuite) TestCutOverLossDataCaseLockGhostBeforeRename() {
 	ctx := context.Background()
 
@@ -1084,6 +1270,35 @@ func TestPanicAbort_PropagatesError(t *testing.T) {
 	}
 }
 
+func TestMigratorDetachRefused(t *testing.T) {
+	migrationContext := base.NewMigrationContext()
+	migrator := NewMigrator(migrationContext, "1.0.0")
+
+	_, err := migrator.Detach()
+	require.ErrorContains(t, err, "requires --checkpoint")
+
+	migrationContext.Checkpoint = true
+	migrationContext.NonUniqueKeyAllowed = true
+	migrationContext.UniqueKey = &sql.UniqueKey{Name: "idx_name", IsNonUnique: true}
+	_, err = migrator.Detach()
+	require.ErrorContains(t, err, "Migration key is non-unique")
+
+	migrationContext.NonUniqueKeyAllowed = false
+	migrationContext.UniqueKey = nil
+	_, err = migrator.Detach()
+	require.ErrorContains(t, err, "Row copy has not begun")
+
+	migrationContext.MarkRowCopyStartTime()
+	atomic.StoreInt64(&migrator.cutOverOrDetach, cuttingOver)
+	_, err = migrator.Detach()
+	require.ErrorContains(t, err, "Cut-over has begun")
+
+	atomic.StoreInt64(&migrator.cutOverOrDetach, detaching)
+	_, err = migrator.Detach()
+	require.ErrorContains(t, err, "Already detaching")
+	require.Nil(t, migrationContext.GetAbortError())
+}
+
 func TestPanicAbort_FirstErrorWins(t *testing.T) {
 	migrationContext := base.NewMigrationContext()
 	migrator := NewMigrator(migrationContext, "1.0.0")
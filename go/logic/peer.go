@@ -0,0 +1,138 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package logic
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/github/gh-ost/go/base"
+	"github.com/github/gh-ost/go/mysql"
+
+	"github.com/openark/golib/sqlutils"
+)
+
+// Peer is the other master of an active-active master-master setup, where the original table
+// is written to on both masters. The migration runs on one master, whose binary logs carry the
+// peer's changes as replicated. The peer is only connected to at cut-over: the peer is made
+// read_only, which blocks its clients' writes but not replication, so that the cut-over's RENAME
+// replicates onto the peer behind the migrated master's writes, ahead of any new peer write.
+type Peer struct {
+	connectionConfig *mysql.ConnectionConfig
+	db               *gosql.DB
+	dbVersion        string
+	migrationContext *base.MigrationContext
+	name             string
+	originalReadOnly bool
+}
+
+func NewPeer(migrationContext *base.MigrationContext) *Peer {
+	return &Peer{
+		connectionConfig: migrationContext.PeerConnectionConfig,
+		migrationContext: migrationContext,
+		name:             "peer",
+	}
+}
+
+func (this *Peer) InitDBConnections() (err error) {
+	peerUri := this.connectionConfig.GetDBUri(this.migrationContext.DatabaseName)
+	if this.db, _, err = this.migrationContext.DBCache.GetDB(peerUri, this.connectionConfig); err != nil {
+		return err
+	}
+	if this.dbVersion, err = base.ValidateConnection(this.db, this.connectionConfig, this.migrationContext, this.name); err != nil {
+		return err
+	}
+	if err := this.validateBinlogs(); err != nil {
+		return err
+	}
+	this.migrationContext.Log.Infof("Peer initiated on %+v, version %+v", this.connectionConfig.Key, this.dbVersion)
+	return nil
+}
+
+// validateBinlogs checks that the peer logs its changes the way the migrated master does. The
+// migrated master re-logs the peer's row events as replicated, in the peer's row image.
+func (this *Peer) validateBinlogs() error {
+	var gtidMode, binlogFormat, binlogRowImage string
+	query := `select /* gh-ost */ @@global.gtid_mode, @@global.binlog_format, @@global.binlog_row_image`
+	if err := this.db.QueryRow(query).Scan(&gtidMode, &binlogFormat, &binlogRowImage); err != nil {
+		return err
+	}
+	if !strings.EqualFold(gtidMode, "ON") {
+		return fmt.Errorf("Peer %s must have gtid_mode=ON", this.connectionConfig.Key.String())
+	}
+	if !strings.EqualFold(binlogFormat, "ROW") {
+		return fmt.Errorf("Peer %s has %s binlog_format, and must be using ROW binlog format", this.connectionConfig.Key.String(), binlogFormat)
+	}
+	if !strings.EqualFold(binlogRowImage, this.migrationContext.OriginalBinlogRowImage) {
+		return fmt.Errorf("Peer %s has '%s' binlog_row_image, while %s has '%s'. Both masters must log the same row image", this.connectionConfig.Key.String(), binlogRowImage, this.migrationContext.InspectorConnectionConfig.Key.String(), this.migrationContext.OriginalBinlogRowImage)
+	}
+	return nil
+}
+
+// ReadExecutedGTIDs reads the GTIDs executed on the peer
+func (this *Peer) ReadExecutedGTIDs() (mysql.BinlogCoordinates, error) {
+	return mysql.GetSelfBinlogCoordinates(this.dbVersion, this.db, true)
+}
+
+// WaitForExecutedGTIDs waits up to given timeout for the peer to execute given GTID coordinates
+func (this *Peer) WaitForExecutedGTIDs(coords mysql.BinlogCoordinates, timeoutSeconds int64) error {
+	var timedOut int64
+	query := `select /* gh-ost */ wait_for_executed_gtid_set(?, ?)`
+	if err := this.db.QueryRow(query, coords.String(), timeoutSeconds).Scan(&timedOut); err != nil {
+		return err
+	}
+	if timedOut != 0 {
+		return fmt.Errorf("Timeout of %d seconds waiting for peer %s to execute %s", timeoutSeconds, this.connectionConfig.Key.String(), coords.String())
+	}
+	return nil
+}
+
+// SetReadOnly blocks writes on the peer, other than replicated writes. The peer's read_only
+// setting is kept, to be restored by RestoreReadOnly.
+func (this *Peer) SetReadOnly() error {
+	if err := this.db.QueryRow(`select /* gh-ost */ @@global.read_only`).Scan(&this.originalReadOnly); err != nil {
+		return err
+	}
+	if this.originalReadOnly {
+		this.migrationContext.Log.Infof("Peer %s is read_only", this.connectionConfig.Key.String())
+		return nil
+	}
+	this.migrationContext.Log.Infof("Setting peer %s read_only", this.connectionConfig.Key.String())
+	conn, err := this.db.Conn(this.migrationContext.GetContext())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// Setting read_only waits for ongoing transactions to commit
+	query := fmt.Sprintf(`set /* gh-ost */ session lock_wait_timeout:=%d`, this.migrationContext.CutOverLockTimeoutSeconds)
+	if _, err := conn.ExecContext(this.migrationContext.GetContext(), query); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(this.migrationContext.GetContext(), `set /* gh-ost */ global read_only=1`); err != nil {
+		return err
+	}
+	this.migrationContext.Log.Infof("Peer set read_only")
+	return nil
+}
+
+// RestoreReadOnly restores the peer's read_only setting, as read by SetReadOnly
+func (this *Peer) RestoreReadOnly() error {
+	if this.originalReadOnly {
+		return nil
+	}
+	this.migrationContext.Log.Infof("Unsetting peer %s read_only", this.connectionConfig.Key.String())
+	if _, err := sqlutils.ExecNoPrepare(this.db, `set /* gh-ost */ global read_only=0`); err != nil {
+		return err
+	}
+	this.migrationContext.Log.Infof("Peer read_only unset")
+	return nil
+}
+
+func (this *Peer) Teardown() {
+	this.migrationContext.Log.Debugf("Tearing down...")
+	this.db.Close()
+}
@@ -32,6 +32,7 @@ var (
 )
 
 type printStatusFunc func(PrintStatusRule, io.Writer)
+type detachFunc func() (*Checkpoint, error)
 
 // Server listens for requests on a socket file or via TCP
 type Server struct {
@@ -40,14 +41,16 @@ type Server struct {
 	tcpListener      net.Listener
 	hooksExecutor    *HooksExecutor
 	printStatus      printStatusFunc
+	detach           detachFunc
 	isCPUProfiling   int64
 }
 
-func NewServer(migrationContext *base.MigrationContext, hooksExecutor *HooksExecutor, printStatus printStatusFunc) *Server {
+func NewServer(migrationContext *base.MigrationContext, hooksExecutor *HooksExecutor, printStatus printStatusFunc, detach detachFunc) *Server {
 	return &Server{
 		migrationContext: migrationContext,
 		hooksExecutor:    hooksExecutor,
 		printStatus:      printStatus,
+		detach:           detach,
 	}
 }
 
@@ -221,13 +224,16 @@ status                               # Print a detailed status message
 sup                                  # Print a short status message
 cpu-profile=<options>                # Print a base64-encoded runtime/pprof CPU profile using a duration, default: 30s. Comma-separated options 'gzip' and/or 'block' (blocked profile) may follow the profile duration
 coordinates                          # Print the currently inspected coordinates
+backlog                              # Print the binlog apply backlog: queued events, distance behind the binlog head, apply rate
 applier                              # Print the hostname of the applier
 inspector                            # Print the hostname of the inspector
 chunk-size=<newsize>                 # Set a new chunk-size
 dml-batch-size=<newsize>             # Set a new dml-batch-size
+max-dml-batch-size=<newsize>         # Set a new max-dml-batch-size, above dml-batch-size to size DML batches dynamically; 0 disables
 nice-ratio=<ratio>                   # Set a new nice-ratio, immediate sleep after each row-copy operation, float (examples: 0 is aggressive, 0.7 adds 70% runtime, 1.0 doubles runtime, 2.0 triples runtime, ...)
 critical-load=<load>                 # Set a new set of max-load thresholds
 max-lag-millis=<max-lag>             # Set a new replication lag threshold
+max-binlog-backlog=<backlog>         # Set a new binlog backlog threshold (bytes, or transactions with --gtid) beyond which row copy pauses; 0 disables
 replication-lag-query=<query>        # Set a new query that determines replication lag (no quotes)
 max-load=<load>                      # Set a new set of max-load thresholds
 throttle-query=<query>               # Set a new throttle-query (no quotes)
@@ -237,6 +243,7 @@ throttle                             # Force throttling
 no-throttle                          # End forced throttling (other throttling may still apply)
 postpone-cut-over-flag-file=<path>   # Postpone the cut-over phase, writing a cut over flag file to the given path
 unpostpone                           # Bail out a cut-over postpone; proceed to cut-over
+detach                               # Checkpoint, stop streaming and quit without cleanup; re-attach with --resume. Requires --checkpoint
 panic                                # panic and quit without cleanup
 help                                 # This message
 - use '?' (question mark) as argument to get info rather than set. e.g. "max-load=?" will just print out current max-load.
@@ -260,6 +267,19 @@ help                                 # This message
 			}
 			return NoPrintStatusRule, fmt.Errorf("coordinates are read-only")
 		}
+	case "backlog":
+		{
+			if argIsQuestion || arg == "" {
+				fmt.Fprintf(writer, "Queued: %d/%d; Binlog behind: %s; Apply rate: %.1f/s; Max binlog backlog: %d\n",
+					this.migrationContext.GetApplyEventsQueued(), base.MaxEventsBatchSize,
+					this.migrationContext.GetBinlogBacklogDescription(),
+					this.migrationContext.GetDMLApplyRate(),
+					atomic.LoadInt64(&this.migrationContext.MaxBinlogBacklog),
+				)
+				return NoPrintStatusRule, nil
+			}
+			return NoPrintStatusRule, fmt.Errorf("backlog is read-only")
+		}
 	case "applier":
 		if this.migrationContext.ApplierConnectionConfig != nil && this.migrationContext.ApplierConnectionConfig.ImpliedKey != nil {
 			fmt.Fprintf(writer, "Host: %s, Version: %s\n",
@@ -302,6 +322,19 @@ help                                 # This message
 				return ForcePrintStatusAndHintRule, nil
 			}
 		}
+	case "max-dml-batch-size":
+		{
+			if argIsQuestion {
+				fmt.Fprintf(writer, "%+v\n", atomic.LoadInt64(&this.migrationContext.MaxDMLBatchSize))
+				return NoPrintStatusRule, nil
+			}
+			if maxDMLBatchSize, err := strconv.Atoi(arg); err != nil {
+				return NoPrintStatusRule, err
+			} else {
+				this.migrationContext.SetMaxDMLBatchSize(int64(maxDMLBatchSize))
+				return ForcePrintStatusAndHintRule, nil
+			}
+		}
 	case "max-lag-millis":
 		{
 			if argIsQuestion {
@@ -315,6 +348,19 @@ help                                 # This message
 				return ForcePrintStatusAndHintRule, nil
 			}
 		}
+	case "max-binlog-backlog":
+		{
+			if argIsQuestion {
+				fmt.Fprintf(writer, "%+v\n", atomic.LoadInt64(&this.migrationContext.MaxBinlogBacklog))
+				return NoPrintStatusRule, nil
+			}
+			if maxBinlogBacklog, err := strconv.ParseInt(arg, 10, 64); err != nil {
+				return NoPrintStatusRule, err
+			} else {
+				this.migrationContext.SetMaxBinlogBacklog(maxBinlogBacklog)
+				return ForcePrintStatusAndHintRule, nil
+			}
+		}
 	case "replication-lag-query":
 		{
 			return NoPrintStatusRule, fmt.Errorf("replication-lag-query is deprecated. gh-ost uses an internal, subsecond resolution query")
@@ -441,6 +487,20 @@ help                                 # This message
 			fmt.Fprintf(writer, "You may only invoke this when gh-ost is actively postponing migration. At this time it is not.\n")
 			return NoPrintStatusRule, nil
 		}
+	case "detach":
+		{
+			if arg != "" && arg != this.migrationContext.OriginalTableName {
+				// User explicitly provided table name. This is a courtesy protection mechanism
+				err := fmt.Errorf("User commanded 'detach' on %s, but migrated table is %s; ignoring request.", arg, this.migrationContext.OriginalTableName)
+				return NoPrintStatusRule, err
+			}
+			chk, err := this.detach()
+			if err != nil {
+				return NoPrintStatusRule, err
+			}
+			fmt.Fprintf(writer, "Detached at %s. Re-attach with --resume\n", chk.LastTrxCoords.DisplayString())
+			return NoPrintStatusRule, nil
+		}
 	case "panic":
 		{
 			if arg == "" && this.migrationContext.ForceNamedPanicCommand {
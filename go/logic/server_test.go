@@ -1,6 +1,8 @@
 package logic
 
 import (
+	"bufio"
+	"bytes"
 	"os"
 	"path"
 	"testing"
@@ -103,3 +105,37 @@ func TestServerCreatePostponeCutOverFlagFile(t *testing.T) {
 		require.FileExists(t, filePath)
 	})
 }
+
+func TestServerApplyServerCommandBinlogBacklog(t *testing.T) {
+	migrationContext := base.NewMigrationContext()
+	s := &Server{
+		migrationContext: migrationContext,
+		hooksExecutor:    NewHooksExecutor(migrationContext),
+	}
+
+	var buf bytes.Buffer
+	writer := bufio.NewWriter(&buf)
+	rule, err := s.applyServerCommand("backlog", writer)
+	require.NoError(t, err)
+	require.Equal(t, NoPrintStatusRule, rule)
+	writer.Flush()
+	require.Equal(t, "Queued: 0/1000; Binlog behind: N/A; Apply rate: 0.0/s; Max binlog backlog: 0\n", buf.String())
+
+	rule, err = s.applyServerCommand("max-binlog-backlog=1048576", writer)
+	require.NoError(t, err)
+	require.Equal(t, PrintStatusRule(ForcePrintStatusAndHintRule), rule)
+	require.Equal(t, int64(1048576), migrationContext.MaxBinlogBacklog)
+	require.False(t, migrationContext.IsBinlogBacklogExceeded())
+
+	migrationContext.SetBinlogBacklog(2097152)
+	require.True(t, migrationContext.IsBinlogBacklogExceeded())
+
+	buf.Reset()
+	_, err = s.applyServerCommand("backlog", writer)
+	require.NoError(t, err)
+	writer.Flush()
+	require.Equal(t, "Queued: 0/1000; Binlog behind: 2097152 bytes; Apply rate: 0.0/s; Max binlog backlog: 1048576\n", buf.String())
+
+	_, err = s.applyServerCommand("max-binlog-backlog=abc", writer)
+	require.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/github/gh-ost/go/base"
@@ -46,6 +47,13 @@ type EventsStreamer struct {
 	eventsChannel            chan *binlog.BinlogEntry
 	binlogReader             *binlog.GoMySQLReader
 	name                     string
+	// failoverSource, when set, is called when the binlog stream cannot resume off the streamed
+	// server. It returns the connection config of another server to resume streaming off.
+	failoverSource func(failedConfig *mysql.ConnectionConfig, coords mysql.BinlogCoordinates) (*mysql.ConnectionConfig, error)
+	// failoverRequested is set when the streamed server is found to no longer replicate from the applier
+	failoverRequested int64
+	// connectionConfigMutex guards the streamed server's connection and binlog reader, which change on failover
+	connectionConfigMutex *sync.Mutex
 }
 
 func NewEventsStreamer(migrationContext *base.MigrationContext) *EventsStreamer {
@@ -54,6 +62,7 @@ func NewEventsStreamer(migrationContext *base.MigrationContext) *EventsStreamer
 		migrationContext:         migrationContext,
 		listeners:                [](*BinlogEventListener){},
 		listenersMutex:           &sync.Mutex{},
+		connectionConfigMutex:    &sync.Mutex{},
 		eventsChannel:            make(chan *binlog.BinlogEntry, EventsChannelBufferSize),
 		name:                     "streamer",
 		initialBinlogCoordinates: migrationContext.InitialStreamerCoords,
@@ -90,10 +99,10 @@ func (this *EventsStreamer) notifyListeners(binlogEntry *binlog.BinlogEntry) {
 
 	for _, listener := range this.listeners {
 		listener := listener
-		if !strings.EqualFold(listener.databaseName, binlogEntry.DmlEvent.DatabaseName) {
+		if !this.migrationContext.TableNamesEqual(listener.databaseName, binlogEntry.DmlEvent.DatabaseName) {
 			continue
 		}
-		if !strings.EqualFold(listener.tableName, binlogEntry.DmlEvent.TableName) {
+		if !this.migrationContext.TableNamesEqual(listener.tableName, binlogEntry.DmlEvent.TableName) {
 			continue
 		}
 		if listener.async {
@@ -108,7 +117,7 @@ func (this *EventsStreamer) notifyListeners(binlogEntry *binlog.BinlogEntry) {
 
 func (this *EventsStreamer) InitDBConnections() (err error) {
 	EventsStreamerUri := this.connectionConfig.GetDBUri(this.migrationContext.DatabaseName)
-	if this.db, _, err = mysql.GetDB(this.migrationContext.Uuid, EventsStreamerUri); err != nil {
+	if this.db, _, err = this.migrationContext.DBCache.GetDB(EventsStreamerUri, this.connectionConfig); err != nil {
 		return err
 	}
 	version, err := base.ValidateConnection(this.db, this.connectionConfig, this.migrationContext, this.name)
@@ -130,10 +139,15 @@ func (this *EventsStreamer) InitDBConnections() (err error) {
 
 // initBinlogReader creates and connects the reader: we hook up to a MySQL server as a replica
 func (this *EventsStreamer) initBinlogReader(binlogCoordinates mysql.BinlogCoordinates) error {
-	goMySQLReader := binlog.NewGoMySQLReader(this.migrationContext)
+	goMySQLReader, err := binlog.NewGoMySQLReader(this.migrationContext, this.connectionConfig)
+	if err != nil {
+		return err
+	}
 	if err := goMySQLReader.ConnectBinlogStreamer(binlogCoordinates); err != nil {
 		return err
 	}
+	this.connectionConfigMutex.Lock()
+	defer this.connectionConfigMutex.Unlock()
 	this.binlogReader = goMySQLReader
 	return nil
 }
@@ -188,6 +202,9 @@ func (this *EventsStreamer) StreamEvents(canStopStreaming func() bool) error {
 	var successiveFailures int
 	var reconnectCoords mysql.BinlogCoordinates
 	ctx := this.migrationContext.GetContext()
+	canStopStreamingOrFailOver := func() bool {
+		return canStopStreaming() || atomic.LoadInt64(&this.failoverRequested) > 0
+	}
 	for {
 		// Check for context cancellation each iteration
 		if err := ctx.Err(); err != nil {
@@ -196,16 +213,28 @@ func (this *EventsStreamer) StreamEvents(canStopStreaming func() bool) error {
 		if canStopStreaming() {
 			return nil
 		}
+		if atomic.CompareAndSwapInt64(&this.failoverRequested, 1, 0) {
+			reconnectCoords = this.lastTrxCoordinates()
+			_ = this.binlogReader.Close()
+			if err := this.failOver(reconnectCoords); err != nil {
+				return err
+			}
+			successiveFailures = 0
+			continue
+		}
 		// We will reconnect the binlog streamer at the coordinates
 		// of the last trx that was read completely from the streamer.
 		// Since row event application is idempotent, it's OK if we reapply some events.
-		if err := this.binlogReader.StreamEvents(canStopStreaming, this.eventsChannel); err != nil {
+		if err := this.binlogReader.StreamEvents(canStopStreamingOrFailOver, this.eventsChannel); err != nil {
 			if canStopStreaming() {
 				return nil
 			}
 			if errors.Is(err, binlog.ErrMaxAuthFailures) {
 				return err
 			}
+			if atomic.LoadInt64(&this.failoverRequested) > 0 {
+				continue
+			}
 
 			this.migrationContext.Log.Infof("StreamEvents encountered unexpected error: %+v", err)
 			this.migrationContext.MarkPointOfInterest()
@@ -213,15 +242,20 @@ func (this *EventsStreamer) StreamEvents(canStopStreaming func() bool) error {
 
 			// See if there's retry overflow
 			if this.migrationContext.BinlogSyncerMaxReconnectAttempts > 0 && successiveFailures >= this.migrationContext.BinlogSyncerMaxReconnectAttempts {
-				return fmt.Errorf("%d successive failures in streamer reconnect at coordinates %+v", successiveFailures, reconnectCoords)
+				if this.failoverSource == nil {
+					return fmt.Errorf("%d successive failures in streamer reconnect at coordinates %+v", successiveFailures, reconnectCoords)
+				}
+				this.migrationContext.Log.Errorf("%d successive failures in streamer reconnect at coordinates %+v", successiveFailures, reconnectCoords)
+				_ = this.binlogReader.Close()
+				if err := this.failOver(reconnectCoords); err != nil {
+					return err
+				}
+				successiveFailures = 0
+				continue
 			}
 
 			// Reposition at same coordinates
-			if this.binlogReader.LastTrxCoords != nil {
-				reconnectCoords = this.binlogReader.LastTrxCoords.Clone()
-			} else {
-				reconnectCoords = this.initialBinlogCoordinates.Clone()
-			}
+			reconnectCoords = this.lastTrxCoordinates()
 			if !reconnectCoords.SmallerThan(this.GetCurrentBinlogCoordinates()) {
 				successiveFailures += 1
 			} else {
@@ -231,12 +265,71 @@ func (this *EventsStreamer) StreamEvents(canStopStreaming func() bool) error {
 			this.migrationContext.Log.Infof("Reconnecting EventsStreamer... Will resume at %+v", reconnectCoords)
 			_ = this.binlogReader.Close()
 			if err := this.initBinlogReader(reconnectCoords); err != nil {
-				return err
+				if this.failoverSource == nil {
+					return err
+				}
+				this.migrationContext.Log.Errorf("Unable to reconnect EventsStreamer at %+v: %+v", reconnectCoords, err)
+				if err := this.failOver(reconnectCoords); err != nil {
+					return err
+				}
+				successiveFailures = 0
 			}
 		}
 	}
 }
 
+// lastTrxCoordinates returns the coordinates of the last transaction read completely from the streamer
+func (this *EventsStreamer) lastTrxCoordinates() mysql.BinlogCoordinates {
+	if this.binlogReader.LastTrxCoords != nil {
+		return this.binlogReader.LastTrxCoords.Clone()
+	}
+	return this.initialBinlogCoordinates.Clone()
+}
+
+// getConnectionConfig returns the connection config of the streamed server
+func (this *EventsStreamer) getConnectionConfig() *mysql.ConnectionConfig {
+	this.connectionConfigMutex.Lock()
+	defer this.connectionConfigMutex.Unlock()
+	return this.connectionConfig
+}
+
+// requestFailover makes the streamer fail over to another server, as given by failoverSource. The
+// current stream is closed, and streaming resumes at the last transaction read completely.
+func (this *EventsStreamer) requestFailover() {
+	if !atomic.CompareAndSwapInt64(&this.failoverRequested, 0, 1) {
+		return
+	}
+	this.connectionConfigMutex.Lock()
+	defer this.connectionConfigMutex.Unlock()
+	if this.binlogReader != nil {
+		_ = this.binlogReader.Close()
+	}
+}
+
+// failOver resumes streaming at given coordinates off another server, as given by failoverSource.
+// Only GTID coordinates are meaningful across servers.
+func (this *EventsStreamer) failOver(coords mysql.BinlogCoordinates) error {
+	connectionConfig, err := this.failoverSource(this.connectionConfig, coords)
+	if err != nil {
+		return err
+	}
+	db, _, err := this.migrationContext.DBCache.GetDB(connectionConfig.GetDBUri(this.migrationContext.DatabaseName), connectionConfig)
+	if err != nil {
+		return err
+	}
+	version, err := base.ValidateConnection(db, connectionConfig, this.migrationContext, this.name)
+	if err != nil {
+		return err
+	}
+	this.migrationContext.Log.Infof("EventsStreamer failing over from %+v to %+v. Will resume at %+v", this.connectionConfig.Key, connectionConfig.Key, coords)
+	this.connectionConfigMutex.Lock()
+	this.connectionConfig = connectionConfig
+	this.db = db
+	this.dbVersion = version
+	this.connectionConfigMutex.Unlock()
+	return this.initBinlogReader(coords)
+}
+
 func (this *EventsStreamer) Close() (err error) {
 	if this.binlogReader != nil {
 		err = this.binlogReader.Close()
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/github/gh-ost/go/binlog"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/mysql"
@@ -74,6 +75,31 @@ func TestEventsStreamerCloseAndTeardownBeforeInit(t *testing.T) {
 	streamer.Teardown()
 }
 
+func TestEventsStreamerNotifyListenersTableNameCase(t *testing.T) {
+	for _, caseSensitive := range []bool{true, false} {
+		t.Run(fmt.Sprintf("case-sensitive=%t", caseSensitive), func(t *testing.T) {
+			migrationContext := newTestMigrationContext()
+			migrationContext.TableNamesCaseSensitive = caseSensitive
+			streamer := NewEventsStreamer(migrationContext)
+
+			var notified []string
+			for _, tableName := range []string{"MyTable", "MYtable"} {
+				tableName := tableName
+				require.NoError(t, streamer.AddListener(false, "test", tableName, func(event *binlog.BinlogEntry) error {
+					notified = append(notified, tableName)
+					return nil
+				}))
+			}
+			streamer.notifyListeners(&binlog.BinlogEntry{DmlEvent: binlog.NewBinlogDMLEvent("test", "MyTable", binlog.InsertDML)})
+			if caseSensitive {
+				require.Equal(t, []string{"MyTable"}, notified)
+			} else {
+				require.Equal(t, []string{"MyTable", "MYtable"}, notified)
+			}
+		})
+	}
+}
+
 func (suite *EventsStreamerTestSuite) TestStreamEvents() {
 	ctx := context.Background()
 
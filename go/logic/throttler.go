@@ -150,7 +150,7 @@ func (this *Throttler) collectReplicationLag(firstThrottlingCollected chan<- boo
 			// when running on replica, the heartbeat injection is also done on the replica.
 			// This means we will always get a good heartbeat value.
 			// When running on replica, we should instead check the `SHOW SLAVE STATUS` output.
-			if lag, err := mysql.GetReplicationLagFromSlaveStatus(this.inspector.dbVersion, this.inspector.informationSchemaDb); err != nil {
+			if lag, err := mysql.GetReplicationLagFromSlaveStatus(this.inspector.dbVersion, this.inspector.informationSchemaDb, this.migrationContext.ReplicationChannel); err != nil {
 				return this.migrationContext.Log.Errore(err)
 			} else {
 				atomic.StoreInt64(&this.migrationContext.CurrentLag, int64(lag))
@@ -195,7 +195,7 @@ func (this *Throttler) collectControlReplicasLag() {
 		dbUri := connectionConfig.GetDBUri("information_schema")
 
 		var heartbeatValue string
-		db, _, err := mysql.GetDB(this.migrationContext.Uuid, dbUri)
+		db, _, err := this.migrationContext.DBCache.GetDB(dbUri, connectionConfig)
 		if err != nil {
 			return lag, err
 		}
@@ -216,9 +216,6 @@ func (this *Throttler) collectControlReplicasLag() {
 		lagResults := make(chan *mysql.ReplicationLagResult, instanceKeyMap.Len())
 		for replicaKey := range *instanceKeyMap {
 			connectionConfig := this.migrationContext.InspectorConnectionConfig.DuplicateCredentials(replicaKey)
-			if err := connectionConfig.RegisterTLSConfig(); err != nil {
-				return &mysql.ReplicationLagResult{Err: err}
-			}
 
 			lagResult := &mysql.ReplicationLagResult{Key: connectionConfig.Key}
 			go func() {
@@ -441,6 +438,59 @@ func (this *Throttler) collectGeneralThrottleMetrics() error {
 	return setThrottle(false, "", base.NoThrottleReasonHint)
 }
 
+// collectBinlogBacklog measures how far the applied binlog events are behind the binlog head
+// of the inspected server: in bytes with file based coordinates, in transactions with GTID.
+func (this *Throttler) collectBinlogBacklog() {
+	collectFunc := func() error {
+		this.applier.CurrentCoordinatesMutex.Lock()
+		appliedCoords := this.applier.CurrentCoordinates
+		this.applier.CurrentCoordinatesMutex.Unlock()
+		if appliedCoords == nil || appliedCoords.IsEmpty() {
+			this.migrationContext.SetBinlogBacklog(base.BinlogBacklogUnknown)
+			return nil
+		}
+
+		headCoords, err := mysql.GetSelfBinlogCoordinates(this.inspector.dbVersion, this.inspector.db, this.migrationContext.UseGTIDs)
+		if err != nil {
+			this.migrationContext.SetBinlogBacklog(base.BinlogBacklogUnknown)
+			return this.migrationContext.Log.Errore(err)
+		}
+		switch head := headCoords.(type) {
+		case *mysql.GTIDBinlogCoordinates:
+			applied, ok := appliedCoords.(*mysql.GTIDBinlogCoordinates)
+			if !ok {
+				return nil
+			}
+			this.migrationContext.SetBinlogBacklog(applied.TransactionsBehind(head))
+		case *mysql.FileBinlogCoordinates:
+			applied, ok := appliedCoords.(*mysql.FileBinlogCoordinates)
+			if !ok {
+				return nil
+			}
+			binaryLogs, err := mysql.GetBinaryLogs(this.inspector.db)
+			if err != nil {
+				this.migrationContext.SetBinlogBacklog(base.BinlogBacklogUnknown)
+				return this.migrationContext.Log.Errore(err)
+			}
+			bytesBehind, ok := applied.BytesBehind(head, binaryLogs)
+			if !ok {
+				bytesBehind = base.BinlogBacklogUnknown
+			}
+			this.migrationContext.SetBinlogBacklog(bytesBehind)
+		}
+		return nil
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if atomic.LoadInt64(&this.finishedMigrating) > 0 {
+			return
+		}
+		collectFunc()
+	}
+}
+
 // initiateThrottlerCollection initiates the various processes that collect measurements
 // that may affect throttling. There are several components, all running independently,
 // that collect such metrics.
@@ -448,6 +498,7 @@ func (this *Throttler) initiateThrottlerCollection(firstThrottlingCollected chan
 	go this.collectReplicationLag(firstThrottlingCollected)
 	go this.collectControlReplicasLag()
 	go this.collectThrottleHTTPStatus(firstThrottlingCollected)
+	go this.collectBinlogBacklog()
 
 	go func() {
 		this.collectGeneralThrottleMetrics()
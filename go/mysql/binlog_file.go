@@ -99,6 +99,48 @@ func (this *FileBinlogCoordinates) SmallerThanOrEquals(other BinlogCoordinates)
 	return this.LogFile == coord.LogFile && this.LogPos == coord.LogPos // No Type comparison
 }
 
+// BytesBehind returns the number of binary log bytes from these coordinates up to given head coordinates,
+// based on the file sizes listed by SHOW BINARY LOGS. It returns false when the binary logs in between are
+// not all listed, e.g. when they are purged.
+func (this *FileBinlogCoordinates) BytesBehind(head *FileBinlogCoordinates, binaryLogs []BinaryLog) (bytesBehind int64, ok bool) {
+	if !this.SmallerThan(head) {
+		return 0, true
+	}
+	if this.LogFile == head.LogFile {
+		return head.LogPos - this.LogPos, true
+	}
+	fileSizes := make(map[string]int64, len(binaryLogs))
+	for _, binaryLog := range binaryLogs {
+		fileSizes[binaryLog.Name] = binaryLog.Size
+	}
+	size, ok := fileSizes[this.LogFile]
+	if !ok {
+		return 0, false
+	}
+	bytesBehind = size - this.LogPos
+	filesInBetween := 0
+	for _, binaryLog := range binaryLogs {
+		coords := NewFileBinlogCoordinates(binaryLog.Name, 0)
+		if this.FileNumberDistance(coords) > 0 && coords.FileNumberDistance(head) > 0 {
+			bytesBehind += binaryLog.Size
+			filesInBetween++
+		}
+	}
+	if filesInBetween != this.FileNumberDistance(head)-1 {
+		return 0, false
+	}
+	return bytesBehind + head.LogPos, true
+}
+
+// IsPurged returns true when these coordinates' file precedes the binary logs listed by SHOW BINARY LOGS,
+// i.e. when it has been purged.
+func (this *FileBinlogCoordinates) IsPurged(binaryLogs []BinaryLog) bool {
+	if len(binaryLogs) == 0 {
+		return false
+	}
+	return this.FileNumberDistance(NewFileBinlogCoordinates(binaryLogs[0].Name, 0)) > 0
+}
+
 // FileNumberDistance returns the numeric distance between this coordinate's file number and the other's.
 // Effectively it means "how many rotates/FLUSHes would make these coordinates's file reach the other's"
 func (this *FileBinlogCoordinates) FileNumberDistance(other *FileBinlogCoordinates) int {
@@ -150,3 +150,84 @@ func TestBinlogCoordinates_SameLogFileDifferentPosition(t *testing.T) {
 	require.False(t, c2.SmallerThan(&c1))
 	require.False(t, c1.SmallerThan(&c1))
 }
+
+func TestBinlogCoordinates_BytesBehind(t *testing.T) {
+	binaryLogs := []BinaryLog{
+		{Name: "mysql-bin.000016", Size: 1000},
+		{Name: "mysql-bin.000017", Size: 2000},
+		{Name: "mysql-bin.000018", Size: 3000},
+		{Name: "mysql-bin.000019", Size: 500},
+	}
+	head := &FileBinlogCoordinates{LogFile: "mysql-bin.000019", LogPos: 500}
+	{
+		coords := &FileBinlogCoordinates{LogFile: "mysql-bin.000019", LogPos: 200}
+		bytesBehind, ok := coords.BytesBehind(head, binaryLogs)
+		require.True(t, ok)
+		require.Equal(t, int64(300), bytesBehind)
+	}
+	{
+		coords := &FileBinlogCoordinates{LogFile: "mysql-bin.000017", LogPos: 1500}
+		bytesBehind, ok := coords.BytesBehind(head, binaryLogs)
+		require.True(t, ok)
+		require.Equal(t, int64(500+3000+500), bytesBehind)
+	}
+	{
+		bytesBehind, ok := head.BytesBehind(head, binaryLogs)
+		require.True(t, ok)
+		require.Equal(t, int64(0), bytesBehind)
+	}
+	{
+		coords := &FileBinlogCoordinates{LogFile: "mysql-bin.000015", LogPos: 1500}
+		_, ok := coords.BytesBehind(head, binaryLogs)
+		require.False(t, ok)
+	}
+	{
+		coords := &FileBinlogCoordinates{LogFile: "mysql-bin.000016", LogPos: 100}
+		_, ok := coords.BytesBehind(head, []BinaryLog{binaryLogs[0], binaryLogs[2], binaryLogs[3]})
+		require.False(t, ok)
+	}
+}
+
+func TestBinlogCoordinates_IsPurged(t *testing.T) {
+	binaryLogs := []BinaryLog{
+		{Name: "mysql-bin.000016", Size: 1000},
+		{Name: "mysql-bin.000017", Size: 2000},
+	}
+	require.True(t, NewFileBinlogCoordinates("mysql-bin.000015", 1500).IsPurged(binaryLogs))
+	require.False(t, NewFileBinlogCoordinates("mysql-bin.000016", 4).IsPurged(binaryLogs))
+	require.False(t, NewFileBinlogCoordinates("mysql-bin.000017", 1500).IsPurged(binaryLogs))
+	require.False(t, NewFileBinlogCoordinates("mysql-bin.000015", 1500).IsPurged(nil))
+}
+
+func TestGTIDBinlogCoordinates_IsPurged(t *testing.T) {
+	gtidPurged, err := NewGTIDBinlogCoordinates("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-50")
+	require.NoError(t, err)
+	{
+		coords, err := NewGTIDBinlogCoordinates("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-90,7F80FA47-FF33-71A1-AE01-B80CC7823548:1-10")
+		require.NoError(t, err)
+		require.False(t, coords.IsPurged(gtidPurged))
+	}
+	{
+		coords, err := NewGTIDBinlogCoordinates("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-40")
+		require.NoError(t, err)
+		require.True(t, coords.IsPurged(gtidPurged))
+	}
+}
+
+func TestGTIDBinlogCoordinates_TransactionsBehind(t *testing.T) {
+	head, err := NewGTIDBinlogCoordinates("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-100,7F80FA47-FF33-71A1-AE01-B80CC7823548:1-10")
+	require.NoError(t, err)
+	{
+		coords, err := NewGTIDBinlogCoordinates("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-90,7F80FA47-FF33-71A1-AE01-B80CC7823548:1-10")
+		require.NoError(t, err)
+		require.Equal(t, int64(10), coords.TransactionsBehind(head))
+	}
+	{
+		coords, err := NewGTIDBinlogCoordinates("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-100")
+		require.NoError(t, err)
+		require.Equal(t, int64(10), coords.TransactionsBehind(head))
+	}
+	{
+		require.Equal(t, int64(0), head.TransactionsBehind(head))
+	}
+}
@@ -85,3 +85,26 @@ func (this *GTIDBinlogCoordinates) Clone() BinlogCoordinates {
 	}
 	return out
 }
+
+// TransactionsBehind returns the number of transactions in given head coordinates which are not in these coordinates.
+func (this *GTIDBinlogCoordinates) TransactionsBehind(head *GTIDBinlogCoordinates) (transactionsBehind int64) {
+	if head.IsEmpty() {
+		return 0
+	}
+	missing := head.GTIDSet.Clone().(*gomysql.MysqlGTIDSet)
+	if !this.IsEmpty() {
+		missing.Minus(*this.GTIDSet)
+	}
+	for _, uuidSet := range missing.Sets {
+		for _, interval := range uuidSet.Intervals {
+			transactionsBehind += interval.Stop - interval.Start
+		}
+	}
+	return transactionsBehind
+}
+
+// IsPurged returns true when given purged GTID set (gtid_purged) holds transactions which are not in
+// these coordinates, i.e. when transactions following these coordinates have been purged.
+func (this *GTIDBinlogCoordinates) IsPurged(gtidPurged *GTIDBinlogCoordinates) bool {
+	return this.TransactionsBehind(gtidPurged) > 0
+}
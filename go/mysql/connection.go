@@ -15,7 +15,6 @@ import (
 	"strings"
 
 	gomysqlclient "github.com/go-mysql-org/go-mysql/client"
-	"github.com/go-sql-driver/mysql"
 )
 
 const (
@@ -36,6 +35,8 @@ type ConnectionConfig struct {
 	Network string
 	// Dialer is used by go-mysql binlog connections. When nil, go-mysql uses net.Dialer.
 	Dialer gomysqlclient.Dialer
+	// CredentialsProvider, when not nil, is consulted for User/Password on each new connection
+	CredentialsProvider CredentialsProvider
 
 	// use migrationContext.Uuid if useSSL
 	TLSKey string
@@ -61,6 +62,7 @@ func (this *ConnectionConfig) DuplicateCredentials(key InstanceKey) *ConnectionC
 		Charset:              this.Charset,
 		Network:              this.Network,
 		Dialer:               this.Dialer,
+		CredentialsProvider:  this.CredentialsProvider,
 		TLSKey:               this.TLSKey,
 	}
 
@@ -81,6 +83,21 @@ func (this *ConnectionConfig) Duplicate() *ConnectionConfig {
 	return this.DuplicateCredentials(this.Key)
 }
 
+// GetCredentials returns the credentials to use for a new connection: those of the credentials provider
+// when there is one, or else the static User/Password
+func (this *ConnectionConfig) GetCredentials() (user, password string, err error) {
+	if this.CredentialsProvider == nil {
+		return this.User, this.Password, nil
+	}
+	if user, password, err = this.CredentialsProvider.GetCredentials(); err != nil {
+		return "", "", err
+	}
+	if user == "" {
+		user = this.User
+	}
+	return user, password, nil
+}
+
 func (this *ConnectionConfig) String() string {
 	return fmt.Sprintf("%s, user=%s, usingTLS=%t", this.Key.DisplayString(), this.User, this.tlsConfig != nil)
 }
@@ -125,20 +142,10 @@ func (this *ConnectionConfig) UseTLS(tlsKey string, caCertificatePath, clientCer
 	}
 	this.TLSKey = tlsKey
 
-	return this.RegisterTLSConfig()
-}
-
-func (this *ConnectionConfig) RegisterTLSConfig() error {
-	if this.tlsConfig == nil {
-		return nil
-	}
 	if this.tlsConfig.ServerName == "" {
 		return errors.New("tlsConfig.ServerName cannot be empty")
 	}
-
-	tlsOption := GetDBTLSConfigKey(this.TLSKey, this.tlsConfig.ServerName)
-
-	return mysql.RegisterTLSConfig(tlsOption, this.tlsConfig)
+	return nil
 }
 
 func (this *ConnectionConfig) TLSConfig() *tls.Config {
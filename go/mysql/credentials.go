@@ -0,0 +1,149 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const credentialsCommandTimeout = 30 * time.Second
+
+// CredentialsProvider supplies the user and password used when opening a new MySQL connection.
+// An empty user means the connection config's user applies.
+type CredentialsProvider interface {
+	GetCredentials() (user, password string, err error)
+}
+
+// StaticCredentialsProvider always supplies the same credentials
+type StaticCredentialsProvider struct {
+	User     string
+	Password string
+}
+
+func NewStaticCredentialsProvider(user, password string) *StaticCredentialsProvider {
+	return &StaticCredentialsProvider{User: user, Password: password}
+}
+
+func (this *StaticCredentialsProvider) GetCredentials() (user, password string, err error) {
+	return this.User, this.Password, nil
+}
+
+// parseCredentials reads credentials off a file's content or a command's output. Either the content is
+// the password as a single line, or it holds `user=...` and `password=...` lines.
+func parseCredentials(content string) (user, password string, err error) {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if len(lines) == 1 && !strings.HasPrefix(lines[0], "user=") && !strings.HasPrefix(lines[0], "password=") {
+		return "", strings.TrimSpace(lines[0]), nil
+	}
+	hasPassword := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens := strings.SplitN(line, "=", 2)
+		if len(tokens) != 2 {
+			return "", "", fmt.Errorf("Unable to parse credentials: expected user=... and password=... lines")
+		}
+		switch strings.TrimSpace(tokens[0]) {
+		case "user":
+			user = strings.TrimSpace(tokens[1])
+		case "password":
+			password = strings.TrimSpace(tokens[1])
+			hasPassword = true
+		default:
+			return "", "", fmt.Errorf("Unable to parse credentials: unknown key %q", strings.TrimSpace(tokens[0]))
+		}
+	}
+	if !hasPassword {
+		return "", "", fmt.Errorf("Unable to parse credentials: no password found")
+	}
+	return user, password, nil
+}
+
+// FileCredentialsProvider reads credentials from a file, such as one maintained by a secrets agent.
+// The file is re-read whenever it is modified.
+type FileCredentialsProvider struct {
+	path     string
+	modTime  time.Time
+	size     int64
+	user     string
+	password string
+	mutex    sync.Mutex
+}
+
+func NewFileCredentialsProvider(path string) *FileCredentialsProvider {
+	return &FileCredentialsProvider{path: path}
+}
+
+func (this *FileCredentialsProvider) GetCredentials() (user, password string, err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	fileInfo, err := os.Stat(this.path)
+	if err != nil {
+		return "", "", err
+	}
+	if fileInfo.ModTime().Equal(this.modTime) && fileInfo.Size() == this.size {
+		return this.user, this.password, nil
+	}
+	content, err := os.ReadFile(this.path)
+	if err != nil {
+		return "", "", err
+	}
+	if this.user, this.password, err = parseCredentials(string(content)); err != nil {
+		return "", "", fmt.Errorf("%s: %w", this.path, err)
+	}
+	this.modTime = fileInfo.ModTime()
+	this.size = fileInfo.Size()
+	return this.user, this.password, nil
+}
+
+// ExecCredentialsProvider runs a command that prints credentials, such as a Vault or cloud IAM client.
+// The credentials are cached for given duration, after which the command runs again.
+type ExecCredentialsProvider struct {
+	command       string
+	cacheDuration time.Duration
+	fetchedAt     time.Time
+	user          string
+	password      string
+	mutex         sync.Mutex
+}
+
+func NewExecCredentialsProvider(command string, cacheDuration time.Duration) *ExecCredentialsProvider {
+	return &ExecCredentialsProvider{command: command, cacheDuration: cacheDuration}
+}
+
+func (this *ExecCredentialsProvider) GetCredentials() (user, password string, err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	if !this.fetchedAt.IsZero() && time.Since(this.fetchedAt) < this.cacheDuration {
+		return this.user, this.password, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), credentialsCommandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", this.command)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("Credentials command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if this.user, this.password, err = parseCredentials(string(output)); err != nil {
+		return "", "", fmt.Errorf("Credentials command: %w", err)
+	}
+	this.fetchedAt = time.Now()
+	return this.user, this.password, nil
+}
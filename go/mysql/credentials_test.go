@@ -0,0 +1,144 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCredentialsProvider struct {
+	calls int
+	err   error
+}
+
+func (this *testCredentialsProvider) GetCredentials() (user, password string, err error) {
+	this.calls++
+	return "", fmt.Sprintf("password-%d", this.calls), this.err
+}
+
+func TestParseCredentials(t *testing.T) {
+	{
+		user, password, err := parseCredentials("s3cr3t\n")
+		require.NoError(t, err)
+		require.Equal(t, "", user)
+		require.Equal(t, "s3cr3t", password)
+	}
+	{
+		user, password, err := parseCredentials("# rotated hourly\nuser=gromit\npassword=pen=guin\n")
+		require.NoError(t, err)
+		require.Equal(t, "gromit", user)
+		require.Equal(t, "pen=guin", password)
+	}
+	{
+		_, _, err := parseCredentials("user=gromit\n")
+		require.Error(t, err)
+	}
+	{
+		_, _, err := parseCredentials("user=gromit\npasswd=penguin\n")
+		require.Error(t, err)
+	}
+}
+
+func TestFileCredentialsProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(path, []byte("user=gromit\npassword=first\n"), 0o600))
+
+	provider := NewFileCredentialsProvider(path)
+	user, password, err := provider.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "gromit", user)
+	require.Equal(t, "first", password)
+
+	// rotated
+	require.NoError(t, os.WriteFile(path, []byte("user=gromit\npassword=second\n"), 0o600))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+	_, password, err = provider.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "second", password)
+
+	require.NoError(t, os.Remove(path))
+	_, _, err = provider.GetCredentials()
+	require.Error(t, err)
+}
+
+func TestExecCredentialsProvider(t *testing.T) {
+	counterFile := filepath.Join(t.TempDir(), "counter")
+	command := fmt.Sprintf("echo x >> %s; printf 'password=%%s' $(wc -l < %s)", counterFile, counterFile)
+	{
+		provider := NewExecCredentialsProvider(command, time.Hour)
+		_, password, err := provider.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "1", password)
+		// cached
+		_, password, err = provider.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "1", password)
+	}
+	{
+		provider := NewExecCredentialsProvider(command, 0)
+		_, password, err := provider.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "2", password)
+		_, password, err = provider.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "3", password)
+	}
+	{
+		provider := NewExecCredentialsProvider("echo denied >&2; exit 1", 0)
+		_, _, err := provider.GetCredentials()
+		require.ErrorContains(t, err, "denied")
+	}
+}
+
+func TestConnectionConfigGetCredentials(t *testing.T) {
+	c := NewConnectionConfig()
+	c.User = "gromit"
+	c.Password = "penguin"
+	{
+		user, password, err := c.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "gromit", user)
+		require.Equal(t, "penguin", password)
+	}
+	provider := &testCredentialsProvider{}
+	c.CredentialsProvider = provider
+	{
+		user, password, err := c.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "gromit", user)
+		require.Equal(t, "password-1", password)
+	}
+	dup := c.DuplicateCredentials(InstanceKey{Hostname: "otherhost", Port: 3306})
+	{
+		_, password, err := dup.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "password-2", password)
+	}
+}
+
+func TestOpenDBConsultsCredentialsProvider(t *testing.T) {
+	providerErr := errors.New("credentials unavailable")
+	provider := &testCredentialsProvider{err: providerErr}
+
+	c := NewConnectionConfig()
+	c.Key = InstanceKey{Hostname: "127.0.0.1", Port: 3306}
+	c.User = "gromit"
+	c.CredentialsProvider = provider
+	db, err := openDB(c.GetDBUri("test"), c)
+	require.NoError(t, err)
+	defer db.Close()
+
+	require.ErrorIs(t, db.Ping(), providerErr)
+	require.ErrorIs(t, db.Ping(), providerErr)
+	require.Equal(t, 2, provider.calls)
+}
@@ -6,6 +6,7 @@
 package mysql
 
 import (
+	"context"
 	gosql "database/sql"
 	"fmt"
 	"strings"
@@ -13,8 +14,8 @@ import (
 	"time"
 
 	"github.com/github/gh-ost/go/sql"
+	gomysqldriver "github.com/go-sql-driver/mysql"
 
-	"github.com/openark/golib/log"
 	"github.com/openark/golib/sqlutils"
 )
 
@@ -44,32 +45,151 @@ func (this *ReplicationLagResult) HasLag() bool {
 	return this.Lag > 0
 }
 
-// knownDBs is a DB cache by uri
-var knownDBs map[string]*gosql.DB = make(map[string]*gosql.DB)
-var knownDBsMutex = &sync.Mutex{}
+// DBCache is a cache of connection pools by uri. Each migration has its own cache, such that
+// concurrent migrations in one process do not share connection pools.
+type DBCache struct {
+	dbs   map[string]*gosql.DB
+	mutex sync.Mutex
+}
 
-func GetDB(migrationUuid string, mysql_uri string) (db *gosql.DB, exists bool, err error) {
-	cacheKey := migrationUuid + ":" + mysql_uri
+func NewDBCache() *DBCache {
+	return &DBCache{dbs: make(map[string]*gosql.DB)}
+}
 
-	knownDBsMutex.Lock()
-	defer knownDBsMutex.Unlock()
+// GetDB returns a connection pool to given uri, which is one of connectionConfig's GetDBUri(), possibly
+// with additional params. The connection config's credentials provider, if any, is consulted for user &
+// password on each new connection, rather than using those in the uri
+func (this *DBCache) GetDB(mysql_uri string, connectionConfig *ConnectionConfig) (db *gosql.DB, exists bool, err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
 
-	if db, exists = knownDBs[cacheKey]; !exists {
-		db, err = gosql.Open("mysql", mysql_uri)
+	if db, exists = this.dbs[mysql_uri]; !exists {
+		db, err = openDB(mysql_uri, connectionConfig)
 		if err != nil {
 			return nil, false, err
 		}
 		db.SetMaxOpenConns(MaxDBPoolConnections)
 		db.SetMaxIdleConns(MaxDBPoolConnections)
-		knownDBs[cacheKey] = db
+		this.dbs[mysql_uri] = db
 	}
 	return db, exists, nil
 }
 
-// GetReplicationLagFromSlaveStatus returns replication lag for a given db; via SHOW SLAVE STATUS
-func GetReplicationLagFromSlaveStatus(dbVersion string, informationSchemaDb *gosql.DB) (replicationLag time.Duration, err error) {
-	showReplicaStatusQuery := fmt.Sprintf("show %s", ReplicaTermFor(dbVersion, `slave status`))
-	err = sqlutils.QueryRowsMap(informationSchemaDb, showReplicaStatusQuery, func(m sqlutils.RowMap) error {
+// Close closes all cached connection pools
+func (this *DBCache) Close() (err error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+
+	for uri, db := range this.dbs {
+		if closeErr := db.Close(); closeErr != nil {
+			err = closeErr
+		}
+		delete(this.dbs, uri)
+	}
+	return err
+}
+
+// openDB opens a connection pool to given uri, which is one of connectionConfig's GetDBUri().
+// The connection config's TLS config is handed to the driver directly rather than registered
+// process wide. Given a credentials provider, user & password are obtained from the provider
+// whenever the pool opens a new connection.
+func openDB(mysql_uri string, connectionConfig *ConnectionConfig) (*gosql.DB, error) {
+	tlsConfig := connectionConfig.TLSConfig()
+	if tlsConfig != nil {
+		// the uri names the TLS config, which is not registered with the driver
+		mysql_uri = withoutTLSConfigName(mysql_uri)
+	}
+	config, err := gomysqldriver.ParseDSN(mysql_uri)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		config.TLS = tlsConfig.Clone()
+	}
+	if credentialsProvider := connectionConfig.CredentialsProvider; credentialsProvider != nil {
+		defaultUser := config.User
+		err = config.Apply(gomysqldriver.BeforeConnect(func(ctx context.Context, config *gomysqldriver.Config) error {
+			user, password, err := credentialsProvider.GetCredentials()
+			if err != nil {
+				return err
+			}
+			if user == "" {
+				user = defaultUser
+			}
+			config.User, config.Passwd = user, password
+			return nil
+		}))
+		if err != nil {
+			return nil, err
+		}
+	}
+	connector, err := gomysqldriver.NewConnector(config)
+	if err != nil {
+		return nil, err
+	}
+	return gosql.OpenDB(connector), nil
+}
+
+// withoutTLSConfigName replaces a uri's named TLS config with tls=false, for the TLS config to be
+// set on the parsed driver config
+func withoutTLSConfigName(mysql_uri string) string {
+	tokens := strings.SplitN(mysql_uri, "?", 2)
+	if len(tokens) != 2 {
+		return mysql_uri
+	}
+	params := strings.Split(tokens[1], "&")
+	for i, param := range params {
+		if strings.HasPrefix(param, "tls=") {
+			params[i] = "tls=false"
+		}
+	}
+	return tokens[0] + "?" + strings.Join(params, "&")
+}
+
+// ReplicationChannelClause returns the FOR CHANNEL clause of replication statements. With no channel
+// given, statements apply to the default channel, or to all channels of a multi-source replica.
+func ReplicationChannelClause(channel string) string {
+	if channel == "" {
+		return ""
+	}
+	return fmt.Sprintf(" for channel '%s'", channel)
+}
+
+// ShowReplicaStatusQuery returns the SHOW SLAVE STATUS query, for given channel or for all channels
+func ShowReplicaStatusQuery(dbVersion string, channel string) string {
+	return fmt.Sprintf("show /* gh-ost */ %s%s", ReplicaTermFor(dbVersion, `slave status`), ReplicationChannelClause(channel))
+}
+
+// ReplicationChannel is a replication channel of a replica. A multi-source replica has a channel
+// per source.
+type ReplicationChannel struct {
+	Name      string
+	MasterKey InstanceKey
+}
+
+// GetReplicationChannels lists the replication channels of given server; via SHOW SLAVE STATUS.
+// A server that does not replicate has none.
+func GetReplicationChannels(dbVersion string, db *gosql.DB) (channels []ReplicationChannel, err error) {
+	err = sqlutils.QueryRowsMap(db, ShowReplicaStatusQuery(dbVersion, ""), func(m sqlutils.RowMap) error {
+		if m.GetString(ReplicaTermFor(dbVersion, "Master_Log_File")) == "" {
+			return nil
+		}
+		channels = append(channels, ReplicationChannel{
+			Name: m.GetString("Channel_Name"),
+			MasterKey: InstanceKey{
+				Hostname: m.GetString(ReplicaTermFor(dbVersion, "Master_Host")),
+				Port:     m.GetInt(ReplicaTermFor(dbVersion, "Master_Port")),
+			},
+		})
+		return nil
+	})
+	return channels, err
+}
+
+// GetReplicationLagFromSlaveStatus returns replication lag for a given db; via SHOW SLAVE STATUS.
+// With no channel given, the lag of a multi-source replica is that of its most lagging channel.
+func GetReplicationLagFromSlaveStatus(dbVersion string, informationSchemaDb *gosql.DB, channel string) (replicationLag time.Duration, err error) {
+	err = sqlutils.QueryRowsMap(informationSchemaDb, ShowReplicaStatusQuery(dbVersion, channel), func(m sqlutils.RowMap) error {
 		ioRunningTerm := ReplicaTermFor(dbVersion, "Slave_IO_Running")
 		sqlRunningTerm := ReplicaTermFor(dbVersion, "Slave_SQL_Running")
 		slaveIORunning := m.GetString(ioRunningTerm)
@@ -78,24 +198,29 @@ func GetReplicationLagFromSlaveStatus(dbVersion string, informationSchemaDb *gos
 		if !secondsBehindMaster.Valid {
 			return fmt.Errorf("replication not running; %s=%+v, %s=%+v", ioRunningTerm, slaveIORunning, sqlRunningTerm, slaveSQLRunning)
 		}
-		replicationLag = time.Duration(secondsBehindMaster.Int64) * time.Second
+		if channelLag := time.Duration(secondsBehindMaster.Int64) * time.Second; channelLag > replicationLag {
+			replicationLag = channelLag
+		}
 		return nil
 	})
 
 	return replicationLag, err
 }
 
-func GetMasterKeyFromSlaveStatus(dbVersion string, connectionConfig *ConnectionConfig) (masterKey *InstanceKey, err error) {
+// GetMasterKeyFromSlaveStatus returns the master of given server on given replication channel,
+// or nil if the server does not replicate. With no channel given, the server must have at most
+// one channel.
+func GetMasterKeyFromSlaveStatus(dbVersion string, connectionConfig *ConnectionConfig, channel string) (masterKey *InstanceKey, err error) {
 	currentUri := connectionConfig.GetDBUri("information_schema")
 	// This function is only called once, okay to not have a cached connection pool
-	db, err := gosql.Open("mysql", currentUri)
+	db, err := openDB(currentUri, connectionConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
-	showReplicaStatusQuery := fmt.Sprintf("show %s", ReplicaTermFor(dbVersion, `slave status`))
-	err = sqlutils.QueryRowsMap(db, showReplicaStatusQuery, func(rowMap sqlutils.RowMap) error {
+	var channelNames []string
+	err = sqlutils.QueryRowsMap(db, ShowReplicaStatusQuery(dbVersion, channel), func(rowMap sqlutils.RowMap) error {
 		// We wish to recognize the case where the topology's master actually has replication configuration.
 		// This can happen when a DBA issues a `RESET SLAVE` instead of `RESET SLAVE ALL`.
 
@@ -103,6 +228,13 @@ func GetMasterKeyFromSlaveStatus(dbVersion string, connectionConfig *ConnectionC
 		if rowMap.GetString(ReplicaTermFor(dbVersion, "Master_Log_File")) == "" {
 			return nil
 		}
+		channelNames = append(channelNames, rowMap.GetString("Channel_Name"))
+		if len(channelNames) > 1 {
+			return fmt.Errorf("%+v is a multi-source replica, with replication channels %s. Please specify --replication-channel",
+				connectionConfig.Key,
+				strings.Join(channelNames, ", "),
+			)
+		}
 
 		ioRunningTerm := ReplicaTermFor(dbVersion, "Slave_IO_Running")
 		sqlRunningTerm := ReplicaTermFor(dbVersion, "Slave_SQL_Running")
@@ -129,10 +261,10 @@ func GetMasterKeyFromSlaveStatus(dbVersion string, connectionConfig *ConnectionC
 	return masterKey, err
 }
 
-func GetMasterConnectionConfigSafe(dbVersion string, connectionConfig *ConnectionConfig, visitedKeys *InstanceKeyMap, allowMasterMaster bool) (masterConfig *ConnectionConfig, err error) {
-	log.Debugf("Looking for %s on %+v", ReplicaTermFor(dbVersion, "master"), connectionConfig.Key)
-
-	masterKey, err := GetMasterKeyFromSlaveStatus(dbVersion, connectionConfig)
+// GetMasterConnectionConfigSafe recursively follows replication up to the topology's master. The
+// channel, if given, is followed from the first server; servers further up must have a single channel.
+func GetMasterConnectionConfigSafe(dbVersion string, connectionConfig *ConnectionConfig, visitedKeys *InstanceKeyMap, allowMasterMaster bool, channel string) (masterConfig *ConnectionConfig, err error) {
+	masterKey, err := GetMasterKeyFromSlaveStatus(dbVersion, connectionConfig, channel)
 	if err != nil {
 		return nil, err
 	}
@@ -144,11 +276,7 @@ func GetMasterConnectionConfigSafe(dbVersion string, connectionConfig *Connectio
 	}
 
 	masterConfig = connectionConfig.DuplicateCredentials(*masterKey)
-	if err := masterConfig.RegisterTLSConfig(); err != nil {
-		return nil, err
-	}
 
-	log.Debugf("%s of %+v is %+v", ReplicaTermFor(dbVersion, "master"), connectionConfig.Key, masterConfig.Key)
 	if visitedKeys.HasKey(masterConfig.Key) {
 		if allowMasterMaster {
 			return connectionConfig, nil
@@ -156,12 +284,11 @@ func GetMasterConnectionConfigSafe(dbVersion string, connectionConfig *Connectio
 		return nil, fmt.Errorf("There seems to be a master-master setup at %+v. This is unsupported. Bailing out", masterConfig.Key)
 	}
 	visitedKeys.AddKey(masterConfig.Key)
-	return GetMasterConnectionConfigSafe(dbVersion, masterConfig, visitedKeys, allowMasterMaster)
+	return GetMasterConnectionConfigSafe(dbVersion, masterConfig, visitedKeys, allowMasterMaster, "")
 }
 
-func GetReplicationBinlogCoordinates(dbVersion string, db *gosql.DB, gtid bool) (readBinlogCoordinates, executeBinlogCoordinates BinlogCoordinates, err error) {
-	showReplicaStatusQuery := fmt.Sprintf("show %s", ReplicaTermFor(dbVersion, `slave status`))
-	err = sqlutils.QueryRowsMap(db, showReplicaStatusQuery, func(m sqlutils.RowMap) error {
+func GetReplicationBinlogCoordinates(dbVersion string, db *gosql.DB, gtid bool, channel string) (readBinlogCoordinates, executeBinlogCoordinates BinlogCoordinates, err error) {
+	err = sqlutils.QueryRowsMap(db, ShowReplicaStatusQuery(dbVersion, channel), func(m sqlutils.RowMap) error {
 		if gtid {
 			executeBinlogCoordinates, err = NewGTIDBinlogCoordinates(m.GetString("Executed_Gtid_Set"))
 			if err != nil {
@@ -202,6 +329,52 @@ func GetSelfBinlogCoordinates(dbVersion string, db *gosql.DB, gtid bool) (selfBi
 	return selfBinlogCoordinates, err
 }
 
+// BinaryLog describes a binary log file, as listed by SHOW BINARY LOGS
+type BinaryLog struct {
+	Name string
+	Size int64
+}
+
+// GetBinaryLogs lists the binary logs on given server
+func GetBinaryLogs(db *gosql.DB) (binaryLogs []BinaryLog, err error) {
+	err = sqlutils.QueryRowsMap(db, `show binary logs`, func(m sqlutils.RowMap) error {
+		binaryLogs = append(binaryLogs, BinaryLog{
+			Name: m.GetString("Log_name"),
+			Size: m.GetInt64("File_size"),
+		})
+		return nil
+	})
+	return binaryLogs, err
+}
+
+// GetBinlogRetentionSeconds returns the period after which the server purges its binary logs automatically,
+// in seconds: binlog_expire_logs_seconds, or else expire_logs_days where supported. 0 means no automatic purge.
+func GetBinlogRetentionSeconds(db *gosql.DB) (int64, error) {
+	var expireLogsSeconds int64
+	secondsErr := db.QueryRow(`select /* gh-ost */ @@global.binlog_expire_logs_seconds`).Scan(&expireLogsSeconds)
+	if secondsErr == nil && expireLogsSeconds > 0 {
+		return expireLogsSeconds, nil
+	}
+	var expireLogsDays int64
+	if err := db.QueryRow(`select /* gh-ost */ @@global.expire_logs_days`).Scan(&expireLogsDays); err != nil {
+		if secondsErr == nil {
+			// expire_logs_days is removed as of 8.4
+			return 0, nil
+		}
+		return 0, err
+	}
+	return expireLogsDays * 24 * 60 * 60, nil
+}
+
+// GetPurgedGTIDSet reads gtid_purged on given DB
+func GetPurgedGTIDSet(db *gosql.DB) (*GTIDBinlogCoordinates, error) {
+	var gtidPurged string
+	if err := db.QueryRow(`select /* gh-ost */ @@global.gtid_purged`).Scan(&gtidPurged); err != nil {
+		return nil, err
+	}
+	return NewGTIDBinlogCoordinates(gtidPurged)
+}
+
 // GetInstanceKey reads hostname and port on given DB
 func GetInstanceKey(db *gosql.DB) (instanceKey *InstanceKey, err error) {
 	instanceKey = &InstanceKey{}
@@ -223,7 +396,6 @@ func GetTableColumns(db *gosql.DB, databaseName, tableName string) (*sql.ColumnL
 		columnName := rowMap.GetString("Field")
 		columnNames = append(columnNames, columnName)
 		if strings.Contains(rowMap.GetString("Extra"), " GENERATED") {
-			log.Debugf("%s is a generated column", columnName)
 			virtualColumnNames = append(virtualColumnNames, columnName)
 		}
 		return nil
@@ -232,7 +404,7 @@ func GetTableColumns(db *gosql.DB, databaseName, tableName string) (*sql.ColumnL
 		return nil, nil, err
 	}
 	if len(columnNames) == 0 {
-		return nil, nil, log.Errorf("Found 0 columns on %s.%s. Bailing out",
+		return nil, nil, fmt.Errorf("Found 0 columns on %s.%s. Bailing out",
 			sql.EscapeName(databaseName),
 			sql.EscapeName(tableName),
 		)
@@ -247,10 +419,14 @@ func Kill(db *gosql.DB, connectionID string) error {
 }
 
 // GetTriggers reads trigger list from given table
-func GetTriggers(db *gosql.DB, databaseName, tableName string) (triggers []Trigger, err error) {
-	query := `select trigger_name as name, event_manipulation as event, action_statement as statement, action_timing as timing
+func GetTriggers(db *gosql.DB, databaseName, tableName string, caseSensitiveTableNames bool) (triggers []Trigger, err error) {
+	tableNameArg := "?"
+	if caseSensitiveTableNames {
+		tableNameArg = "cast(? as binary)"
+	}
+	query := fmt.Sprintf(`select trigger_name as name, event_manipulation as event, action_statement as statement, action_timing as timing
 	from information_schema.triggers
-	where trigger_schema = ? and event_object_table = ?`
+	where trigger_schema = ? and event_object_table = %s`, tableNameArg)
 
 	err = sqlutils.QueryRowsMap(db, query, func(rowMap sqlutils.RowMap) error {
 		triggers = append(triggers, Trigger{
@@ -0,0 +1,68 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package mysql
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithoutTLSConfigName(t *testing.T) {
+	require.Equal(t,
+		"gromit:penguin@tcp(myhost:3306)/test?autocommit=true&tls=false&timeout=1s",
+		withoutTLSConfigName("gromit:penguin@tcp(myhost:3306)/test?autocommit=true&tls=uuidv4-myhost&timeout=1s"),
+	)
+	require.Equal(t, "gromit:penguin@tcp(myhost:3306)/test", withoutTLSConfigName("gromit:penguin@tcp(myhost:3306)/test"))
+}
+
+func TestOpenDBWithUnregisteredTLSConfig(t *testing.T) {
+	c := NewConnectionConfig()
+	c.Key = InstanceKey{Hostname: "myhost", Port: 3306}
+	c.tlsConfig = &tls.Config{ServerName: c.Key.Hostname}
+	c.TLSKey = "uuidv4"
+
+	db, err := openDB(c.GetDBUri("test"), c)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+}
+
+func TestDBCache(t *testing.T) {
+	c := NewConnectionConfig()
+	c.Key = InstanceKey{Hostname: "myhost", Port: 3306}
+	uri := c.GetDBUri("test")
+
+	cache := NewDBCache()
+	db, exists, err := cache.GetDB(uri, c)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	cachedDB, exists, err := cache.GetDB(uri, c)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Same(t, db, cachedDB)
+
+	otherCache := NewDBCache()
+	otherDB, exists, err := otherCache.GetDB(uri, c)
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.NotSame(t, db, otherDB)
+
+	require.NoError(t, cache.Close())
+	require.NoError(t, otherCache.Close())
+	_, exists, err = cache.GetDB(uri, c)
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.NoError(t, cache.Close())
+}
+
+func TestShowReplicaStatusQuery(t *testing.T) {
+	require.Equal(t, "", ReplicationChannelClause(""))
+	require.Equal(t, " for channel 'source1'", ReplicationChannelClause("source1"))
+	require.Equal(t, "show /* gh-ost */ slave status", ShowReplicaStatusQuery("8.0.36", ""))
+	require.Equal(t, "show /* gh-ost */ replica status for channel 'source1'", ShowReplicaStatusQuery("8.4.0", "source1"))
+}
@@ -130,11 +130,14 @@ func NewCheckpointQueryBuilder(databaseName, tableName string, uniqueKeyColumns
 	values := buildColumnsPreparedValues(uniqueKeyColumns)
 	minUniqueColNames := []string{}
 	maxUniqueColNames := []string{}
+	countUniqueColNames := []string{}
 	for _, name := range uniqueKeyColumns.Names() {
 		minColName := TruncateColumnName(name, MaxColumnNameLength-4) + "_min"
 		maxColName := TruncateColumnName(name, MaxColumnNameLength-4) + "_max"
+		countColName := TruncateColumnName(name, MaxColumnNameLength-4) + "_cnt"
 		minUniqueColNames = append(minUniqueColNames, minColName)
 		maxUniqueColNames = append(maxUniqueColNames, maxColName)
+		countUniqueColNames = append(countUniqueColNames, countColName)
 	}
 	databaseName = EscapeName(databaseName)
 	tableName = EscapeName(tableName)
@@ -143,14 +146,20 @@ func NewCheckpointQueryBuilder(databaseName, tableName string, uniqueKeyColumns
 		into %s.%s
 			(gh_ost_chk_timestamp, gh_ost_chk_coords, gh_ost_chk_iteration,
 			 gh_ost_rows_copied, gh_ost_dml_applied, gh_ost_is_cutover,
-  			 %s, %s)
+			 gh_ost_rows_counted, gh_ost_is_count_complete,
+			 gh_ost_chk_heartbeat, gh_ost_chk_server_uuid,
+  			 %s, %s, %s)
 		values
 			(unix_timestamp(now()), ?, ?,
 			 ?, ?, ?,
-			 %s, %s)`,
+			 ?, ?,
+			 ?, ?,
+			 %s, %s, %s)`,
 		databaseName, tableName,
 		strings.Join(minUniqueColNames, ", "),
 		strings.Join(maxUniqueColNames, ", "),
+		strings.Join(countUniqueColNames, ", "),
+		strings.Join(values, ", "),
 		strings.Join(values, ", "),
 		strings.Join(values, ", "),
 	)
@@ -162,19 +171,17 @@ func NewCheckpointQueryBuilder(databaseName, tableName string, uniqueKeyColumns
 	return b, nil
 }
 
-// BuildQuery builds the insert query.
+// BuildQuery builds the insert query. uniqueKeyArgs holds the iteration range min values,
+// followed by the iteration range max values, followed by the row count range max values.
 func (b *CheckpointInsertQueryBuilder) BuildQuery(uniqueKeyArgs []interface{}) (string, []interface{}, error) {
-	if len(uniqueKeyArgs) != 2*b.uniqueKeyColumns.Len() {
-		return "", nil, fmt.Errorf("args count differs from 2 x unique key column count")
-	}
-	convertedArgs := make([]interface{}, 0, 2*b.uniqueKeyColumns.Len())
-	for i, column := range b.uniqueKeyColumns.Columns() {
-		minArg := column.convertArg(uniqueKeyArgs[i])
-		convertedArgs = append(convertedArgs, minArg)
+	if len(uniqueKeyArgs) != 3*b.uniqueKeyColumns.Len() {
+		return "", nil, fmt.Errorf("args count differs from 3 x unique key column count")
 	}
-	for i, column := range b.uniqueKeyColumns.Columns() {
-		minArg := column.convertArg(uniqueKeyArgs[i+b.uniqueKeyColumns.Len()])
-		convertedArgs = append(convertedArgs, minArg)
+	convertedArgs := make([]interface{}, 0, 3*b.uniqueKeyColumns.Len())
+	for offset := 0; offset < len(uniqueKeyArgs); offset += b.uniqueKeyColumns.Len() {
+		for i, column := range b.uniqueKeyColumns.Columns() {
+			convertedArgs = append(convertedArgs, column.convertArg(uniqueKeyArgs[offset+i]))
+		}
 	}
 	return b.preparedStatement, convertedArgs, nil
 }
@@ -330,6 +337,92 @@ func BuildRangeInsertPreparedQuery(databaseName, originalTableName, ghostDatabas
 	return BuildRangeInsertQuery(databaseName, originalTableName, ghostDatabaseName, ghostTableName, sharedColumns, mappedSharedColumns, uniqueKey, uniqueKeyColumns, rangeStartValues, rangeEndValues, rangeStartArgs, rangeEndArgs, includeRangeStartValues, transactionalTable, noWait)
 }
 
+// BuildRangeSelectQuery builds a query reading the shared columns of a single chunk, bounded by
+// the given unique key range. It is the read half of BuildRangeInsertQuery, used when rows are
+// read on one server and written on another.
+func BuildRangeSelectQuery(databaseName, tableName string, sharedColumns []string, uniqueKey string, uniqueKeyColumns *ColumnList, rangeStartValues, rangeEndValues []string, rangeStartArgs, rangeEndArgs []interface{}, includeRangeStartValues bool) (result string, explodedArgs []interface{}, err error) {
+	if len(sharedColumns) == 0 {
+		return "", explodedArgs, fmt.Errorf("Got 0 shared columns in BuildRangeSelectQuery")
+	}
+	databaseName = EscapeName(databaseName)
+	tableName = EscapeName(tableName)
+
+	sharedColumns = duplicateNames(sharedColumns)
+	for i := range sharedColumns {
+		sharedColumns[i] = EscapeName(sharedColumns[i])
+	}
+	sharedColumnsListing := strings.Join(sharedColumns, ", ")
+
+	uniqueKey = EscapeName(uniqueKey)
+	var minRangeComparisonSign ValueComparisonSign = GreaterThanComparisonSign
+	if includeRangeStartValues {
+		minRangeComparisonSign = GreaterThanOrEqualsComparisonSign
+	}
+	rangeStartComparison, rangeExplodedArgs, err := BuildRangeComparison(uniqueKeyColumns.Names(), rangeStartValues, rangeStartArgs, minRangeComparisonSign)
+	if err != nil {
+		return "", explodedArgs, err
+	}
+	explodedArgs = append(explodedArgs, rangeExplodedArgs...)
+	rangeEndComparison, rangeExplodedArgs, err := BuildRangeComparison(uniqueKeyColumns.Names(), rangeEndValues, rangeEndArgs, LessThanOrEqualsComparisonSign)
+	if err != nil {
+		return "", explodedArgs, err
+	}
+	explodedArgs = append(explodedArgs, rangeExplodedArgs...)
+	result = fmt.Sprintf(`
+		select /* gh-ost %s.%s */ %s
+		from
+			%s.%s
+		force index (%s)
+		where
+			(%s and %s)`,
+		databaseName, tableName, sharedColumnsListing,
+		databaseName, tableName, uniqueKey,
+		rangeStartComparison, rangeEndComparison)
+	return result, explodedArgs, nil
+}
+
+func BuildRangeSelectPreparedQuery(databaseName, tableName string, sharedColumns []string, uniqueKey string, uniqueKeyColumns *ColumnList, rangeStartArgs, rangeEndArgs []interface{}, includeRangeStartValues bool) (result string, explodedArgs []interface{}, err error) {
+	rangeStartValues := buildColumnsPreparedValues(uniqueKeyColumns)
+	rangeEndValues := buildColumnsPreparedValues(uniqueKeyColumns)
+	return BuildRangeSelectQuery(databaseName, tableName, sharedColumns, uniqueKey, uniqueKeyColumns, rangeStartValues, rangeEndValues, rangeStartArgs, rangeEndArgs, includeRangeStartValues)
+}
+
+// BuildMultiRowInsertIgnorePreparedQuery builds a multi-row `insert ignore` statement with
+// rowsCount rows of placeholders for the given (mapped) shared columns.
+func BuildMultiRowInsertIgnorePreparedQuery(databaseName, tableName string, mappedSharedColumns []string, rowsCount int) (result string, err error) {
+	if len(mappedSharedColumns) == 0 {
+		return "", fmt.Errorf("Got 0 shared columns in BuildMultiRowInsertIgnorePreparedQuery")
+	}
+	if rowsCount <= 0 {
+		return "", fmt.Errorf("Got %d rows in BuildMultiRowInsertIgnorePreparedQuery", rowsCount)
+	}
+	databaseName = EscapeName(databaseName)
+	tableName = EscapeName(tableName)
+
+	mappedSharedColumns = duplicateNames(mappedSharedColumns)
+	for i := range mappedSharedColumns {
+		mappedSharedColumns[i] = EscapeName(mappedSharedColumns[i])
+	}
+	mappedSharedColumnsListing := strings.Join(mappedSharedColumns, ", ")
+
+	rowValues := fmt.Sprintf("(%s)", strings.Join(buildPreparedValues(len(mappedSharedColumns)), ", "))
+	valuesListing := make([]string, rowsCount)
+	for i := range valuesListing {
+		valuesListing[i] = rowValues
+	}
+	result = fmt.Sprintf(`
+		insert /* gh-ost %s.%s */ ignore
+		into
+			%s.%s
+			(%s)
+		values
+			%s`,
+		databaseName, tableName,
+		databaseName, tableName, mappedSharedColumnsListing,
+		strings.Join(valuesListing, ", "))
+	return result, nil
+}
+
 func BuildUniqueKeyRangeEndPreparedQueryViaOffset(databaseName, tableName string, uniqueKeyColumns *ColumnList, rangeStartArgs, rangeEndArgs []interface{}, chunkSize int64, includeRangeStartValues bool, hint string) (result string, explodedArgs []interface{}, err error) {
 	if uniqueKeyColumns.Len() == 0 {
 		return "", explodedArgs, fmt.Errorf("Got 0 columns in BuildUniqueKeyRangeEndPreparedQuery")
@@ -443,6 +536,45 @@ func BuildUniqueKeyRangeEndPreparedQueryViaTemptable(databaseName, tableName str
 	return result, explodedArgs, nil
 }
 
+// BuildUniqueKeyRangeCountPreparedQuery builds a query counting the rows within a range of the unique key.
+func BuildUniqueKeyRangeCountPreparedQuery(databaseName, tableName string, uniqueKey string, uniqueKeyColumns *ColumnList, rangeStartArgs, rangeEndArgs []interface{}, includeRangeStartValues bool) (result string, explodedArgs []interface{}, err error) {
+	if uniqueKeyColumns.Len() == 0 {
+		return "", explodedArgs, fmt.Errorf("Got 0 columns in BuildUniqueKeyRangeCountPreparedQuery")
+	}
+	databaseName = EscapeName(databaseName)
+	tableName = EscapeName(tableName)
+
+	var startRangeComparisonSign ValueComparisonSign = GreaterThanComparisonSign
+	if includeRangeStartValues {
+		startRangeComparisonSign = GreaterThanOrEqualsComparisonSign
+	}
+	rangeStartComparison, rangeExplodedArgs, err := BuildRangePreparedComparison(uniqueKeyColumns, rangeStartArgs, startRangeComparisonSign)
+	if err != nil {
+		return "", explodedArgs, err
+	}
+	explodedArgs = append(explodedArgs, rangeExplodedArgs...)
+	rangeEndComparison, rangeExplodedArgs, err := BuildRangePreparedComparison(uniqueKeyColumns, rangeEndArgs, LessThanOrEqualsComparisonSign)
+	if err != nil {
+		return "", explodedArgs, err
+	}
+	explodedArgs = append(explodedArgs, rangeExplodedArgs...)
+
+	result = fmt.Sprintf(`
+		select /* gh-ost %s.%s rowcount */
+			count(*) as count_rows
+		from
+			%s.%s
+			force index (%s)
+		where
+			%s and %s`,
+		databaseName, tableName,
+		databaseName, tableName,
+		EscapeName(uniqueKey),
+		rangeStartComparison, rangeEndComparison,
+	)
+	return result, explodedArgs, nil
+}
+
 func BuildUniqueKeyMinValuesPreparedQuery(databaseName, tableName string, uniqueKey *UniqueKey) (string, error) {
 	return buildUniqueKeyMinMaxValuesPreparedQuery(databaseName, tableName, uniqueKey, "asc")
 }
@@ -488,6 +620,8 @@ func buildUniqueKeyMinMaxValuesPreparedQuery(databaseName, tableName string, uni
 type DMLDeleteQueryBuilder struct {
 	tableColumns, uniqueKeyColumns *ColumnList
 	preparedStatement              string
+	multiRowStatementPrefix        string
+	rowValues                      string
 }
 
 // NewDMLDeleteQueryBuilder creates a new DMLDeleteQueryBuilder.
@@ -516,10 +650,27 @@ func NewDMLDeleteQueryBuilder(databaseName, tableName string, tableColumns, uniq
 		equalsComparison,
 	)
 
+	uniqueKeyColumnNames := duplicateNames(uniqueKeyColumns.Names())
+	for i := range uniqueKeyColumnNames {
+		uniqueKeyColumnNames[i] = EscapeName(uniqueKeyColumnNames[i])
+	}
+	multiRowStatementPrefix := fmt.Sprintf(`
+		delete /* gh-ost %s.%s */
+		from
+			%s.%s
+		where
+			(%s) in `,
+		databaseName, tableName,
+		databaseName, tableName,
+		strings.Join(uniqueKeyColumnNames, ", "),
+	)
+
 	b := &DMLDeleteQueryBuilder{
-		tableColumns:      tableColumns,
-		uniqueKeyColumns:  uniqueKeyColumns,
-		preparedStatement: stmt,
+		tableColumns:            tableColumns,
+		uniqueKeyColumns:        uniqueKeyColumns,
+		preparedStatement:       stmt,
+		multiRowStatementPrefix: multiRowStatementPrefix,
+		rowValues:               fmt.Sprintf("(%s)", strings.Join(buildPreparedValues(uniqueKeyColumns.Len()), ", ")),
 	}
 	return b, nil
 }
@@ -540,11 +691,34 @@ func (b *DMLDeleteQueryBuilder) BuildQuery(args []interface{}) (string, []interf
 	return b.preparedStatement, uniqueKeyArgs, nil
 }
 
+// BuildMultiRowQuery builds a single DELETE query for multiple DML events, matching rows by their
+// unique key values with an `in` list. It returns the query string and the unique key arguments of
+// all rows. Returns an error if no rows are given or the arguments of any row are invalid.
+func (b *DMLDeleteQueryBuilder) BuildMultiRowQuery(rowsArgs [][]interface{}) (string, []interface{}, error) {
+	if len(rowsArgs) == 0 {
+		return "", nil, fmt.Errorf("no rows found in BuildMultiRowQuery")
+	}
+	rowsValues := make([]string, 0, len(rowsArgs))
+	uniqueKeyArgs := make([]interface{}, 0, len(rowsArgs)*b.uniqueKeyColumns.Len())
+	for _, args := range rowsArgs {
+		_, rowUniqueKeyArgs, err := b.BuildQuery(args)
+		if err != nil {
+			return "", nil, err
+		}
+		rowsValues = append(rowsValues, b.rowValues)
+		uniqueKeyArgs = append(uniqueKeyArgs, rowUniqueKeyArgs...)
+	}
+	query := fmt.Sprintf("%s(%s)", b.multiRowStatementPrefix, strings.Join(rowsValues, ", "))
+	return query, uniqueKeyArgs, nil
+}
+
 // DMLInsertQueryBuilder can build INSERT queries for DML events.
 // It holds the prepared query statement so it doesn't need to be recreated every time.
 type DMLInsertQueryBuilder struct {
 	tableColumns, sharedColumns *ColumnList
 	preparedStatement           string
+	multiRowStatementPrefix     string
+	rowValues                   string
 }
 
 // NewDMLInsertQueryBuilder creates a new DMLInsertQueryBuilder.
@@ -566,23 +740,25 @@ func NewDMLInsertQueryBuilder(databaseName, tableName string, tableColumns, shar
 	}
 	preparedValues := buildColumnsPreparedValues(mappedSharedColumns)
 
-	stmt := fmt.Sprintf(`
+	multiRowStatementPrefix := fmt.Sprintf(`
 		insert /* gh-ost %s.%s */ ignore
 		into
 			%s.%s
 			(%s)
 		values
-			(%s)`,
+			`,
 		databaseName, tableName,
 		databaseName, tableName,
 		strings.Join(mappedSharedColumnNames, ", "),
-		strings.Join(preparedValues, ", "),
 	)
+	rowValues := fmt.Sprintf("(%s)", strings.Join(preparedValues, ", "))
 
 	return &DMLInsertQueryBuilder{
-		tableColumns:      tableColumns,
-		sharedColumns:     sharedColumns,
-		preparedStatement: stmt,
+		tableColumns:            tableColumns,
+		sharedColumns:           sharedColumns,
+		preparedStatement:       multiRowStatementPrefix + rowValues,
+		multiRowStatementPrefix: multiRowStatementPrefix,
+		rowValues:               rowValues,
 	}, nil
 }
 
@@ -602,11 +778,34 @@ func (b *DMLInsertQueryBuilder) BuildQuery(args []interface{}) (string, []interf
 	return b.preparedStatement, sharedArgs, nil
 }
 
+// BuildMultiRowQuery builds a single multi-row INSERT query for multiple DML events.
+// It returns the query string and the shared arguments of all rows.
+// Returns an error if no rows are given or the arguments of any row are invalid.
+func (b *DMLInsertQueryBuilder) BuildMultiRowQuery(rowsArgs [][]interface{}) (string, []interface{}, error) {
+	if len(rowsArgs) == 0 {
+		return "", nil, fmt.Errorf("no rows found in BuildMultiRowQuery")
+	}
+	rowsValues := make([]string, 0, len(rowsArgs))
+	sharedArgs := make([]interface{}, 0, len(rowsArgs)*b.sharedColumns.Len())
+	for _, args := range rowsArgs {
+		_, rowSharedArgs, err := b.BuildQuery(args)
+		if err != nil {
+			return "", nil, err
+		}
+		rowsValues = append(rowsValues, b.rowValues)
+		sharedArgs = append(sharedArgs, rowSharedArgs...)
+	}
+	query := b.multiRowStatementPrefix + strings.Join(rowsValues, ", ")
+	return query, sharedArgs, nil
+}
+
 // DMLUpdateQueryBuilder can build UPDATE queries for DML events.
 // It holds the prepared query statement so it doesn't need to be recreated every time.
 type DMLUpdateQueryBuilder struct {
-	tableColumns, sharedColumns, uniqueKeyColumns *ColumnList
-	preparedStatement                             string
+	databaseName, tableName                                            string
+	tableColumns, sharedColumns, mappedSharedColumns, uniqueKeyColumns *ColumnList
+	equalsComparison                                                   string
+	preparedStatement                                                  string
 }
 
 // NewDMLUpdateQueryBuilder creates a new DMLUpdateQueryBuilder.
@@ -653,10 +852,14 @@ func NewDMLUpdateQueryBuilder(databaseName, tableName string, tableColumns, shar
 		equalsComparison,
 	)
 	return &DMLUpdateQueryBuilder{
-		tableColumns:      tableColumns,
-		sharedColumns:     sharedColumns,
-		uniqueKeyColumns:  uniqueKeyColumns,
-		preparedStatement: stmt,
+		databaseName:        databaseName,
+		tableName:           tableName,
+		tableColumns:        tableColumns,
+		sharedColumns:       sharedColumns,
+		mappedSharedColumns: mappedSharedColumns,
+		uniqueKeyColumns:    uniqueKeyColumns,
+		equalsComparison:    equalsComparison,
+		preparedStatement:   stmt,
 	}, nil
 }
 
@@ -677,3 +880,234 @@ func (b *DMLUpdateQueryBuilder) BuildQuery(valueArgs, whereArgs []interface{}) (
 
 	return b.preparedStatement, args, nil
 }
+
+// BuildPartialQuery builds an UPDATE query for a DML event whose after image is partial, as logged with
+// binlog_row_image MINIMAL or NOBLOB. Only the shared columns present in the after image, as told by
+// isPresent given a table ordinal, are set. The unique key columns must be present in whereArgs.
+// It returns an empty query when no shared column is present.
+func (b *DMLUpdateQueryBuilder) BuildPartialQuery(valueArgs, whereArgs []interface{}, isPresent func(tableOrdinal int) bool) (string, []interface{}, error) {
+	setColumns := make([]Column, 0, b.sharedColumns.Len())
+	args := make([]interface{}, 0, b.sharedColumns.Len()+b.uniqueKeyColumns.Len())
+	for i, column := range b.sharedColumns.Columns() {
+		tableOrdinal := b.tableColumns.Ordinals[column.Name]
+		if !isPresent(tableOrdinal) {
+			continue
+		}
+		setColumns = append(setColumns, b.mappedSharedColumns.Columns()[i])
+		args = append(args, column.convertArg(valueArgs[tableOrdinal]))
+	}
+	if len(setColumns) == 0 {
+		return "", nil, nil
+	}
+	setClause, err := BuildSetPreparedClause(&ColumnList{columns: setColumns})
+	if err != nil {
+		return "", nil, err
+	}
+	for _, column := range b.uniqueKeyColumns.Columns() {
+		tableOrdinal := b.tableColumns.Ordinals[column.Name]
+		args = append(args, column.convertArg(whereArgs[tableOrdinal]))
+	}
+	stmt := fmt.Sprintf(`
+		update /* gh-ost %s.%s */
+			%s.%s
+		set
+			%s
+		where
+			%s`,
+		b.databaseName, b.tableName,
+		b.databaseName, b.tableName,
+		setClause,
+		b.equalsComparison,
+	)
+	return stmt, args, nil
+}
+
+// castPreparedValue returns a placeholder token which casts its argument onto the column's type,
+// such that literal values compare as the column's values do. It supports the column types
+// allowed in a non-unique migration key: integers, decimals and temporal types other than TIMESTAMP.
+func castPreparedValue(column Column) string {
+	mysqlType := strings.ToLower(column.MySQLType)
+	switch {
+	case strings.HasPrefix(mysqlType, "decimal"):
+		return fmt.Sprintf("cast(? as %s)", strings.Fields(mysqlType)[0])
+	case strings.HasPrefix(mysqlType, "datetime"):
+		return "cast(? as datetime(6))"
+	case strings.HasPrefix(mysqlType, "date"):
+		return "cast(? as date)"
+	case strings.HasPrefix(mysqlType, "time"):
+		return "cast(? as time(6))"
+	case strings.HasPrefix(mysqlType, "year"):
+		return "cast(? as unsigned)"
+	case strings.Contains(mysqlType, "int"):
+		if column.IsUnsigned {
+			return "cast(? as unsigned)"
+		}
+		return "cast(? as signed)"
+	}
+	return "?"
+}
+
+// BuildRangeExclusionPreparedClause builds a condition which holds when a row's key values are
+// outside the given key range. The key values, range start values, key values again, and range end
+// values, are expected as arguments in that order. See BuildRangeExclusionArgs.
+func BuildRangeExclusionPreparedClause(keyColumns *ColumnList, includeRangeStartValues bool) (result string, err error) {
+	if keyColumns.Len() == 0 {
+		return "", fmt.Errorf("Got 0 columns in BuildRangeExclusionPreparedClause")
+	}
+	values := make([]string, 0, keyColumns.Len())
+	for _, column := range keyColumns.Columns() {
+		values = append(values, castPreparedValue(column))
+	}
+	tuple := fmt.Sprintf("(%s)", strings.Join(values, ", "))
+	var minRangeComparisonSign ValueComparisonSign = GreaterThanComparisonSign
+	if includeRangeStartValues {
+		minRangeComparisonSign = GreaterThanOrEqualsComparisonSign
+	}
+	result = fmt.Sprintf("not (%s %s %s and %s %s %s)",
+		tuple, minRangeComparisonSign, tuple,
+		tuple, LessThanOrEqualsComparisonSign, tuple,
+	)
+	return result, nil
+}
+
+// BuildRangeExclusionArgs orders arguments for a BuildRangeExclusionPreparedClause condition, extracting
+// the key values from a row image of the table.
+func BuildRangeExclusionArgs(tableColumns, keyColumns *ColumnList, rowArgs, rangeStartArgs, rangeEndArgs []interface{}) (explodedArgs []interface{}, err error) {
+	if len(rowArgs) != tableColumns.Len() {
+		return nil, fmt.Errorf("args count differs from table column count in BuildRangeExclusionArgs")
+	}
+	keyArgs := make([]interface{}, 0, keyColumns.Len())
+	for _, column := range keyColumns.Columns() {
+		tableOrdinal := tableColumns.Ordinals[column.Name]
+		keyArgs = append(keyArgs, column.convertArg(rowArgs[tableOrdinal]))
+	}
+	explodedArgs = append(explodedArgs, keyArgs...)
+	explodedArgs = append(explodedArgs, rangeStartArgs...)
+	explodedArgs = append(explodedArgs, keyArgs...)
+	explodedArgs = append(explodedArgs, rangeEndArgs...)
+	return explodedArgs, nil
+}
+
+// DMLRowImageDeleteQueryBuilder can build DELETE queries for DML events on tables migrated by a
+// non-unique key. Rows are matched by their full image and at most one row is deleted.
+type DMLRowImageDeleteQueryBuilder struct {
+	tableColumns, sharedColumns *ColumnList
+	preparedStatement           string
+}
+
+// NewDMLRowImageDeleteQueryBuilder creates a new DMLRowImageDeleteQueryBuilder.
+// It prepares the DELETE query statement, matching all shared columns null-safely.
+func NewDMLRowImageDeleteQueryBuilder(databaseName, tableName string, tableColumns, sharedColumns, mappedSharedColumns *ColumnList) (*DMLRowImageDeleteQueryBuilder, error) {
+	if !sharedColumns.IsSubsetOf(tableColumns) {
+		return nil, fmt.Errorf("shared columns is not a subset of table columns in NewDMLRowImageDeleteQueryBuilder")
+	}
+	if sharedColumns.Len() == 0 {
+		return nil, fmt.Errorf("no shared columns found in NewDMLRowImageDeleteQueryBuilder")
+	}
+	databaseName = EscapeName(databaseName)
+	tableName = EscapeName(tableName)
+	preparedValues := buildColumnsPreparedValues(mappedSharedColumns)
+	comparisons := make([]string, 0, mappedSharedColumns.Len())
+	for i, name := range mappedSharedColumns.Names() {
+		comparisons = append(comparisons, fmt.Sprintf("(%s <=> %s)", EscapeName(name), preparedValues[i]))
+	}
+
+	stmt := fmt.Sprintf(`
+		delete /* gh-ost %s.%s */
+		from
+			%s.%s
+		where
+			(%s)`,
+		databaseName, tableName,
+		databaseName, tableName,
+		strings.Join(comparisons, " and "),
+	)
+	return &DMLRowImageDeleteQueryBuilder{
+		tableColumns:      tableColumns,
+		sharedColumns:     sharedColumns,
+		preparedStatement: stmt,
+	}, nil
+}
+
+// BuildQuery builds the query and arguments for a DML event DELETE query. When given, the
+// condition is appended to the query, followed by its own arguments.
+func (b *DMLRowImageDeleteQueryBuilder) BuildQuery(args []interface{}, condition string, conditionArgs []interface{}) (string, []interface{}, error) {
+	if len(args) != b.tableColumns.Len() {
+		return "", nil, fmt.Errorf("args count differs from table column count in BuildDMLRowImageDeleteQuery")
+	}
+	sharedArgs := make([]interface{}, 0, b.sharedColumns.Len()+len(conditionArgs))
+	for _, column := range b.sharedColumns.Columns() {
+		tableOrdinal := b.tableColumns.Ordinals[column.Name]
+		arg := column.convertArg(args[tableOrdinal])
+		sharedArgs = append(sharedArgs, arg)
+	}
+	query := b.preparedStatement
+	if condition != "" {
+		query = fmt.Sprintf("%s and %s", query, condition)
+		sharedArgs = append(sharedArgs, conditionArgs...)
+	}
+	return fmt.Sprintf("%s limit 1", query), sharedArgs, nil
+}
+
+// DMLRowImageInsertQueryBuilder can build INSERT queries for DML events on tables migrated by a
+// non-unique key. The insert may be made conditional, see BuildQuery.
+type DMLRowImageInsertQueryBuilder struct {
+	tableColumns, sharedColumns *ColumnList
+	preparedStatement           string
+}
+
+// NewDMLRowImageInsertQueryBuilder creates a new DMLRowImageInsertQueryBuilder.
+func NewDMLRowImageInsertQueryBuilder(databaseName, tableName string, tableColumns, sharedColumns, mappedSharedColumns *ColumnList) (*DMLRowImageInsertQueryBuilder, error) {
+	if !sharedColumns.IsSubsetOf(tableColumns) {
+		return nil, fmt.Errorf("shared columns is not a subset of table columns in NewDMLRowImageInsertQueryBuilder")
+	}
+	if sharedColumns.Len() == 0 {
+		return nil, fmt.Errorf("no shared columns found in NewDMLRowImageInsertQueryBuilder")
+	}
+	databaseName = EscapeName(databaseName)
+	tableName = EscapeName(tableName)
+	mappedSharedColumnNames := duplicateNames(mappedSharedColumns.Names())
+	for i := range mappedSharedColumnNames {
+		mappedSharedColumnNames[i] = EscapeName(mappedSharedColumnNames[i])
+	}
+	preparedValues := buildColumnsPreparedValues(mappedSharedColumns)
+
+	stmt := fmt.Sprintf(`
+		insert /* gh-ost %s.%s */
+		into
+			%s.%s
+			(%s)
+		select
+			%s
+		from
+			dual`,
+		databaseName, tableName,
+		databaseName, tableName,
+		strings.Join(mappedSharedColumnNames, ", "),
+		strings.Join(preparedValues, ", "),
+	)
+	return &DMLRowImageInsertQueryBuilder{
+		tableColumns:      tableColumns,
+		sharedColumns:     sharedColumns,
+		preparedStatement: stmt,
+	}, nil
+}
+
+// BuildQuery builds the query and arguments for a DML event INSERT query. When given, the
+// row is only inserted if the condition holds; the condition's arguments follow the row's.
+func (b *DMLRowImageInsertQueryBuilder) BuildQuery(args []interface{}, condition string, conditionArgs []interface{}) (string, []interface{}, error) {
+	if len(args) != b.tableColumns.Len() {
+		return "", nil, fmt.Errorf("args count differs from table column count in BuildDMLRowImageInsertQuery")
+	}
+	sharedArgs := make([]interface{}, 0, b.sharedColumns.Len()+len(conditionArgs))
+	for _, column := range b.sharedColumns.Columns() {
+		tableOrdinal := b.tableColumns.Ordinals[column.Name]
+		arg := column.convertArg(args[tableOrdinal])
+		sharedArgs = append(sharedArgs, arg)
+	}
+	if condition == "" {
+		return b.preparedStatement, sharedArgs, nil
+	}
+	sharedArgs = append(sharedArgs, conditionArgs...)
+	return fmt.Sprintf("%s where %s", b.preparedStatement, condition), sharedArgs, nil
+}
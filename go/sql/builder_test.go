@@ -328,6 +328,88 @@ func TestBuildRangeInsertPreparedQuery(t *testing.T) {
 	}
 }
 
+func TestBuildRangeSelectPreparedQuery(t *testing.T) {
+	databaseName := "mydb"
+	tableName := "tbl"
+	sharedColumns := []string{"id", "name", "position"}
+	{
+		uniqueKey := "PRIMARY"
+		uniqueKeyColumns := NewColumnList([]string{"id"})
+		rangeStartArgs := []interface{}{3}
+		rangeEndArgs := []interface{}{103}
+
+		query, explodedArgs, err := BuildRangeSelectPreparedQuery(databaseName, tableName, sharedColumns, uniqueKey, uniqueKeyColumns, rangeStartArgs, rangeEndArgs, true)
+		require.NoError(t, err)
+		expected := `
+			select /* gh-ost mydb.tbl */ id, name, position
+			from
+				mydb.tbl
+			force index (PRIMARY)
+			where (((id > ?) or ((id = ?))) and ((id < ?) or ((id = ?))))`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{3, 3, 103, 103}, explodedArgs)
+	}
+	{
+		uniqueKey := "name_position_uidx"
+		uniqueKeyColumns := NewColumnList([]string{"name", "position"})
+		rangeStartArgs := []interface{}{3, 17}
+		rangeEndArgs := []interface{}{103, 117}
+
+		query, explodedArgs, err := BuildRangeSelectPreparedQuery(databaseName, tableName, sharedColumns, uniqueKey, uniqueKeyColumns, rangeStartArgs, rangeEndArgs, false)
+		require.NoError(t, err)
+		expected := `
+			select /* gh-ost mydb.tbl */ id, name, position
+			from
+				mydb.tbl
+			force index (name_position_uidx)
+			where (((name > ?) or (((name = ?)) AND (position > ?))) and ((name < ?) or (((name = ?)) AND (position < ?)) or ((name = ?) and (position = ?))))`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{3, 3, 17, 103, 103, 117, 103, 117}, explodedArgs)
+	}
+	{
+		_, _, err := BuildRangeSelectPreparedQuery(databaseName, tableName, []string{}, "PRIMARY", NewColumnList([]string{"id"}), []interface{}{3}, []interface{}{103}, true)
+		require.Error(t, err)
+	}
+}
+
+func TestBuildMultiRowInsertIgnorePreparedQuery(t *testing.T) {
+	databaseName := "mydb"
+	tableName := "ghost"
+	mappedSharedColumns := []string{"id", "name", "position"}
+	{
+		query, err := BuildMultiRowInsertIgnorePreparedQuery(databaseName, tableName, mappedSharedColumns, 1)
+		require.NoError(t, err)
+		expected := `
+			insert /* gh-ost mydb.ghost */ ignore
+			into
+				mydb.ghost
+				(id, name, position)
+			values
+				(?, ?, ?)`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+	}
+	{
+		query, err := BuildMultiRowInsertIgnorePreparedQuery(databaseName, tableName, mappedSharedColumns, 3)
+		require.NoError(t, err)
+		expected := `
+			insert /* gh-ost mydb.ghost */ ignore
+			into
+				mydb.ghost
+				(id, name, position)
+			values
+				(?, ?, ?), (?, ?, ?), (?, ?, ?)`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+	}
+	{
+		_, err := BuildMultiRowInsertIgnorePreparedQuery(databaseName, tableName, mappedSharedColumns, 0)
+		require.Error(t, err)
+	}
+	{
+		_, err := BuildMultiRowInsertIgnorePreparedQuery(databaseName, tableName, []string{}, 1)
+		require.Error(t, err)
+	}
+}
+
 func TestBuildUniqueKeyRangeEndPreparedQueryViaOffset(t *testing.T) {
 	databaseName := "mydb"
 	originalTableName := "tbl"
@@ -529,6 +611,50 @@ func TestBuildDMLDeleteQuerySignedUnsigned(t *testing.T) {
 	}
 }
 
+func TestBuildDMLDeleteMultiRowQuery(t *testing.T) {
+	databaseName := "mydb"
+	tableName := "tbl"
+	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
+	rowsArgs := [][]interface{}{
+		{3, "testname", "first", 17, 23},
+		{4, "othername", "second", 18, 24},
+	}
+	{
+		uniqueKeyColumns := NewColumnList([]string{"position"})
+		builder, err := NewDMLDeleteQueryBuilder(databaseName, tableName, tableColumns, uniqueKeyColumns)
+		require.NoError(t, err)
+
+		query, uniqueKeyArgs, err := builder.BuildMultiRowQuery(rowsArgs)
+		require.NoError(t, err)
+		expected := `
+			delete /* gh-ost mydb.tbl */
+				from
+					mydb.tbl
+				where
+					(position) in ((?), (?))
+		`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{17, 18}, uniqueKeyArgs)
+	}
+	{
+		uniqueKeyColumns := NewColumnList([]string{"name", "position"})
+		builder, err := NewDMLDeleteQueryBuilder(databaseName, tableName, tableColumns, uniqueKeyColumns)
+		require.NoError(t, err)
+
+		query, uniqueKeyArgs, err := builder.BuildMultiRowQuery(rowsArgs)
+		require.NoError(t, err)
+		expected := `
+			delete /* gh-ost mydb.tbl */
+				from
+					mydb.tbl
+				where
+					(name, position) in ((?, ?), (?, ?))
+		`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{"testname", 17, "othername", 18}, uniqueKeyArgs)
+	}
+}
+
 func TestBuildDMLInsertQuery(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
@@ -578,6 +704,166 @@ func TestBuildDMLInsertQuery(t *testing.T) {
 	}
 }
 
+func TestBuildDMLInsertMultiRowQuery(t *testing.T) {
+	databaseName := "mydb"
+	tableName := "tbl"
+	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
+	sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
+	builder, err := NewDMLInsertQueryBuilder(databaseName, tableName, tableColumns, sharedColumns, sharedColumns)
+	require.NoError(t, err)
+	{
+		query, sharedArgs, err := builder.BuildMultiRowQuery([][]interface{}{
+			{3, "testname", "first", 17, 23},
+			{4, "othername", "second", 18, 24},
+		})
+		require.NoError(t, err)
+		expected := `
+			insert /* gh-ost mydb.tbl */ ignore
+				into mydb.tbl
+					(id, name, position, age)
+				values
+					(?, ?, ?, ?), (?, ?, ?, ?)
+		`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{3, "testname", 17, 23, 4, "othername", 18, 24}, sharedArgs)
+	}
+	{
+		_, _, err := builder.BuildMultiRowQuery([][]interface{}{{3, "testname", "first", 17, 23}, {4, "othername"}})
+		require.Error(t, err)
+	}
+	{
+		_, _, err := builder.BuildMultiRowQuery(nil)
+		require.Error(t, err)
+	}
+}
+
+func TestBuildRangeExclusionPreparedClause(t *testing.T) {
+	{
+		keyColumns := NewColumnList([]string{"id"})
+		keyColumns.GetColumn("id").MySQLType = "bigint unsigned"
+		keyColumns.GetColumn("id").IsUnsigned = true
+		clause, err := BuildRangeExclusionPreparedClause(keyColumns, false)
+		require.NoError(t, err)
+		require.Equal(t, "not ((cast(? as unsigned)) > (cast(? as unsigned)) and (cast(? as unsigned)) <= (cast(? as unsigned)))", clause)
+	}
+	{
+		keyColumns := NewColumnList([]string{"amount", "dt", "d"})
+		keyColumns.GetColumn("amount").MySQLType = "decimal(10,2) unsigned"
+		keyColumns.GetColumn("dt").MySQLType = "datetime(3)"
+		keyColumns.GetColumn("d").MySQLType = "date"
+		clause, err := BuildRangeExclusionPreparedClause(keyColumns, true)
+		require.NoError(t, err)
+		tuple := "(cast(? as decimal(10,2)), cast(? as datetime(6)), cast(? as date))"
+		require.Equal(t, "not ("+tuple+" >= "+tuple+" and "+tuple+" <= "+tuple+")", clause)
+	}
+	{
+		_, err := BuildRangeExclusionPreparedClause(NewColumnList([]string{}), true)
+		require.Error(t, err)
+	}
+	{
+		tableColumns := NewColumnList([]string{"id", "name", "rank"})
+		keyColumns := NewColumnList([]string{"rank", "id"})
+		args, err := BuildRangeExclusionArgs(tableColumns, keyColumns, []interface{}{5, "x", 6}, []interface{}{1, 2}, []interface{}{9, 9})
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{6, 5, 1, 2, 6, 5, 9, 9}, args)
+
+		_, err = BuildRangeExclusionArgs(tableColumns, keyColumns, []interface{}{5}, []interface{}{1, 2}, []interface{}{9, 9})
+		require.Error(t, err)
+	}
+}
+
+func TestBuildDMLRowImageDeleteQuery(t *testing.T) {
+	databaseName := "mydb"
+	tableName := "tbl"
+	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
+	args := []interface{}{3, "testname", "first", 17, nil}
+	{
+		sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
+		builder, err := NewDMLRowImageDeleteQueryBuilder(databaseName, tableName, tableColumns, sharedColumns, sharedColumns)
+		require.NoError(t, err)
+		query, queryArgs, err := builder.BuildQuery(args, "", nil)
+		require.NoError(t, err)
+		expected := `
+			delete /* gh-ost mydb.tbl */
+				from mydb.tbl
+				where
+					((id <=> ?) and (name <=> ?) and (position <=> ?) and (age <=> ?))
+				limit 1
+		`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{3, "testname", 17, nil}, queryArgs)
+
+		query, queryArgs, err = builder.BuildQuery(args, "not ((?) > (?) and (?) <= (?))", []interface{}{3, 1, 3, 10})
+		require.NoError(t, err)
+		expected = `
+			delete /* gh-ost mydb.tbl */
+				from mydb.tbl
+				where
+					((id <=> ?) and (name <=> ?) and (position <=> ?) and (age <=> ?))
+					and not ((?) > (?) and (?) <= (?))
+				limit 1
+		`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{3, "testname", 17, nil, 3, 1, 3, 10}, queryArgs)
+	}
+	{
+		builder, err := NewDMLRowImageDeleteQueryBuilder(databaseName, tableName, tableColumns, tableColumns, tableColumns)
+		require.NoError(t, err)
+		_, _, err = builder.BuildQuery([]interface{}{3}, "", nil)
+		require.Error(t, err)
+	}
+	{
+		sharedColumns := NewColumnList([]string{"id", "surprise"})
+		_, err := NewDMLRowImageDeleteQueryBuilder(databaseName, tableName, tableColumns, sharedColumns, sharedColumns)
+		require.Error(t, err)
+	}
+}
+
+func TestBuildDMLRowImageInsertQuery(t *testing.T) {
+	databaseName := "mydb"
+	tableName := "tbl"
+	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
+	args := []interface{}{3, "testname", "first", 17, 23}
+	sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
+	builder, err := NewDMLRowImageInsertQueryBuilder(databaseName, tableName, tableColumns, sharedColumns, sharedColumns)
+	require.NoError(t, err)
+	{
+		query, queryArgs, err := builder.BuildQuery(args, "", nil)
+		require.NoError(t, err)
+		expected := `
+			insert /* gh-ost mydb.tbl */
+				into mydb.tbl
+					(id, name, position, age)
+				select
+					?, ?, ?, ?
+				from
+					dual
+		`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{3, "testname", 17, 23}, queryArgs)
+	}
+	{
+		query, queryArgs, err := builder.BuildQuery(args, "not ((?) > (?) and (?) <= (?))", []interface{}{3, 1, 3, 10})
+		require.NoError(t, err)
+		expected := `
+			insert /* gh-ost mydb.tbl */
+				into mydb.tbl
+					(id, name, position, age)
+				select
+					?, ?, ?, ?
+				from
+					dual
+				where not ((?) > (?) and (?) <= (?))
+		`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{3, "testname", 17, 23, 3, 1, 3, 10}, queryArgs)
+	}
+	{
+		_, err := NewDMLRowImageInsertQueryBuilder(databaseName, tableName, tableColumns, NewColumnList([]string{}), NewColumnList([]string{}))
+		require.Error(t, err)
+	}
+}
+
 func TestBuildDMLInsertQuerySignedUnsigned(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
@@ -745,6 +1031,40 @@ func TestBuildDMLUpdateQuery(t *testing.T) {
 	}
 }
 
+func TestBuildDMLUpdatePartialQuery(t *testing.T) {
+	databaseName := "mydb"
+	tableName := "tbl"
+	tableColumns := NewColumnList([]string{"id", "name", "rank", "position", "age"})
+	sharedColumns := NewColumnList([]string{"id", "name", "position", "age"})
+	uniqueKeyColumns := NewColumnList([]string{"id"})
+	builder, err := NewDMLUpdateQueryBuilder(databaseName, tableName, tableColumns, sharedColumns, sharedColumns, uniqueKeyColumns)
+	require.NoError(t, err)
+
+	valueArgs := []interface{}{nil, nil, "newval", nil, 24}
+	whereArgs := []interface{}{3, nil, nil, nil, nil}
+	{
+		isPresent := func(ordinal int) bool { return ordinal == 2 || ordinal == 4 }
+		query, updateArgs, err := builder.BuildPartialQuery(valueArgs, whereArgs, isPresent)
+		require.NoError(t, err)
+		expected := `
+			update /* gh-ost mydb.tbl */
+			  mydb.tbl
+					set age=?
+				where
+					((id = ?))
+		`
+		require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+		require.Equal(t, []interface{}{24, 3}, updateArgs)
+	}
+	{
+		isPresent := func(ordinal int) bool { return ordinal == 2 }
+		query, updateArgs, err := builder.BuildPartialQuery(valueArgs, whereArgs, isPresent)
+		require.NoError(t, err)
+		require.Empty(t, query)
+		require.Empty(t, updateArgs)
+	}
+}
+
 func TestBuildDMLUpdateQuerySignedUnsigned(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "tbl"
@@ -790,7 +1110,7 @@ func TestBuildDMLUpdateQuerySignedUnsigned(t *testing.T) {
 func TestCheckpointQueryBuilder(t *testing.T) {
 	databaseName := "mydb"
 	tableName := "_tbl_ghk"
-	valueArgs := []interface{}{"mona", "mascot", int8(-17), "anothername", "anotherposition", int8(-2)}
+	valueArgs := []interface{}{"mona", "mascot", int8(-17), "anothername", "anotherposition", int8(-2), "lastname", "lastposition", int8(-1)}
 	uniqueKeyColumns := NewColumnList([]string{"name", "position", "my_very_long_column_that_is_64_utf8_characters_long_很长很长很长很长很长很长"})
 	builder, err := NewCheckpointQueryBuilder(databaseName, tableName, uniqueKeyColumns)
 	require.NoError(t, err)
@@ -800,14 +1120,45 @@ func TestCheckpointQueryBuilder(t *testing.T) {
 		insert /* gh-ost */ into mydb._tbl_ghk
 		(gh_ost_chk_timestamp, gh_ost_chk_coords, gh_ost_chk_iteration,
 		 gh_ost_rows_copied, gh_ost_dml_applied, gh_ost_is_cutover,
+		 gh_ost_rows_counted, gh_ost_is_count_complete,
+		 gh_ost_chk_heartbeat, gh_ost_chk_server_uuid,
 		 name_min, position_min, my_very_long_column_that_is_64_utf8_characters_long_很长很长很长很长_min,
-		 name_max, position_max, my_very_long_column_that_is_64_utf8_characters_long_很长很长很长很长_max)
+		 name_max, position_max, my_very_long_column_that_is_64_utf8_characters_long_很长很长很长很长_max,
+		 name_cnt, position_cnt, my_very_long_column_that_is_64_utf8_characters_long_很长很长很长很长_cnt)
 		values
 		(unix_timestamp(now()), ?, ?,
+			 ?, ?, ?,
+			 ?, ?,
+			 ?, ?,
 			 ?, ?, ?,
 			 ?, ?, ?,
 			 ?, ?, ?)
     `
 	require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
-	require.Equal(t, []interface{}{"mona", "mascot", int8(-17), "anothername", "anotherposition", int8(-2)}, uniqueKeyArgs)
+	require.Equal(t, []interface{}{"mona", "mascot", int8(-17), "anothername", "anotherposition", int8(-2), "lastname", "lastposition", int8(-1)}, uniqueKeyArgs)
+
+	_, _, err = builder.BuildQuery(valueArgs[:6])
+	require.Error(t, err)
+}
+
+func TestBuildUniqueKeyRangeCountPreparedQuery(t *testing.T) {
+	databaseName := "mydb"
+	originalTableName := "tbl"
+	uniqueKeyColumns := NewColumnList([]string{"name", "position"})
+	rangeStartArgs := []interface{}{3, 17}
+	rangeEndArgs := []interface{}{103, 117}
+
+	query, explodedArgs, err := BuildUniqueKeyRangeCountPreparedQuery(databaseName, originalTableName, "name_position_uidx", uniqueKeyColumns, rangeStartArgs, rangeEndArgs, false)
+	require.NoError(t, err)
+	expected := `
+		select /* gh-ost mydb.tbl rowcount */
+			count(*) as count_rows
+		from
+			mydb.tbl
+			force index (name_position_uidx)
+		where
+			((name > ?) or (((name = ?)) AND (position > ?))) and ((name < ?) or (((name = ?)) AND (position < ?)) or ((name = ?) and (position = ?)))
+	`
+	require.Equal(t, normalizeQuery(expected), normalizeQuery(query))
+	require.Equal(t, []interface{}{3, 3, 17, 103, 103, 117, 103, 117}, explodedArgs)
 }
@@ -0,0 +1,210 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	version "github.com/hashicorp/go-version"
+)
+
+type DDLAlgorithm string
+
+const (
+	DDLAlgorithmInstant DDLAlgorithm = "INSTANT"
+	DDLAlgorithmInplace DDLAlgorithm = "INPLACE"
+	DDLAlgorithmCopy    DDLAlgorithm = "COPY"
+	DDLAlgorithmUnknown DDLAlgorithm = "UNKNOWN"
+)
+
+// MaxInstantRowVersions is the number of row versions an InnoDB table may have; once reached, columns
+// can no longer be added or dropped instantly, until the table is rebuilt
+const MaxInstantRowVersions = 64
+
+var (
+	unpredictedOperationRegexp  = regexp.MustCompile(`(?i)^(add|drop)\s+(primary|foreign|constraint|check|partition)\b`)
+	addIndexRegexp              = regexp.MustCompile(`(?i)^add\s+(unique|fulltext|spatial|index|key)\b`)
+	addColumnRegexp             = regexp.MustCompile(`(?i)^add\s+`)
+	columnPositionRegexp        = regexp.MustCompile(`(?i)\s(first|after)\b`)
+	autoIncrementColumnRegexp   = regexp.MustCompile(`(?i)\bauto_increment\b`)
+	storedColumnRegexp          = regexp.MustCompile(`(?i)\bstored\b`)
+	virtualColumnRegexp         = regexp.MustCompile(`(?i)\b(virtual|generated\s+always\s+as)\b`)
+	dropIndexRegexp             = regexp.MustCompile(`(?i)^drop\s+(index|key)\b`)
+	dropColumnOperationRegexp   = regexp.MustCompile(`(?i)^drop\s+`)
+	renameIndexRegexp           = regexp.MustCompile(`(?i)^rename\s+(index|key)\b`)
+	renameColumnOperationRegexp = regexp.MustCompile(`(?i)^rename\s+column\b`)
+	alterColumnDefaultRegexp    = regexp.MustCompile(`(?i)^alter\s+(column\s+)?\S+\s+(set\s+default|drop\s+default)\b`)
+	alterColumnVisibilityRegexp = regexp.MustCompile(`(?i)^alter\s+(column\s+)?\S+\s+set\s+(visible|invisible)\b`)
+	alterIndexVisibilityRegexp  = regexp.MustCompile(`(?i)^alter\s+index\s+\S+\s+(visible|invisible)\b`)
+)
+
+// DDLTableInfo describes the properties of a table which limit the algorithms by which it can be altered
+type DDLTableInfo struct {
+	RowFormat        string
+	HasFulltextIndex bool
+	TotalRowVersions int64
+}
+
+// DDLAlgorithmPrediction is the predicted best algorithm by which the server can apply an alter
+// statement. MetadataOnly is set for INPLACE operations which only change the table's metadata.
+type DDLAlgorithmPrediction struct {
+	Algorithm    DDLAlgorithm
+	MetadataOnly bool
+	Reasons      []string
+}
+
+func (this *DDLAlgorithmPrediction) String() string {
+	algorithm := string(this.Algorithm)
+	if this.Algorithm == DDLAlgorithmInplace && this.MetadataOnly {
+		algorithm = fmt.Sprintf("%s (metadata only)", algorithm)
+	}
+	if len(this.Reasons) == 0 {
+		return algorithm
+	}
+	return fmt.Sprintf("%s: %s", algorithm, strings.Join(this.Reasons, "; "))
+}
+
+// IsInstant returns true when the alter is predicted to apply with ALGORITHM=INSTANT
+func (this *DDLAlgorithmPrediction) IsInstant() bool {
+	return this.Algorithm == DDLAlgorithmInstant
+}
+
+// WritesData returns true when the alter is predicted to write table or index data, and so cannot be instant
+func (this *DDLAlgorithmPrediction) WritesData() bool {
+	return this.Algorithm == DDLAlgorithmCopy || (this.Algorithm == DDLAlgorithmInplace && !this.MetadataOnly)
+}
+
+// IsInplaceMetadataOnly returns true when the alter is predicted to apply with ALGORITHM=INPLACE, changing
+// the table's metadata only
+func (this *DDLAlgorithmPrediction) IsInplaceMetadataOnly() bool {
+	return this.Algorithm == DDLAlgorithmInplace && this.MetadataOnly
+}
+
+// ddlAlgorithmRank orders algorithms from cheapest to costliest. An unknown algorithm ranks above all.
+type ddlAlgorithmRank int
+
+const (
+	instantRank ddlAlgorithmRank = iota
+	inplaceMetadataOnlyRank
+	inplaceRank
+	copyRank
+	unknownRank
+)
+
+func (this ddlAlgorithmRank) prediction() *DDLAlgorithmPrediction {
+	switch this {
+	case instantRank:
+		return &DDLAlgorithmPrediction{Algorithm: DDLAlgorithmInstant}
+	case inplaceMetadataOnlyRank:
+		return &DDLAlgorithmPrediction{Algorithm: DDLAlgorithmInplace, MetadataOnly: true}
+	case inplaceRank:
+		return &DDLAlgorithmPrediction{Algorithm: DDLAlgorithmInplace}
+	case copyRank:
+		return &DDLAlgorithmPrediction{Algorithm: DDLAlgorithmCopy}
+	}
+	return &DDLAlgorithmPrediction{Algorithm: DDLAlgorithmUnknown}
+}
+
+// PredictDDLAlgorithm predicts the best algorithm by which given server version can apply the operations
+// of an alter statement (see AlterTableParser.AlterTokens) onto a table. The prediction is the costliest
+// among those of the operations. It is UNKNOWN when any of the operations is not recognized, or when
+// it depends on the current definition of a column, as with MODIFY and CHANGE.
+func PredictDDLAlgorithm(alterTokens []string, serverVersion string, tableInfo *DDLTableInfo) *DDLAlgorithmPrediction {
+	parsedVersion, err := version.NewVersion(serverVersion)
+	if err != nil {
+		return &DDLAlgorithmPrediction{Algorithm: DDLAlgorithmUnknown, Reasons: []string{fmt.Sprintf("unrecognized server version %s", serverVersion)}}
+	}
+	serverVersionAtLeast := func(minVersion string) bool {
+		return parsedVersion.Core().GreaterThanOrEqual(version.Must(version.NewVersion(minVersion)))
+	}
+	if tableInfo == nil {
+		tableInfo = &DDLTableInfo{}
+	}
+	isCompressed := strings.EqualFold(tableInfo.RowFormat, "COMPRESSED")
+	rowVersionsExhausted := tableInfo.TotalRowVersions >= MaxInstantRowVersions
+
+	// instantColumnRank ranks adding or dropping a column, which is instant as of minVersion unless row
+	// versions are exhausted, and rebuilds the table otherwise
+	instantColumnRank := func(operation string, minVersion string) (ddlAlgorithmRank, string) {
+		switch {
+		case !serverVersionAtLeast(minVersion):
+			return inplaceRank, fmt.Sprintf("%s is instant as of MySQL %s", operation, minVersion)
+		case serverVersionAtLeast("8.0.29") && rowVersionsExhausted:
+			return inplaceRank, fmt.Sprintf("table has %d row versions, the maximum for instant %s", tableInfo.TotalRowVersions, operation)
+		}
+		return instantRank, ""
+	}
+
+	rank := instantRank
+	reasons := []string{}
+	for _, token := range alterTokens {
+		token = strings.TrimSpace(token)
+		var tokenRank ddlAlgorithmRank
+		var reason string
+		switch {
+		case unpredictedOperationRegexp.MatchString(token):
+			tokenRank, reason = unknownRank, fmt.Sprintf("%s is not predicted", token)
+		case addIndexRegexp.MatchString(token):
+			tokenRank, reason = inplaceRank, fmt.Sprintf("%s builds an index", token)
+		case addColumnRegexp.MatchString(token):
+			switch {
+			case autoIncrementColumnRegexp.MatchString(token):
+				tokenRank, reason = copyRank, "adding an AUTO_INCREMENT column copies the table"
+			case storedColumnRegexp.MatchString(token):
+				tokenRank, reason = copyRank, "adding a STORED generated column copies the table"
+			case virtualColumnRegexp.MatchString(token):
+				tokenRank = instantRank
+				if !serverVersionAtLeast("8.0.12") {
+					tokenRank = inplaceMetadataOnlyRank
+				}
+			case isCompressed:
+				tokenRank, reason = inplaceRank, "columns cannot be added instantly to a COMPRESSED table"
+			case tableInfo.HasFulltextIndex:
+				tokenRank, reason = inplaceRank, "columns cannot be added instantly to a table with a FULLTEXT index"
+			case columnPositionRegexp.MatchString(token):
+				tokenRank, reason = instantColumnRank("adding a column with FIRST or AFTER", "8.0.29")
+			default:
+				tokenRank, reason = instantColumnRank("adding a column", "8.0.12")
+			}
+		case dropIndexRegexp.MatchString(token):
+			tokenRank = inplaceMetadataOnlyRank
+		case dropColumnOperationRegexp.MatchString(token):
+			tokenRank, reason = instantColumnRank("dropping a column", "8.0.29")
+		case renameIndexRegexp.MatchString(token):
+			tokenRank = inplaceMetadataOnlyRank
+		case renameColumnOperationRegexp.MatchString(token):
+			tokenRank = inplaceMetadataOnlyRank
+			if serverVersionAtLeast("8.0.28") {
+				tokenRank = instantRank
+			}
+		case alterColumnDefaultRegexp.MatchString(token):
+			tokenRank = inplaceMetadataOnlyRank
+			if serverVersionAtLeast("8.0.12") {
+				tokenRank = instantRank
+			}
+		case alterColumnVisibilityRegexp.MatchString(token):
+			tokenRank = instantRank
+			if !serverVersionAtLeast("8.0.23") {
+				tokenRank, reason = unknownRank, "column visibility requires MySQL 8.0.23"
+			}
+		case alterIndexVisibilityRegexp.MatchString(token):
+			tokenRank = inplaceMetadataOnlyRank
+		default:
+			tokenRank, reason = unknownRank, fmt.Sprintf("%s is not predicted", token)
+		}
+		if reason != "" && tokenRank > instantRank {
+			reasons = append(reasons, reason)
+		}
+		if tokenRank > rank {
+			rank = tokenRank
+		}
+	}
+	prediction := rank.prediction()
+	prediction.Reasons = reasons
+	return prediction
+}
@@ -0,0 +1,63 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictDDLAlgorithm(t *testing.T) {
+	tests := []struct {
+		name         string
+		alter        string
+		version      string
+		tableInfo    *DDLTableInfo
+		algorithm    DDLAlgorithm
+		metadataOnly bool
+	}{
+		{name: "add column", alter: "add column i int", version: "8.0.35", algorithm: DDLAlgorithmInstant},
+		{name: "add column without column keyword", alter: "add i int not null default 0", version: "8.0.12", algorithm: DDLAlgorithmInstant},
+		{name: "add column on 5.7", alter: "add column i int", version: "5.7.44-log", algorithm: DDLAlgorithmInplace},
+		{name: "add column after", alter: "add column i int after id", version: "8.0.28", algorithm: DDLAlgorithmInplace},
+		{name: "add column after on 8.0.29", alter: "add column i int after id", version: "8.0.29", algorithm: DDLAlgorithmInstant},
+		{name: "add column, row versions exhausted", alter: "add column i int", version: "8.0.35", tableInfo: &DDLTableInfo{TotalRowVersions: 64}, algorithm: DDLAlgorithmInplace},
+		{name: "add column, row versions before 8.0.29", alter: "add column i int", version: "8.0.20", tableInfo: &DDLTableInfo{TotalRowVersions: 64}, algorithm: DDLAlgorithmInstant},
+		{name: "add column to compressed table", alter: "add column i int", version: "8.0.35", tableInfo: &DDLTableInfo{RowFormat: "Compressed"}, algorithm: DDLAlgorithmInplace},
+		{name: "add column to fulltext table", alter: "add column i int", version: "8.0.35", tableInfo: &DDLTableInfo{HasFulltextIndex: true}, algorithm: DDLAlgorithmInplace},
+		{name: "add auto_increment column", alter: "add column i int auto_increment", version: "8.0.35", algorithm: DDLAlgorithmCopy},
+		{name: "add stored column", alter: "add column i int as (id + 1) stored", version: "8.0.35", algorithm: DDLAlgorithmCopy},
+		{name: "add virtual column", alter: "add column i int generated always as (id + 1) virtual", version: "8.0.35", algorithm: DDLAlgorithmInstant},
+		{name: "drop column", alter: "drop column i", version: "8.0.29", algorithm: DDLAlgorithmInstant},
+		{name: "drop column before 8.0.29", alter: "drop column i", version: "8.0.28", algorithm: DDLAlgorithmInplace},
+		{name: "drop index", alter: "drop index i_idx", version: "8.0.35", algorithm: DDLAlgorithmInplace, metadataOnly: true},
+		{name: "add index", alter: "add index i_idx (i)", version: "8.0.35", algorithm: DDLAlgorithmInplace},
+		{name: "rename index", alter: "rename index i_idx to j_idx", version: "5.7.44", algorithm: DDLAlgorithmInplace, metadataOnly: true},
+		{name: "rename column", alter: "rename column i to j", version: "8.0.28", algorithm: DDLAlgorithmInstant},
+		{name: "rename column before 8.0.28", alter: "rename column i to j", version: "8.0.27", algorithm: DDLAlgorithmInplace, metadataOnly: true},
+		{name: "set default", alter: "alter column i set default 7", version: "8.0.35", algorithm: DDLAlgorithmInstant},
+		{name: "index visibility", alter: "alter index i_idx invisible", version: "8.0.35", algorithm: DDLAlgorithmInplace, metadataOnly: true},
+		{name: "modify column", alter: "modify column i bigint", version: "8.0.35", algorithm: DDLAlgorithmUnknown},
+		{name: "engine", alter: "engine=innodb", version: "8.0.35", algorithm: DDLAlgorithmUnknown},
+		{name: "drop primary key", alter: "drop primary key", version: "8.0.35", algorithm: DDLAlgorithmUnknown},
+		{name: "costliest operation", alter: "add column i int, drop index i_idx, add index j_idx (j)", version: "8.0.35", algorithm: DDLAlgorithmInplace},
+		{name: "metadata only operations", alter: "drop index i_idx, rename index j_idx to k_idx", version: "8.0.35", algorithm: DDLAlgorithmInplace, metadataOnly: true},
+		{name: "unknown version", alter: "add column i int", version: "unknown", algorithm: DDLAlgorithmUnknown},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parser := NewParserFromAlterStatement(test.alter)
+			prediction := PredictDDLAlgorithm(parser.AlterTokens(), test.version, test.tableInfo)
+			require.Equal(t, test.algorithm, prediction.Algorithm, prediction.String())
+			require.Equal(t, test.metadataOnly, prediction.MetadataOnly, prediction.String())
+			require.Equal(t, test.algorithm == DDLAlgorithmCopy || (test.algorithm == DDLAlgorithmInplace && !test.metadataOnly), prediction.WritesData())
+			if test.algorithm != DDLAlgorithmInstant && !test.metadataOnly {
+				require.NotEmpty(t, prediction.Reasons)
+			}
+		})
+	}
+}
@@ -204,6 +204,11 @@ func (this *AlterTableParser) GetAlterStatementOptions() string {
 	return this.alterStatementOptions
 }
 
+// AlterTokens returns the comma separated operations of the alter statement, with quoted strings sanitized
+func (this *AlterTableParser) AlterTokens() []string {
+	return this.alterTokens
+}
+
 func ParseEnumValues(enumColumnType string) string {
 	if submatch := enumValuesRegexp.FindStringSubmatch(enumColumnType); len(submatch) > 0 {
 		return submatch[1]
@@ -0,0 +1,59 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package sql
+
+import (
+	"regexp"
+	"strings"
+)
+
+const triggerIdentifierPattern = "`(?:[^`]|``)+`|[\\w$]+"
+
+var (
+	// string literals and comments, which may contain anything
+	triggerLiteralsAndCommentsRegexp = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"|/\*[\s\S]*?\*/|(?:--\s|#)[^\n]*`)
+	// UPDATE keywords which do not begin an UPDATE statement
+	triggerNonStatementUpdateRegexp = regexp.MustCompile(`(?i)\b(?:on\s+duplicate\s+key|for)\s+update\b`)
+	triggerWriteRegexp              = regexp.MustCompile(`(?i)\b(?:` +
+		`insert(?:\s+(?:low_priority|delayed|high_priority|ignore))*(?:\s+into)?|` +
+		`replace(?:\s+(?:low_priority|delayed))*(?:\s+into)?|` +
+		`update(?:\s+(?:low_priority|ignore))*|` +
+		`delete(?:\s+(?:low_priority|quick|ignore))*(?:\s+from)?` +
+		`)\s+(` + triggerIdentifierPattern + `)(?:\s*[.]\s*(` + triggerIdentifierPattern + `))?`)
+)
+
+// TriggerWriteTarget is a table written to by a trigger's statement
+type TriggerWriteTarget struct {
+	// DatabaseName is empty when the statement does not qualify the table
+	DatabaseName string
+	TableName    string
+}
+
+func unescapeTriggerIdentifier(identifier string) string {
+	if strings.HasPrefix(identifier, "`") {
+		return strings.ReplaceAll(identifier[1:len(identifier)-1], "``", "`")
+	}
+	return identifier
+}
+
+// ParseTriggerWriteTargets returns the tables written to by INSERT, REPLACE, UPDATE and DELETE
+// statements in a trigger's body. Multi-table statements are reported by their first table.
+func ParseTriggerWriteTargets(triggerStatement string) (targets []TriggerWriteTarget) {
+	statement := triggerLiteralsAndCommentsRegexp.ReplaceAllString(triggerStatement, " ")
+	statement = triggerNonStatementUpdateRegexp.ReplaceAllString(statement, " ")
+	for _, submatch := range triggerWriteRegexp.FindAllStringSubmatch(statement, -1) {
+		target := TriggerWriteTarget{TableName: unescapeTriggerIdentifier(submatch[1])}
+		if submatch[2] != "" {
+			target.DatabaseName = target.TableName
+			target.TableName = unescapeTriggerIdentifier(submatch[2])
+		}
+		if strings.EqualFold(target.TableName, "from") || strings.EqualFold(target.TableName, "into") {
+			continue
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
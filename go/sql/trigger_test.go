@@ -0,0 +1,55 @@
+/*
+   Copyright 2025 GitHub Inc.
+	 See https://github.com/github/gh-ost/blob/master/LICENSE
+*/
+
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTriggerWriteTargets(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		expected  []TriggerWriteTarget
+	}{
+		{
+			name:      "set new values only",
+			statement: "SET NEW.updated_at = NOW()",
+		},
+		{
+			name:      "insert",
+			statement: "INSERT INTO audit (id, action) VALUES (NEW.id, 'insert')",
+			expected:  []TriggerWriteTarget{{TableName: "audit"}},
+		},
+		{
+			name:      "qualified and escaped",
+			statement: "BEGIN\n  INSERT IGNORE `my db`.`au``dit` SET id=NEW.id;\n  DELETE FROM test . counters WHERE id=OLD.id;\nEND",
+			expected:  []TriggerWriteTarget{{DatabaseName: "my db", TableName: "au`dit"}, {DatabaseName: "test", TableName: "counters"}},
+		},
+		{
+			name:      "replace and update",
+			statement: "BEGIN REPLACE LOW_PRIORITY INTO totals SELECT 1; UPDATE IGNORE gh_ost_test SET i=i+1; END",
+			expected:  []TriggerWriteTarget{{TableName: "totals"}, {TableName: "gh_ost_test"}},
+		},
+		{
+			name:      "update keywords which are not statements",
+			statement: "BEGIN INSERT INTO totals VALUES (1) ON DUPLICATE KEY UPDATE n=n+1; SELECT n INTO @n FROM totals FOR UPDATE; END",
+			expected:  []TriggerWriteTarget{{TableName: "totals"}},
+		},
+		{
+			name:      "literals and comments",
+			statement: "BEGIN\n  -- update gh_ost_test\n  /* delete from gh_ost_test */\n  INSERT INTO audit VALUES ('update gh_ost_test', \"delete from x\");\nEND",
+			expected:  []TriggerWriteTarget{{TableName: "audit"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, ParseTriggerWriteTargets(test.statement))
+		})
+	}
+}
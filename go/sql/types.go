@@ -283,6 +283,8 @@ type UniqueKey struct {
 	Columns          ColumnList
 	HasNullable      bool
 	IsAutoIncrement  bool
+	// IsNonUnique indicates this is a non-unique index, used by --allow-non-unique-key
+	IsNonUnique bool
 }
 
 // IsPrimary checks if this unique key is primary
@@ -299,12 +301,18 @@ func (this *UniqueKey) String() string {
 	if this.IsAutoIncrement {
 		description = fmt.Sprintf("%s (auto_increment)", description)
 	}
+	if this.IsNonUnique {
+		description = fmt.Sprintf("%s (non-unique)", description)
+	}
 	return fmt.Sprintf("%s: %s; has nullable: %+v", description, this.Columns.Names(), this.HasNullable)
 }
 
 type ColumnValues struct {
 	abstractValues []interface{}
 	ValuesPointers []interface{}
+	// absentOrdinals lists the columns missing from a partial row image, as logged with
+	// binlog_row_image MINIMAL or NOBLOB. It is nil for full row images.
+	absentOrdinals map[int]bool
 }
 
 func NewColumnValues(length int) *ColumnValues {
@@ -331,6 +339,29 @@ func ToColumnValues(abstractValues []interface{}) *ColumnValues {
 	return result
 }
 
+// ToPartialColumnValues returns the values of a row image lacking the columns of given ordinals, whose
+// values are nil. With no absent columns, the image is full.
+func ToPartialColumnValues(abstractValues []interface{}, absentOrdinals []int) *ColumnValues {
+	result := ToColumnValues(abstractValues)
+	if len(absentOrdinals) > 0 {
+		result.absentOrdinals = make(map[int]bool, len(absentOrdinals))
+		for _, ordinal := range absentOrdinals {
+			result.absentOrdinals[ordinal] = true
+		}
+	}
+	return result
+}
+
+// IsPartial returns true when some of the row's columns are absent from the image
+func (this *ColumnValues) IsPartial() bool {
+	return len(this.absentOrdinals) > 0
+}
+
+// IsPresent returns true when the column of given ordinal is present in the image
+func (this *ColumnValues) IsPresent(ordinal int) bool {
+	return !this.absentOrdinals[ordinal]
+}
+
 func (this *ColumnValues) AbstractValues() []interface{} {
 	return this.abstractValues
 }
@@ -354,5 +385,11 @@ func (this *ColumnValues) String() string {
 func (this *ColumnValues) Clone() *ColumnValues {
 	cv := NewColumnValues(len(this.abstractValues))
 	copy(cv.abstractValues, this.abstractValues)
+	if this.absentOrdinals != nil {
+		cv.absentOrdinals = make(map[int]bool, len(this.absentOrdinals))
+		for ordinal := range this.absentOrdinals {
+			cv.absentOrdinals[ordinal] = true
+		}
+	}
 	return cv
 }
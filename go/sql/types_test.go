@@ -41,6 +41,25 @@ func TestGetColumn(t *testing.T) {
 	}
 }
 
+func TestToPartialColumnValues(t *testing.T) {
+	{
+		columnValues := ToPartialColumnValues([]interface{}{1, "a", 3}, nil)
+		require.False(t, columnValues.IsPartial())
+		require.True(t, columnValues.IsPresent(1))
+	}
+	{
+		columnValues := ToPartialColumnValues([]interface{}{1, nil, 3}, []int{1})
+		require.True(t, columnValues.IsPartial())
+		require.True(t, columnValues.IsPresent(0))
+		require.False(t, columnValues.IsPresent(1))
+
+		clone := columnValues.Clone()
+		require.True(t, clone.IsPartial())
+		require.False(t, clone.IsPresent(1))
+		require.Equal(t, columnValues.AbstractValues(), clone.AbstractValues())
+	}
+}
+
 func TestBinaryToString(t *testing.T) {
 	id := []uint8{0x1b, 0x99}
 	col := make([]interface{}, 1)
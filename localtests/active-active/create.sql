@@ -0,0 +1,15 @@
+drop table if exists gh_ost_test;
+create table gh_ost_test (
+  id int,
+  origin varchar(16) charset ascii not null,
+  i int not null,
+  t varchar(128) charset utf8mb4,
+  primary key(id)
+);
+
+insert into gh_ost_test values (1, 'primary', 0, md5(rand()));
+insert into gh_ost_test values (2, 'replica', 0, md5(rand()));
+insert into gh_ost_test values (3, 'primary', 0, md5(rand()));
+insert into gh_ost_test values (4, 'replica', 0, md5(rand()));
+insert into gh_ost_test values (1001, 'shared', 0, md5(rand()));
+insert into gh_ost_test values (1002, 'shared', 0, md5(rand()));
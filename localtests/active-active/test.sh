@@ -0,0 +1,155 @@
+#!/bin/bash
+# Custom test: active-active master-master migration.
+# The replica is made a master of the primary as well (circular replication). gh-ost runs on the
+# primary with the replica as --active-active-peer, while both servers write to the table: each to
+# rows of its own, and both to the same rows. Writes go on through the cut-over.
+
+postpone_flag_file=/tmp/gh-ost-test.postpone-cutover
+touch $postpone_flag_file
+
+# Circular replication: the primary replicates from the replica
+peer_repl_host=$replica_host
+peer_repl_port=$replica_port
+if [ "$docker" = true ]; then
+    peer_repl_host=mysql-replica
+    peer_repl_port=3308
+fi
+gh-ost-test-mysql-master -e "change master to master_host='${peer_repl_host}', master_port=${peer_repl_port}, master_user='repl', master_password='repl', master_auto_position=1; start slave;"
+
+teardown_circular_replication() {
+    rm -f $postpone_flag_file
+    gh-ost-test-mysql-master -e "stop slave; reset slave all;"
+}
+
+table_name="gh_ost_test"
+ghost_table_name="\`~gh_ost_test_gho\`"
+
+# Run on the primary, rather than on the replica
+build_ghost_command
+cmd=$(echo "$cmd" | sed \
+    -e "s/--host=[^ ]*/--host=${master_host}/" \
+    -e "s/--port=[^ ]*/--port=${master_port}/" \
+    -e "s/--assume-master-host=[^ ]*//" \
+    -e "s/--test-on-replica//")
+cmd+=" --active-active-peer=${replica_host}:${replica_port} --postpone-cut-over-flag-file=${postpone_flag_file}"
+
+echo_dot
+echo "$cmd" >$exec_command_file
+echo > $test_logfile
+bash $exec_command_file >>$test_logfile 2>&1 &
+ghost_pid=$!
+
+# Both masters keep writing throughout the migration and its cut-over, and for a while after it.
+# Writes on the peer fail while it is read_only at cut-over; each writer keeps track of the rows it
+# successfully inserted and deleted.
+stop_writers_flag_file=/tmp/gh-ost-test.active-active.stop-writers
+rm -f $stop_writers_flag_file
+
+# Each master writes its own rows: odd ids on the primary, even ids on the replica
+write_rows() {
+    local mysql_client=$1
+    local origin=$2
+    local id=$3
+    local ids_file=$4
+    : >$ids_file
+    while [ ! -f $stop_writers_flag_file ]; do
+        if $mysql_client test -e "insert into gh_ost_test values (${id}, '${origin}', 0, md5(rand()))" 2>/dev/null; then
+            echo $id >>$ids_file
+        fi
+        $mysql_client test -e "update gh_ost_test set i=i+1, t=md5(rand()) where origin='${origin}' order by rand() limit 2" 2>/dev/null
+        if $mysql_client test -e "delete from gh_ost_test where origin='${origin}' and id=$((id - 20))" 2>/dev/null; then
+            sed -i "/^$((id - 20))\$/d" $ids_file
+        fi
+        id=$((id + 2))
+        sleep 0.1
+    done
+}
+# Both masters update the same rows, taking turns: each update follows the other master's last
+# update once replicated, so that the rows' final values are well defined
+wait_for_replication() {
+    local from_mysql_client=$1
+    local to_mysql_client=$2
+    gtid_executed=$($from_mysql_client -ss -e "select replace(@@global.gtid_executed, '\n', '')")
+    $to_mysql_client -ss -e "select wait_for_executed_gtid_set('${gtid_executed}', 10)" >/dev/null
+}
+update_shared_rows() {
+    while [ ! -f $stop_writers_flag_file ]; do
+        gh-ost-test-mysql-master test -e "update gh_ost_test set i=i+1, t=md5(rand()) where origin='shared'" 2>/dev/null
+        wait_for_replication gh-ost-test-mysql-master gh-ost-test-mysql-replica
+        gh-ost-test-mysql-replica test -e "update gh_ost_test set i=i*2, t=md5(rand()) where origin='shared'" 2>/dev/null
+        wait_for_replication gh-ost-test-mysql-replica gh-ost-test-mysql-master
+        sleep 0.2
+    done
+}
+primary_ids_file=/tmp/gh-ost-test.active-active.primary-ids
+replica_ids_file=/tmp/gh-ost-test.active-active.replica-ids
+write_rows gh-ost-test-mysql-master primary 5 $primary_ids_file &
+primary_writer_pid=$!
+write_rows gh-ost-test-mysql-replica replica 6 $replica_ids_file &
+replica_writer_pid=$!
+update_shared_rows &
+shared_writer_pid=$!
+
+echo_dot
+for i in {1..60}; do
+    grep -q "Row copy complete" $test_logfile && break
+    ps -p $ghost_pid >/dev/null || break
+    sleep 1; echo_dot
+done
+
+# Cut over while both masters write
+sleep 3; echo_dot
+rm -f $postpone_flag_file
+
+wait $ghost_pid
+execution_result=$?
+
+sleep 3; echo_dot
+touch $stop_writers_flag_file
+wait $primary_writer_pid $replica_writer_pid $shared_writer_pid
+wait_for_replication gh-ost-test-mysql-master gh-ost-test-mysql-replica
+wait_for_replication gh-ost-test-mysql-replica gh-ost-test-mysql-master
+teardown_circular_replication
+rm -f $stop_writers_flag_file
+
+if [ $execution_result -ne 0 ]; then
+    echo
+    echo "ERROR $test_name execution failure. cat $test_logfile:"
+    print_log_excerpt
+    return 1
+fi
+if ! grep -q "RENAME replicated onto peer" $test_logfile; then
+    echo
+    echo "ERROR $test_name: cut-over did not wait for the RENAME to replicate onto the peer"
+    print_log_excerpt
+    return 1
+fi
+if [ "$(gh-ost-test-mysql-replica -ss -e "select @@global.read_only")" != "0" ]; then
+    echo
+    echo "ERROR $test_name: peer left read_only"
+    return 1
+fi
+
+# Both masters must agree on the migrated table, and have all rows the writers inserted and did not delete
+sleep 1; echo_dot
+gh-ost-test-mysql-master test -e "select * from gh_ost_test order by id" -ss >$orig_content_output_file
+gh-ost-test-mysql-replica test -e "select * from gh_ost_test order by id" -ss >$ghost_content_output_file
+orig_checksum=$(cat $orig_content_output_file | md5sum)
+ghost_checksum=$(cat $ghost_content_output_file | md5sum)
+if [ "$orig_checksum" != "$ghost_checksum" ]; then
+    echo
+    echo "ERROR $test_name: checksum mismatch between the masters"
+    echo "---"
+    diff $orig_content_output_file $ghost_content_output_file
+    return 1
+fi
+expected_ids=$( (echo 1; echo 2; echo 3; echo 4; echo 1001; echo 1002; cat $primary_ids_file $replica_ids_file) | sort -n)
+actual_ids=$(cut -f1 $orig_content_output_file | sort -n)
+if [ "$expected_ids" != "$actual_ids" ]; then
+    echo
+    echo "ERROR $test_name: rows lost or resurrected"
+    echo "---"
+    diff <(echo "$expected_ids") <(echo "$actual_ids")
+    return 1
+fi
+return 0
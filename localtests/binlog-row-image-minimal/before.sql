@@ -0,0 +1,3 @@
+set global binlog_row_image = 'MINIMAL';
+stop replica sql_thread;
+start replica sql_thread;
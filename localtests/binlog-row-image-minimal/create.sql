@@ -0,0 +1,33 @@
+drop table if exists gh_ost_test;
+create table gh_ost_test (
+  id int auto_increment,
+  i int not null,
+  t varchar(128) not null,
+  updated tinyint unsigned not null default 0,
+  primary key(id)
+) auto_increment=1;
+
+insert into gh_ost_test values (null, 11, 'eleven', 0);
+insert into gh_ost_test values (null, 13, 'thirteen', 0);
+insert into gh_ost_test values (null, 17, 'seventeen', 0);
+
+drop event if exists gh_ost_test;
+delimiter ;;
+create event gh_ost_test
+  on schedule every 1 second
+  starts current_timestamp
+  ends current_timestamp + interval 60 second
+  on completion not preserve
+  enable
+  do
+begin
+  insert into gh_ost_test values (null, 11, 'eleven', 0);
+  insert into gh_ost_test values (null, 13, 'thirteen', 0);
+  insert into gh_ost_test values (null, 17, 'seventeen', 0);
+  insert into gh_ost_test values (null, 19, 'nineteen', 0);
+
+  update gh_ost_test set updated = updated + 1 where i = 11 order by id desc limit 1;
+  update gh_ost_test set t = concat(t, '!') where i = 13 order by id desc limit 1;
+  update gh_ost_test set id = -id where i = 17 order by id desc limit 1;
+  delete from gh_ost_test where i = 19 order by id desc limit 1;
+end ;;
@@ -0,0 +1,32 @@
+drop table if exists gh_ost_test;
+create table gh_ost_test (
+  a int not null,
+  b int not null,
+  v varchar(128),
+  updated tinyint unsigned default 0,
+  primary key(a, b)
+);
+
+insert into gh_ost_test values (11, 1, 'eleven', 0);
+insert into gh_ost_test values (13, 1, 'thirteen', 0);
+insert into gh_ost_test values (17, 1, 'seventeen', 0);
+
+drop event if exists gh_ost_test;
+delimiter ;;
+create event gh_ost_test
+  on schedule every 1 second
+  starts current_timestamp
+  ends current_timestamp + interval 60 second
+  on completion not preserve
+  enable
+  do
+begin
+  insert into gh_ost_test values (11, unix_timestamp(), 'eleven', 0);
+  insert into gh_ost_test values (13, unix_timestamp(), 'thirteen', 0);
+  insert into gh_ost_test values (19, unix_timestamp(), 'nineteen', 0);
+
+  update gh_ost_test set b = -b where a = 11 order by b desc limit 1;
+  update gh_ost_test set updated = updated + 1 where a = 13 order by b desc limit 1;
+  update gh_ost_test set a = 23, b = unix_timestamp() where a = 19 order by b desc limit 1;
+  delete from gh_ost_test where a = 17 order by b desc limit 1;
+end ;;
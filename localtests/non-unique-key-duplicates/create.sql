@@ -0,0 +1,33 @@
+drop table if exists gh_ost_test;
+create table gh_ost_test (
+  i int not null,
+  color varchar(32),
+  updated tinyint unsigned default 0,
+  key i_idx(i)
+) auto_increment=1;
+
+insert into gh_ost_test values (11, 'red', 0);
+insert into gh_ost_test values (11, 'red', 0);
+insert into gh_ost_test values (13, 'green', 0);
+insert into gh_ost_test values (13, 'green', 0);
+insert into gh_ost_test values (13, 'green', 0);
+insert into gh_ost_test values (17, 'blue', 0);
+
+drop event if exists gh_ost_test;
+delimiter ;;
+create event gh_ost_test
+  on schedule every 1 second
+  starts current_timestamp
+  ends current_timestamp + interval 60 second
+  on completion not preserve
+  enable
+  do
+begin
+  insert into gh_ost_test values (11, 'red', 0);
+  insert into gh_ost_test values (13, 'green', 0);
+  update gh_ost_test set updated = 1 where i = 11 and updated = 0 limit 1;
+  insert into gh_ost_test values (17, 'blue', 0);
+  insert into gh_ost_test values (19, 'yellow', 0);
+  delete from gh_ost_test where i = 13 limit 1;
+  update gh_ost_test set i = 23 where i = 19 limit 1;
+end ;;
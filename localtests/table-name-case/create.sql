@@ -0,0 +1,38 @@
+drop table if exists gh_ost_test;
+create table gh_ost_test (
+  id int auto_increment,
+  i int not null,
+  primary key(id)
+) auto_increment=1;
+
+-- Tables whose names differ from gh_ost_test only by case, or which match it as a LIKE pattern.
+-- Requires lower_case_table_names=0.
+drop table if exists GH_OST_TEST;
+create table GH_OST_TEST (
+  uid varchar(32) charset ascii not null,
+  t varchar(128),
+  key uid_idx(uid)
+);
+drop table if exists `gh_ostXtest`;
+create table `gh_ostXtest` (
+  id bigint unsigned,
+  primary key(id)
+);
+
+drop event if exists gh_ost_test;
+delimiter ;;
+create event gh_ost_test
+  on schedule every 1 second
+  starts current_timestamp
+  ends current_timestamp + interval 60 second
+  on completion not preserve
+  enable
+  do
+begin
+  insert into gh_ost_test values (null, 11);
+  insert into GH_OST_TEST values (md5(rand()), 'other table');
+  insert into `gh_ostXtest` values (unix_timestamp(now(6)) * 1000000);
+  update GH_OST_TEST set t='updated' order by rand() limit 1;
+  delete from GH_OST_TEST order by rand() limit 1;
+  update gh_ost_test set i=i+1 where id=last_insert_id();
+end ;;
@@ -0,0 +1,2 @@
+drop table if exists GH_OST_TEST;
+drop table if exists `gh_ostXtest`;
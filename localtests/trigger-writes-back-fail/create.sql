@@ -0,0 +1,34 @@
+-- A trigger on gh_ost_test writes to gh_ost_test_counts, whose trigger writes back to gh_ost_test.
+-- Validation must detect the indirect write onto the migrated table.
+
+drop trigger if exists gh_ost_test_ai;
+drop trigger if exists gh_ost_test_counts_ai;
+drop table if exists gh_ost_test_counts;
+drop table if exists gh_ost_test;
+
+create table gh_ost_test (
+  id int auto_increment,
+  i int not null,
+  primary key(id)
+) auto_increment=1;
+
+create table gh_ost_test_counts (
+  id int auto_increment,
+  n int not null,
+  primary key(id)
+);
+
+insert into gh_ost_test values (null, 11);
+insert into gh_ost_test values (null, 13);
+
+create trigger gh_ost_test_ai
+  after insert on gh_ost_test for each row
+  insert into gh_ost_test_counts values (null, new.i);
+
+delimiter ;;
+create trigger gh_ost_test_counts_ai
+  after insert on gh_ost_test_counts for each row
+begin
+  -- never fires successfully: MySQL rejects writes to the table whose trigger is running
+  update `test`.`gh_ost_test` set i = i + 1 where id = new.n;
+end ;;
@@ -0,0 +1,2 @@
+drop trigger if exists gh_ost_test_counts_ai;
+drop table if exists gh_ost_test_counts;